@@ -0,0 +1,39 @@
+/**
+ * File: /api/v1/conversion.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 11:52:06
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package v1
+
+// Hub marks this type as a conversion hub.
+func (*Plug) Hub() {}
+
+// Hub marks this type as a conversion hub.
+func (*Socket) Hub() {}
+
+// Hub marks this type as a conversion hub.
+func (*DeferredResource) Hub() {}
+
+// Hub marks this type as a conversion hub.
+func (*CouplingApproval) Hub() {}
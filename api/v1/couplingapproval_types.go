@@ -0,0 +1,70 @@
+/**
+ * File: /api/v1/couplingapproval_types.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 11:41:07
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CouplingApprovalSpec defines the desired state of CouplingApproval
+type CouplingApprovalSpec struct {
+	// name of the socket in the namespace of the approval
+	Socket string `json:"socket"`
+
+	// plug granted approval to couple to the socket
+	Plug NamespacedName `json:"plug"`
+
+	// identity of the approver, set by the admission webhook to the user that created the approval
+	//+optional
+	Approver string `json:"approver,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:storageversion
+
+// CouplingApproval grants a plug approval to couple to a socket that requires approval.
+// It must be created in the namespace of the socket, so only users permitted to create
+// coupling approvals in that namespace can approve couplings.
+type CouplingApproval struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CouplingApprovalSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CouplingApprovalList contains a list of CouplingApproval
+type CouplingApprovalList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CouplingApproval `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CouplingApproval{}, &CouplingApprovalList{})
+}
@@ -0,0 +1,107 @@
+/**
+ * File: /api/v1/couplingapproval_webhook.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 17:58:31
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/mutate-integration-rock8s-com-v1-couplingapproval,mutating=true,failurePolicy=fail,sideEffects=None,groups=integration.rock8s.com,resources=couplingapprovals,verbs=create;update,versions=v1,name=mcouplingapproval.integration.rock8s.com,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-integration-rock8s-com-v1-couplingapproval,mutating=false,failurePolicy=fail,sideEffects=None,groups=integration.rock8s.com,resources=couplingapprovals,verbs=create;update,versions=v1,name=vcouplingapproval.integration.rock8s.com,admissionReviewVersions=v1
+
+// couplingApprovalAdmission records the user that created a coupling approval as its approver,
+// so the approver cannot be claimed by whoever writes the approval
+type couplingApprovalAdmission struct{}
+
+var _ admission.CustomDefaulter = &couplingApprovalAdmission{}
+
+var _ admission.CustomValidator = &couplingApprovalAdmission{}
+
+// Default sets the approver of a created coupling approval to the user that created it
+func (a *couplingApprovalAdmission) Default(ctx context.Context, obj runtime.Object) error {
+	approval, ok := obj.(*CouplingApproval)
+	if !ok {
+		return fmt.Errorf("expected a CouplingApproval but got %T", obj)
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if req.Operation == admissionv1.Create {
+		approval.Spec.Approver = req.UserInfo.Username
+	}
+	return nil
+}
+
+// ValidateCreate rejects coupling approvals whose approver is not the user that created them
+func (a *couplingApprovalAdmission) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	approval, ok := obj.(*CouplingApproval)
+	if !ok {
+		return fmt.Errorf("expected a CouplingApproval but got %T", obj)
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if approval.Spec.Approver != req.UserInfo.Username {
+		return fmt.Errorf(
+			"approver %s is not %s, the user creating the coupling approval",
+			approval.Spec.Approver,
+			req.UserInfo.Username,
+		)
+	}
+	return nil
+}
+
+// ValidateUpdate rejects changes to the spec of coupling approvals, a new approval must be
+// created to approve another coupling
+func (a *couplingApprovalAdmission) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	oldApproval, ok := oldObj.(*CouplingApproval)
+	if !ok {
+		return fmt.Errorf("expected a CouplingApproval but got %T", oldObj)
+	}
+	newApproval, ok := newObj.(*CouplingApproval)
+	if !ok {
+		return fmt.Errorf("expected a CouplingApproval but got %T", newObj)
+	}
+	if !equality.Semantic.DeepEqual(oldApproval.Spec, newApproval.Spec) {
+		return errors.New("the spec of a coupling approval cannot be changed")
+	}
+	return nil
+}
+
+// ValidateDelete permits deleting coupling approvals, which revokes them
+func (a *couplingApprovalAdmission) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
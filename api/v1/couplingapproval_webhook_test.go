@@ -0,0 +1,100 @@
+/**
+ * File: /api/v1/couplingapproval_webhook_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 18:06:47
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package v1_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("CouplingApproval admission", func() {
+	var approval *integrationv1.CouplingApproval
+	var admit interface {
+		admission.CustomDefaulter
+		admission.CustomValidator
+	}
+
+	BeforeEach(func() {
+		approval = &integrationv1.CouplingApproval{
+			Spec: integrationv1.CouplingApprovalSpec{
+				Socket:   "postgres",
+				Plug:     integrationv1.NamespacedName{Name: "postgres", Namespace: "app"},
+				Approver: "dba@example.com",
+			},
+		}
+		admit = integrationv1.NewTestCouplingApprovalAdmission()
+	})
+
+	requestBy := func(operation admissionv1.Operation, username string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: operation,
+				UserInfo:  authenticationv1.UserInfo{Username: username},
+			},
+		})
+	}
+
+	It("should record the user creating the approval as the approver", func() {
+		ctx := requestBy(admissionv1.Create, "mallory@example.com")
+		Expect(admit.Default(ctx, approval)).To(Succeed())
+		Expect(approval.Spec.Approver).To(Equal("mallory@example.com"))
+		Expect(admit.ValidateCreate(ctx, approval)).To(Succeed())
+	})
+
+	It("should reject created approvals claiming another approver", func() {
+		err := admit.ValidateCreate(requestBy(admissionv1.Create, "mallory@example.com"), approval)
+		Expect(err).To(MatchError(ContainSubstring("approver dba@example.com is not mallory@example.com")))
+	})
+
+	It("should keep the approver when the approval is updated", func() {
+		ctx := requestBy(admissionv1.Update, "mallory@example.com")
+		Expect(admit.Default(ctx, approval)).To(Succeed())
+		Expect(approval.Spec.Approver).To(Equal("dba@example.com"))
+	})
+
+	It("should reject changes to the spec of an approval", func() {
+		ctx := requestBy(admissionv1.Update, "dba@example.com")
+		changed := approval.DeepCopy()
+		changed.Spec.Plug.Name = "other"
+		Expect(admit.ValidateUpdate(ctx, approval, changed)).To(
+			MatchError("the spec of a coupling approval cannot be changed"),
+		)
+		labeled := approval.DeepCopy()
+		labeled.Labels = map[string]string{"team": "dba"}
+		Expect(admit.ValidateUpdate(ctx, approval, labeled)).To(Succeed())
+	})
+
+	It("should fail without an admission request", func() {
+		Expect(admit.Default(context.Background(), approval)).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,364 @@
+/**
+ * File: /api/v1/shared_types.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 11:45:35
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package v1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const Finalizer = "integration.rock8s.com/finalizer"
+
+type When string
+
+const (
+	CoupledWhen   When = "coupled"
+	CreatedWhen   When = "created"
+	DecoupledWhen When = "decoupled"
+	DeletedWhen   When = "deleted"
+	UpdatedWhen   When = "updated"
+)
+
+type Do string
+
+const (
+	ApplyDo    Do = "apply"
+	DeleteDo   Do = "delete"
+	RecreateDo Do = "recreate"
+)
+
+type TemplateEngine string
+
+const (
+	GoTemplateEngine      TemplateEngine = "gotemplate"
+	JsonnetTemplateEngine TemplateEngine = "jsonnet"
+	CUETemplateEngine     TemplateEngine = "cue"
+)
+
+type ResourceAction struct {
+	Do Do `json:"do,omitempty"`
+	// +kubebuilder:validation:Enum=gotemplate;jsonnet;cue
+	Engine          TemplateEngine   `json:"engine,omitempty"`
+	Template        *apiextv1.JSON   `json:"template,omitempty"`
+	Templates       []*apiextv1.JSON `json:"templates,omitempty"`
+	StringTemplate  string           `json:"stringTemplate,omitempty"`
+	StringTemplates []string         `json:"stringTemplates,omitempty"`
+	Source          *ResourceSource  `json:"source,omitempty"`
+}
+
+type SourceKind string
+
+const (
+	KustomizeSourceKind SourceKind = "kustomize"
+	HelmSourceKind      SourceKind = "helm"
+)
+
+// ResourceSource renders resources from a kustomization or a helm chart
+type ResourceSource struct {
+	// kind of the source
+	// +kubebuilder:validation:Enum=kustomize;helm
+	Kind SourceKind `json:"kind"`
+
+	// configmap in the namespace of the plug or socket holding the source
+	ConfigMap *SourceConfigMap `json:"configMap,omitempty"`
+
+	// path of a directory or archive holding the source in the sources directory of the operator image
+	Path string `json:"path,omitempty"`
+
+	// oci artifact holding the source as an archive
+	OCI *SourceOCI `json:"oci,omitempty"`
+
+	// template of the values of the helm chart, rendered with the engine of the resource
+	Values string `json:"values,omitempty"`
+
+	// name of the helm release, defaults to the name of the chart
+	ReleaseName string `json:"releaseName,omitempty"`
+}
+
+type SourceConfigMap struct {
+	// name of the configmap
+	Name string `json:"name"`
+
+	// key holding an archive of the source, otherwise every key is a file of the source
+	Key string `json:"key,omitempty"`
+}
+
+type SourceOCI struct {
+	// reference of the artifact such as registry.example.com/charts/app:1.0.0
+	Ref string `json:"ref"`
+
+	// name of a secret of type kubernetes.io/dockerconfigjson holding the credentials of the registry
+	PullSecretName string `json:"pullSecretName,omitempty"`
+
+	// connect to the registry over plain http
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+type Resource struct {
+	ResourceAction      `json:",inline"`
+	RetainWhenDecoupled bool   `json:"retainWhenDecoupled,omitempty"`
+	When                []When `json:"when,omitempty"`
+}
+
+// ConditionTransition records a transition of a condition
+type ConditionTransition struct {
+	// type of the condition
+	Type string `json:"type"`
+
+	// status of the condition
+	Status metav1.ConditionStatus `json:"status"`
+
+	// reason of the condition
+	Reason string `json:"reason,omitempty"`
+
+	// message of the condition
+	Message string `json:"message,omitempty"`
+
+	// time of the transition
+	Time metav1.Time `json:"time"`
+}
+
+type NamespacedName struct {
+	// name
+	Name string `json:"name"`
+
+	// namespace
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type SpecApparatus struct {
+	// endpoint
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// protocol used to communicate with the apparatus, defaults to http
+	// +kubebuilder:validation:Enum=http;grpc
+	Protocol ApparatusProtocol `json:"protocol,omitempty"`
+
+	// terminate apparatus after idle for timeout in milliseconds
+	IdleTimeout uint `json:"idleTimeout,omitempty"`
+
+	// asynchronous operations started by the apparatus
+	Async *ApparatusAsync `json:"async,omitempty"`
+
+	// timeouts of requests to the apparatus
+	Timeouts *ApparatusTimeouts `json:"timeouts,omitempty"`
+
+	// key of a secret in the namespace of the apparatus used to sign requests to the apparatus
+	SigningSecret *v1.SecretKeySelector `json:"signingSecret,omitempty"`
+
+	// diagnostics collected when requests to the apparatus fail
+	Diagnostics *ApparatusDiagnostics `json:"diagnostics,omitempty"`
+
+	// List of containers belonging to the apparatus.
+	// Containers cannot currently be added or removed.
+	// There must be at least one container in an apparatus.
+	// Cannot be updated.
+	// +patchMergeKey=name
+	// +patchStrategy=merge
+	Containers []v1.Container `json:"containers"`
+}
+
+type ApparatusProtocol string
+
+const (
+	HTTPProtocol ApparatusProtocol = "http"
+	GRPCProtocol ApparatusProtocol = "grpc"
+)
+
+// ApparatusDiagnostics configures the diagnostics collected when requests to an apparatus fail
+type ApparatusDiagnostics struct {
+	// do not collect diagnostics
+	Disabled bool `json:"disabled,omitempty"`
+
+	// number of log lines collected from each container of the apparatus, defaults to 100
+	LogLines uint `json:"logLines,omitempty"`
+
+	// seconds the apparatus is kept alive after a failed request, defaults to 300
+	GracePeriod uint `json:"gracePeriod,omitempty"`
+}
+
+type AsyncCompletion string
+
+const (
+	PollCompletion     AsyncCompletion = "poll"
+	CallbackCompletion AsyncCompletion = "callback"
+)
+
+// ApparatusAsync configures how the operator waits for an apparatus
+// that accepts an event with a 202 status and an operation id
+type ApparatusAsync struct {
+	// how the operator learns the operation completed, poll the status endpoint of the apparatus or wait for a callback
+	// +kubebuilder:validation:Enum=poll;callback
+	Completion AsyncCompletion `json:"completion,omitempty"`
+
+	// seconds between checks of the operation, defaults to 10
+	PollInterval uint `json:"pollInterval,omitempty"`
+
+	// seconds until the operation is considered failed, defaults to 3600
+	Timeout uint `json:"timeout,omitempty"`
+}
+
+// PendingOperation is an operation an apparatus accepted with a 202 status, kept in the status
+// of the plug or socket owning the apparatus until it completes
+type PendingOperation struct {
+	// event the apparatus accepted
+	Event string `json:"event"`
+
+	// uid of the plug the event was sent for
+	Plug types.UID `json:"plug,omitempty"`
+
+	// id of the operation reported by the apparatus
+	ID string `json:"id"`
+
+	// id of the operation in the callback url sent to the apparatus
+	CallbackID string `json:"callbackId,omitempty"`
+
+	// how the operator learns the operation completed
+	// +kubebuilder:validation:Enum=poll;callback
+	Completion AsyncCompletion `json:"completion"`
+
+	// url the status of the operation is polled from
+	StatusURL string `json:"statusUrl,omitempty"`
+
+	// time the operation is considered failed
+	Deadline metav1.Time `json:"deadline"`
+
+	// status of the operation, set when it completes
+	// +kubebuilder:validation:Enum=pending;succeeded;failed
+	Status string `json:"status,omitempty"`
+
+	// message the operation completed with
+	Message string `json:"message,omitempty"`
+}
+
+// AppliedResource is an object applied from a resource of a plug or socket, kept in the status
+// of the plug so objects the resource no longer renders can be pruned
+type AppliedResource struct {
+	// resources the object was rendered from
+	// +kubebuilder:validation:Enum=plug.resources;plug.resultResources;socket.resources;socket.resultResources
+	From string `json:"from"`
+
+	// index of the resource the object was rendered from
+	Resource int `json:"resource"`
+
+	// API version of the object
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the object
+	Kind string `json:"kind"`
+
+	// Name of the object
+	Name string `json:"name"`
+
+	// Namespace of the object
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ApparatusTimeouts configures how long requests to the apparatus may take
+type ApparatusTimeouts struct {
+	// seconds to establish a connection to the apparatus, defaults to 10
+	Connect uint `json:"connect,omitempty"`
+
+	// seconds a request to the apparatus may take including retries, defaults to 60
+	Total uint `json:"total,omitempty"`
+
+	// seconds a request for an event may take including retries, overriding total
+	// for the config, created, coupled, updated, decoupled and deleted events
+	Events map[string]uint `json:"events,omitempty"`
+}
+
+// Var represents a variable whose value will be sourced
+// from a field in a Kubernetes object.
+type Var struct {
+	// Value of identifier name e.g. FOO used in container args, annotations
+	// Appears in pod template as $(FOO)
+	Name string `json:"name" yaml:"name"`
+
+	// ObjRef must refer to a Kubernetes resource under the
+	// purview of this kustomization. ObjRef should use the
+	// raw name of the object (the name specified in its YAML,
+	// before addition of a namePrefix and a nameSuffix).
+	ObjRef Target `json:"objref" yaml:"objref"`
+
+	// FieldRef refers to the field of the object referred to by
+	// ObjRef whose value will be extracted for use in
+	// replacing $(FOO).
+	// If unspecified, this defaults to fieldPath: $defaultFieldPath
+	FieldRef FieldSelector `json:"fieldref,omitempty" yaml:"fieldref,omitempty"`
+}
+
+// FieldSelector selects the value of a var from the object referred to by ObjRef, or from a
+// list with the objects selected by ObjRef as its items
+type FieldSelector struct {
+	// path of the field in gjson syntax, the value is always a string
+	FieldPath string `json:"fieldPath,omitempty" yaml:"fieldPath,omitempty"`
+
+	// jsonpath expression such as {.items[*].metadata.name}, the value is the matched json value,
+	// or a list of the matches when the expression can match more than one
+	JSONPath string `json:"jsonPath,omitempty" yaml:"jsonPath,omitempty"`
+
+	// cel expression with the object or list bound to object, the value is the json value of
+	// the result
+	CEL string `json:"cel,omitempty" yaml:"cel,omitempty"`
+
+	// options of the string value
+	Options *FieldOptions `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// FieldOptions picks a part of the string value of a field
+type FieldOptions struct {
+	// delimiter splitting the value
+	Delimiter string `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`
+
+	// index of the part of the split value
+	Index int `json:"index,omitempty" yaml:"index,omitempty"`
+}
+
+// Gvk identifies a kind of kubernetes object by Group, Version and Kind
+type Gvk struct {
+	Group   string `json:"group,omitempty" yaml:"group,omitempty"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Kind    string `json:"kind,omitempty" yaml:"kind,omitempty"`
+}
+
+// Target refers to a kubernetes object by Group, Version, Kind and Name
+// gvk.Gvk contains Group, Version and Kind
+// APIVersion is added to keep the backward compatibility of using ObjectReference
+// for Var.ObjRef
+type Target struct {
+	APIVersion        string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Gvk               `json:",inline,omitempty" yaml:",inline,omitempty"`
+	Name              string `json:"name,omitempty" yaml:"name,omitempty"`
+	Namespace         string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	TemplateName      string `json:"templateName,omitempty" yaml:"templateName,omitempty"`
+	TemplateNamespace string `json:"templateNamespace,omitempty" yaml:"templateNamespace,omitempty"`
+
+	// selector of the objects in the namespace, used instead of a name
+	Selector *metav1.LabelSelector `json:"selector,omitempty" yaml:"selector,omitempty"`
+}
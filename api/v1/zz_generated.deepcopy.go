@@ -0,0 +1,1349 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApparatusAsync) DeepCopyInto(out *ApparatusAsync) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApparatusAsync.
+func (in *ApparatusAsync) DeepCopy() *ApparatusAsync {
+	if in == nil {
+		return nil
+	}
+	out := new(ApparatusAsync)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApparatusDiagnostics) DeepCopyInto(out *ApparatusDiagnostics) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApparatusDiagnostics.
+func (in *ApparatusDiagnostics) DeepCopy() *ApparatusDiagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(ApparatusDiagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApparatusTimeouts) DeepCopyInto(out *ApparatusTimeouts) {
+	*out = *in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make(map[string]uint, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApparatusTimeouts.
+func (in *ApparatusTimeouts) DeepCopy() *ApparatusTimeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(ApparatusTimeouts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppliedResource) DeepCopyInto(out *AppliedResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppliedResource.
+func (in *AppliedResource) DeepCopy() *AppliedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(AppliedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConditionTransition) DeepCopyInto(out *ConditionTransition) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConditionTransition.
+func (in *ConditionTransition) DeepCopy() *ConditionTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(ConditionTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigInterface) DeepCopyInto(out *ConfigInterface) {
+	*out = *in
+	if in.Plug != nil {
+		in, out := &in.Plug, &out.Plug
+		*out = make(map[string]*SchemaProperty, len(*in))
+		for key, val := range *in {
+			var outVal *SchemaProperty
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(SchemaProperty)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Socket != nil {
+		in, out := &in.Socket, &out.Socket
+		*out = make(map[string]*SchemaProperty, len(*in))
+		for key, val := range *in {
+			var outVal *SchemaProperty
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(SchemaProperty)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigInterface.
+func (in *ConfigInterface) DeepCopy() *ConfigInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoupledPlug) DeepCopyInto(out *CoupledPlug) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoupledPlug.
+func (in *CoupledPlug) DeepCopy() *CoupledPlug {
+	if in == nil {
+		return nil
+	}
+	out := new(CoupledPlug)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoupledResult) DeepCopyInto(out *CoupledResult) {
+	*out = *in
+	if in.Plug != nil {
+		in, out := &in.Plug, &out.Plug
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Socket != nil {
+		in, out := &in.Socket, &out.Socket
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoupledResult.
+func (in *CoupledResult) DeepCopy() *CoupledResult {
+	if in == nil {
+		return nil
+	}
+	out := new(CoupledResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoupledResultStatus) DeepCopyInto(out *CoupledResultStatus) {
+	*out = *in
+	in.CoupledResult.DeepCopyInto(&out.CoupledResult)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoupledResultStatus.
+func (in *CoupledResultStatus) DeepCopy() *CoupledResultStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CoupledResultStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoupledSocket) DeepCopyInto(out *CoupledSocket) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoupledSocket.
+func (in *CoupledSocket) DeepCopy() *CoupledSocket {
+	if in == nil {
+		return nil
+	}
+	out := new(CoupledSocket)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CouplingApproval) DeepCopyInto(out *CouplingApproval) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CouplingApproval.
+func (in *CouplingApproval) DeepCopy() *CouplingApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(CouplingApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CouplingApproval) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CouplingApprovalList) DeepCopyInto(out *CouplingApprovalList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CouplingApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CouplingApprovalList.
+func (in *CouplingApprovalList) DeepCopy() *CouplingApprovalList {
+	if in == nil {
+		return nil
+	}
+	out := new(CouplingApprovalList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CouplingApprovalList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CouplingApprovalSpec) DeepCopyInto(out *CouplingApprovalSpec) {
+	*out = *in
+	out.Plug = in.Plug
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CouplingApprovalSpec.
+func (in *CouplingApprovalSpec) DeepCopy() *CouplingApprovalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CouplingApprovalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeferredResource) DeepCopyInto(out *DeferredResource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeferredResource.
+func (in *DeferredResource) DeepCopy() *DeferredResource {
+	if in == nil {
+		return nil
+	}
+	out := new(DeferredResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeferredResource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeferredResourceList) DeepCopyInto(out *DeferredResourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeferredResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeferredResourceList.
+func (in *DeferredResourceList) DeepCopy() *DeferredResourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeferredResourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeferredResourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeferredResourceSpec) DeepCopyInto(out *DeferredResourceSpec) {
+	*out = *in
+	if in.WaitFor != nil {
+		in, out := &in.WaitFor, &out.WaitFor
+		*out = make([]*WaitForTarget, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(WaitForTarget)
+				**out = **in
+			}
+		}
+	}
+	if in.Resource != nil {
+		in, out := &in.Resource, &out.Resource
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeferredResourceSpec.
+func (in *DeferredResourceSpec) DeepCopy() *DeferredResourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeferredResourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeferredResourceStatus) DeepCopyInto(out *DeferredResourceStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConditionHistory != nil {
+		in, out := &in.ConditionHistory, &out.ConditionHistory
+		*out = make([]ConditionTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.OwnerReference.DeepCopyInto(&out.OwnerReference)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeferredResourceStatus.
+func (in *DeferredResourceStatus) DeepCopy() *DeferredResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeferredResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldOptions) DeepCopyInto(out *FieldOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldOptions.
+func (in *FieldOptions) DeepCopy() *FieldOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldSelector) DeepCopyInto(out *FieldSelector) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = new(FieldOptions)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldSelector.
+func (in *FieldSelector) DeepCopy() *FieldSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Gvk) DeepCopyInto(out *Gvk) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Gvk.
+func (in *Gvk) DeepCopy() *Gvk {
+	if in == nil {
+		return nil
+	}
+	out := new(Gvk)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Interface) DeepCopyInto(out *Interface) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(ConfigInterface)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Result != nil {
+		in, out := &in.Result, &out.Result
+		*out = new(ResultInterface)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Interface.
+func (in *Interface) DeepCopy() *Interface {
+	if in == nil {
+		return nil
+	}
+	out := new(Interface)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedName) DeepCopyInto(out *NamespacedName) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedName.
+func (in *NamespacedName) DeepCopy() *NamespacedName {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedName)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingOperation) DeepCopyInto(out *PendingOperation) {
+	*out = *in
+	in.Deadline.DeepCopyInto(&out.Deadline)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingOperation.
+func (in *PendingOperation) DeepCopy() *PendingOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Plug) DeepCopyInto(out *Plug) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Plug.
+func (in *Plug) DeepCopy() *Plug {
+	if in == nil {
+		return nil
+	}
+	out := new(Plug)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Plug) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlugList) DeepCopyInto(out *PlugList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Plug, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlugList.
+func (in *PlugList) DeepCopy() *PlugList {
+	if in == nil {
+		return nil
+	}
+	out := new(PlugList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PlugList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlugSpec) DeepCopyInto(out *PlugSpec) {
+	*out = *in
+	out.Socket = in.Socket
+	if in.Vars != nil {
+		in, out := &in.Vars, &out.Vars
+		*out = make([]*Var, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Var)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.ResultVars != nil {
+		in, out := &in.ResultVars, &out.ResultVars
+		*out = make([]*Var, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Var)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ConfigTemplate != nil {
+		in, out := &in.ConfigTemplate, &out.ConfigTemplate
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Result != nil {
+		in, out := &in.Result, &out.Result
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResultTemplate != nil {
+		in, out := &in.ResultTemplate, &out.ResultTemplate
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Apparatus != nil {
+		in, out := &in.Apparatus, &out.Apparatus
+		*out = new(SpecApparatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]*Resource, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Resource)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.ResultResources != nil {
+		in, out := &in.ResultResources, &out.ResultResources
+		*out = make([]*ResourceAction, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ResourceAction)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.StrictTemplates != nil {
+		in, out := &in.StrictTemplates, &out.StrictTemplates
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlugSpec.
+func (in *PlugSpec) DeepCopy() *PlugSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PlugSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlugStatus) DeepCopyInto(out *PlugStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConditionHistory != nil {
+		in, out := &in.ConditionHistory, &out.ConditionHistory
+		*out = make([]ConditionTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CoupledSocket != nil {
+		in, out := &in.CoupledSocket, &out.CoupledSocket
+		*out = new(CoupledSocket)
+		**out = **in
+	}
+	if in.CoupledResult != nil {
+		in, out := &in.CoupledResult, &out.CoupledResult
+		*out = new(CoupledResultStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastCoupledTime != nil {
+		in, out := &in.LastCoupledTime, &out.LastCoupledTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PendingOperations != nil {
+		in, out := &in.PendingOperations, &out.PendingOperations
+		*out = make([]PendingOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AppliedResources != nil {
+		in, out := &in.AppliedResources, &out.AppliedResources
+		*out = make([]AppliedResource, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlugStatus.
+func (in *PlugStatus) DeepCopy() *PlugStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PlugStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Resource) DeepCopyInto(out *Resource) {
+	*out = *in
+	in.ResourceAction.DeepCopyInto(&out.ResourceAction)
+	if in.When != nil {
+		in, out := &in.When, &out.When
+		*out = make([]When, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Resource.
+func (in *Resource) DeepCopy() *Resource {
+	if in == nil {
+		return nil
+	}
+	out := new(Resource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceAction) DeepCopyInto(out *ResourceAction) {
+	*out = *in
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]*apiextensionsv1.JSON, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(apiextensionsv1.JSON)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.StringTemplates != nil {
+		in, out := &in.StringTemplates, &out.StringTemplates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(ResourceSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceAction.
+func (in *ResourceAction) DeepCopy() *ResourceAction {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSource) DeepCopyInto(out *ResourceSource) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(SourceConfigMap)
+		**out = **in
+	}
+	if in.OCI != nil {
+		in, out := &in.OCI, &out.OCI
+		*out = new(SourceOCI)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSource.
+func (in *ResourceSource) DeepCopy() *ResourceSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResultInterface) DeepCopyInto(out *ResultInterface) {
+	*out = *in
+	if in.Plug != nil {
+		in, out := &in.Plug, &out.Plug
+		*out = make(map[string]*SchemaProperty, len(*in))
+		for key, val := range *in {
+			var outVal *SchemaProperty
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(SchemaProperty)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Socket != nil {
+		in, out := &in.Socket, &out.Socket
+		*out = make(map[string]*SchemaProperty, len(*in))
+		for key, val := range *in {
+			var outVal *SchemaProperty
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(SchemaProperty)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultInterface.
+func (in *ResultInterface) DeepCopy() *ResultInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(ResultInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaProperty) DeepCopyInto(out *SchemaProperty) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaProperty.
+func (in *SchemaProperty) DeepCopy() *SchemaProperty {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaProperty)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Socket) DeepCopyInto(out *Socket) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Socket.
+func (in *Socket) DeepCopy() *Socket {
+	if in == nil {
+		return nil
+	}
+	out := new(Socket)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Socket) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SocketList) DeepCopyInto(out *SocketList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Socket, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SocketList.
+func (in *SocketList) DeepCopy() *SocketList {
+	if in == nil {
+		return nil
+	}
+	out := new(SocketList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SocketList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SocketSpec) DeepCopyInto(out *SocketSpec) {
+	*out = *in
+	if in.Interface != nil {
+		in, out := &in.Interface, &out.Interface
+		*out = new(Interface)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Vars != nil {
+		in, out := &in.Vars, &out.Vars
+		*out = make([]*Var, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Var)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.ResultVars != nil {
+		in, out := &in.ResultVars, &out.ResultVars
+		*out = make([]*Var, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Var)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ConfigTemplate != nil {
+		in, out := &in.ConfigTemplate, &out.ConfigTemplate
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Result != nil {
+		in, out := &in.Result, &out.Result
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResultTemplate != nil {
+		in, out := &in.ResultTemplate, &out.ResultTemplate
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Apparatus != nil {
+		in, out := &in.Apparatus, &out.Apparatus
+		*out = new(SpecApparatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]*Resource, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Resource)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.ResultResources != nil {
+		in, out := &in.ResultResources, &out.ResultResources
+		*out = make([]*ResourceAction, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ResourceAction)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.StrictTemplates != nil {
+		in, out := &in.StrictTemplates, &out.StrictTemplates
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Validation != nil {
+		in, out := &in.Validation, &out.Validation
+		*out = new(SocketSpecValidation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(SocketSpecApproval)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SocketSpec.
+func (in *SocketSpec) DeepCopy() *SocketSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SocketSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SocketSpecApproval) DeepCopyInto(out *SocketSpecApproval) {
+	*out = *in
+	if in.Approvers != nil {
+		in, out := &in.Approvers, &out.Approvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SocketSpecApproval.
+func (in *SocketSpecApproval) DeepCopy() *SocketSpecApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(SocketSpecApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SocketSpecValidation) DeepCopyInto(out *SocketSpecValidation) {
+	*out = *in
+	if in.NamespaceWhitelist != nil {
+		in, out := &in.NamespaceWhitelist, &out.NamespaceWhitelist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceBlacklist != nil {
+		in, out := &in.NamespaceBlacklist, &out.NamespaceBlacklist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PlugSelector != nil {
+		in, out := &in.PlugSelector, &out.PlugSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedServiceAccounts != nil {
+		in, out := &in.AllowedServiceAccounts, &out.AllowedServiceAccounts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]ValidationRule, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SocketSpecValidation.
+func (in *SocketSpecValidation) DeepCopy() *SocketSpecValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(SocketSpecValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SocketStatus) DeepCopyInto(out *SocketStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConditionHistory != nil {
+		in, out := &in.ConditionHistory, &out.ConditionHistory
+		*out = make([]ConditionTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CoupledPlugs != nil {
+		in, out := &in.CoupledPlugs, &out.CoupledPlugs
+		*out = make([]*CoupledPlug, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(CoupledPlug)
+				**out = **in
+			}
+		}
+	}
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = new(SocketSummary)
+		**out = **in
+	}
+	if in.PendingOperations != nil {
+		in, out := &in.PendingOperations, &out.PendingOperations
+		*out = make([]PendingOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SocketStatus.
+func (in *SocketStatus) DeepCopy() *SocketStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SocketStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SocketSummary) DeepCopyInto(out *SocketSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SocketSummary.
+func (in *SocketSummary) DeepCopy() *SocketSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(SocketSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceConfigMap) DeepCopyInto(out *SourceConfigMap) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceConfigMap.
+func (in *SourceConfigMap) DeepCopy() *SourceConfigMap {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceConfigMap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceOCI) DeepCopyInto(out *SourceOCI) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceOCI.
+func (in *SourceOCI) DeepCopy() *SourceOCI {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceOCI)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecApparatus) DeepCopyInto(out *SpecApparatus) {
+	*out = *in
+	if in.Async != nil {
+		in, out := &in.Async, &out.Async
+		*out = new(ApparatusAsync)
+		**out = **in
+	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(ApparatusTimeouts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SigningSecret != nil {
+		in, out := &in.SigningSecret, &out.SigningSecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Diagnostics != nil {
+		in, out := &in.Diagnostics, &out.Diagnostics
+		*out = new(ApparatusDiagnostics)
+		**out = **in
+	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecApparatus.
+func (in *SpecApparatus) DeepCopy() *SpecApparatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecApparatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Target) DeepCopyInto(out *Target) {
+	*out = *in
+	out.Gvk = in.Gvk
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Target.
+func (in *Target) DeepCopy() *Target {
+	if in == nil {
+		return nil
+	}
+	out := new(Target)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValidationRule) DeepCopyInto(out *ValidationRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidationRule.
+func (in *ValidationRule) DeepCopy() *ValidationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ValidationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Var) DeepCopyInto(out *Var) {
+	*out = *in
+	in.ObjRef.DeepCopyInto(&out.ObjRef)
+	in.FieldRef.DeepCopyInto(&out.FieldRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Var.
+func (in *Var) DeepCopy() *Var {
+	if in == nil {
+		return nil
+	}
+	out := new(Var)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaitForTarget) DeepCopyInto(out *WaitForTarget) {
+	*out = *in
+	out.Gvk = in.Gvk
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaitForTarget.
+func (in *WaitForTarget) DeepCopy() *WaitForTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(WaitForTarget)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,380 @@
+/**
+ * File: /api/v1beta1/conversion.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 11:58:13
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package v1beta1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConversionDataAnnotation holds the fields of a v1 object that v1beta1 cannot represent, so
+// they survive converting the object to v1beta1 and back
+const ConversionDataAnnotation = "integration.rock8s.com/conversion-data"
+
+// ConvertTo converts this Plug to the hub version (v1)
+func (src *Plug) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*integrationv1.Plug)
+	if err := src.convertTo(dst); err != nil {
+		return err
+	}
+	return restoreConversionData(dst)
+}
+
+// ConvertFrom converts from the hub version (v1) to this version
+func (dst *Plug) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*integrationv1.Plug)
+	if err := convert(src, dst, GroupVersion.WithKind("Plug")); err != nil {
+		return err
+	}
+	roundTrip := &integrationv1.Plug{}
+	if err := dst.convertTo(roundTrip); err != nil {
+		return err
+	}
+	return storeConversionData(src, roundTrip, dst)
+}
+
+func (src *Plug) convertTo(dst *integrationv1.Plug) error {
+	return convert(src, dst, integrationv1.GroupVersion.WithKind("Plug"))
+}
+
+// ConvertTo converts this Socket to the hub version (v1)
+func (src *Socket) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*integrationv1.Socket)
+	if err := src.convertTo(dst); err != nil {
+		return err
+	}
+	return restoreConversionData(dst)
+}
+
+// ConvertFrom converts from the hub version (v1) to this version
+func (dst *Socket) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*integrationv1.Socket)
+	if err := convert(src, dst, GroupVersion.WithKind("Socket")); err != nil {
+		return err
+	}
+	roundTrip := &integrationv1.Socket{}
+	if err := dst.convertTo(roundTrip); err != nil {
+		return err
+	}
+	return storeConversionData(src, roundTrip, dst)
+}
+
+func (src *Socket) convertTo(dst *integrationv1.Socket) error {
+	return convert(src, dst, integrationv1.GroupVersion.WithKind("Socket"))
+}
+
+// ConvertTo converts this DeferredResource to the hub version (v1)
+func (src *DeferredResource) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*integrationv1.DeferredResource)
+	if err := src.convertTo(dst); err != nil {
+		return err
+	}
+	return restoreConversionData(dst)
+}
+
+// ConvertFrom converts from the hub version (v1) to this version
+func (dst *DeferredResource) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*integrationv1.DeferredResource)
+	if err := convert(src, dst, GroupVersion.WithKind("DeferredResource")); err != nil {
+		return err
+	}
+	dst.Status.OwnerReference = src.Status.OwnerReference
+	roundTrip := &integrationv1.DeferredResource{}
+	if err := dst.convertTo(roundTrip); err != nil {
+		return err
+	}
+	return storeConversionData(src, roundTrip, dst)
+}
+
+func (src *DeferredResource) convertTo(dst *integrationv1.DeferredResource) error {
+	if err := convert(src, dst, integrationv1.GroupVersion.WithKind("DeferredResource")); err != nil {
+		return err
+	}
+	// the owner reference was stored as ownerReferences in v1beta1
+	dst.Status.OwnerReference = src.Status.OwnerReference
+	return nil
+}
+
+// ConvertTo converts this CouplingApproval to the hub version (v1)
+func (src *CouplingApproval) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*integrationv1.CouplingApproval)
+	if err := src.convertTo(dst); err != nil {
+		return err
+	}
+	return restoreConversionData(dst)
+}
+
+// ConvertFrom converts from the hub version (v1) to this version
+func (dst *CouplingApproval) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*integrationv1.CouplingApproval)
+	if err := convert(src, dst, GroupVersion.WithKind("CouplingApproval")); err != nil {
+		return err
+	}
+	roundTrip := &integrationv1.CouplingApproval{}
+	if err := dst.convertTo(roundTrip); err != nil {
+		return err
+	}
+	return storeConversionData(src, roundTrip, dst)
+}
+
+func (src *CouplingApproval) convertTo(dst *integrationv1.CouplingApproval) error {
+	return convert(src, dst, integrationv1.GroupVersion.WithKind("CouplingApproval"))
+}
+
+// convert copies src into dst through their json representation, which is
+// identical between v1beta1 and v1 apart from the fixed up fields
+func convert(src runtime.Object, dst runtime.Object, gvk schema.GroupVersionKind) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return err
+	}
+	dst.GetObjectKind().SetGroupVersionKind(gvk)
+	return nil
+}
+
+// conversionData is the part of the spec and status of a v1 object that was lost converting
+// it to v1beta1
+type conversionData struct {
+	// fields v1beta1 cannot represent at all, with their v1 value
+	Fields map[string]interface{} `json:"fields,omitempty"`
+
+	// fields both versions represent, with the data lost below them
+	Nested map[string]*conversionData `json:"nested,omitempty"`
+
+	// items of a list with the data lost below them
+	Items []*conversionDataItem `json:"items,omitempty"`
+}
+
+// conversionDataItem is the data lost below an item of a list, which is only restored to the
+// item if it was not changed, since items are not identified other than by their position
+type conversionDataItem struct {
+	Index int `json:"index"`
+
+	// digest of the v1beta1 representation of the item
+	Digest string `json:"digest"`
+
+	Data *conversionData `json:"data"`
+}
+
+// storeConversionData records the spec and status fields of the hub that are missing from
+// its round trip through v1beta1 in an annotation of the converted object
+func storeConversionData(hub runtime.Object, roundTrip runtime.Object, dst client.Object) error {
+	hubValue, err := toJSONValue(hub)
+	if err != nil {
+		return err
+	}
+	roundTripValue, err := toJSONValue(roundTrip)
+	if err != nil {
+		return err
+	}
+	data := &conversionData{}
+	for _, key := range []string{"spec", "status"} {
+		hubField, ok := hubValue.(map[string]interface{})[key]
+		if !ok {
+			continue
+		}
+		roundTripField, ok := roundTripValue.(map[string]interface{})[key]
+		if !ok {
+			if data.Fields == nil {
+				data.Fields = map[string]interface{}{}
+			}
+			data.Fields[key] = hubField
+			continue
+		}
+		if nested := lostConversionData(hubField, roundTripField); nested != nil {
+			if data.Nested == nil {
+				data.Nested = map[string]*conversionData{}
+			}
+			data.Nested[key] = nested
+		}
+	}
+	annotations := dst.GetAnnotations()
+	delete(annotations, ConversionDataAnnotation)
+	if data.Fields != nil || data.Nested != nil {
+		body, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[ConversionDataAnnotation] = string(body)
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	dst.SetAnnotations(annotations)
+	return nil
+}
+
+// restoreConversionData merges the fields recorded in the annotation of the v1beta1 object
+// back into the converted hub and removes the annotation from the hub
+func restoreConversionData(hub client.Object) error {
+	annotations := hub.GetAnnotations()
+	body, ok := annotations[ConversionDataAnnotation]
+	if !ok {
+		return nil
+	}
+	delete(annotations, ConversionDataAnnotation)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	hub.SetAnnotations(annotations)
+	data := &conversionData{}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(data); err != nil {
+		return err
+	}
+	hubValue, err := toJSONValue(hub)
+	if err != nil {
+		return err
+	}
+	restoreConversionDataValue(hubValue, data)
+	restored, err := json.Marshal(hubValue)
+	if err != nil {
+		return err
+	}
+	gvk := hub.GetObjectKind().GroupVersionKind()
+	if err := json.Unmarshal(restored, hub); err != nil {
+		return err
+	}
+	hub.GetObjectKind().SetGroupVersionKind(gvk)
+	return nil
+}
+
+// lostConversionData returns the data of the hub value missing from its round trip value,
+// or nil when nothing was lost
+func lostConversionData(hubValue interface{}, roundTripValue interface{}) *conversionData {
+	switch hubValue := hubValue.(type) {
+	case map[string]interface{}:
+		roundTripMap, ok := roundTripValue.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		data := &conversionData{}
+		for key, value := range hubValue {
+			roundTripField, ok := roundTripMap[key]
+			if !ok {
+				if data.Fields == nil {
+					data.Fields = map[string]interface{}{}
+				}
+				data.Fields[key] = value
+				continue
+			}
+			if nested := lostConversionData(value, roundTripField); nested != nil {
+				if data.Nested == nil {
+					data.Nested = map[string]*conversionData{}
+				}
+				data.Nested[key] = nested
+			}
+		}
+		if data.Fields == nil && data.Nested == nil {
+			return nil
+		}
+		return data
+	case []interface{}:
+		roundTripList, ok := roundTripValue.([]interface{})
+		if !ok || len(roundTripList) != len(hubValue) {
+			return nil
+		}
+		data := &conversionData{}
+		for i, value := range hubValue {
+			if nested := lostConversionData(value, roundTripList[i]); nested != nil {
+				data.Items = append(data.Items, &conversionDataItem{
+					Index:  i,
+					Digest: conversionDigest(roundTripList[i]),
+					Data:   nested,
+				})
+			}
+		}
+		if data.Items == nil {
+			return nil
+		}
+		return data
+	}
+	return nil
+}
+
+// restoreConversionDataValue merges the lost data into the value. Fields are only restored
+// below fields and items that still exist, so data of fields removed through v1beta1 is dropped.
+func restoreConversionDataValue(value interface{}, data *conversionData) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range data.Fields {
+			if _, ok := value[key]; !ok {
+				value[key] = field
+			}
+		}
+		for key, nested := range data.Nested {
+			if field, ok := value[key]; ok && nested != nil {
+				restoreConversionDataValue(field, nested)
+			}
+		}
+	case []interface{}:
+		for _, item := range data.Items {
+			if item.Data == nil || item.Index < 0 || item.Index >= len(value) {
+				continue
+			}
+			if conversionDigest(value[item.Index]) == item.Digest {
+				restoreConversionDataValue(value[item.Index], item.Data)
+			}
+		}
+	}
+}
+
+func conversionDigest(value interface{}) string {
+	body, _ := json.Marshal(value)
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:8])
+}
+
+func toJSONValue(obj runtime.Object) (interface{}, error) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,266 @@
+/**
+ * File: /api/v1beta1/conversion_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 20:13:04
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package v1beta1_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Conversion", func() {
+	strict := true
+
+	newPlug := func() *integrationv1.Plug {
+		return &integrationv1.Plug{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: integrationv1.GroupVersion.String(),
+				Kind:       "Plug",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "postgres",
+				Namespace:   "app",
+				Annotations: map[string]string{"team": "data"},
+			},
+			Spec: integrationv1.PlugSpec{
+				Socket: integrationv1.NamespacedName{Name: "postgres", Namespace: "db"},
+				Vars: []*integrationv1.Var{
+					{
+						Name: "HOST",
+						ObjRef: integrationv1.Target{
+							APIVersion: "v1",
+							Gvk:        integrationv1.Gvk{Kind: "Service"},
+							Name:       "postgres",
+						},
+						FieldRef: integrationv1.FieldSelector{FieldPath: "spec.clusterIP"},
+					},
+					{
+						Name: "PODS",
+						ObjRef: integrationv1.Target{
+							APIVersion: "v1",
+							Gvk:        integrationv1.Gvk{Kind: "Pod"},
+							Selector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{"app": "postgres"},
+							},
+						},
+						FieldRef: integrationv1.FieldSelector{
+							JSONPath: "{.items[*].metadata.name}",
+							CEL:      "size(object.items)",
+						},
+					},
+				},
+				Config:               map[string]string{"database": "app"},
+				ConfigTemplateEngine: integrationv1.JsonnetTemplateEngine,
+				Apparatus: &integrationv1.SpecApparatus{
+					Endpoint: "http://apparatus",
+					Async: &integrationv1.ApparatusAsync{
+						Completion:   integrationv1.CallbackCompletion,
+						PollInterval: 5,
+					},
+					Timeouts: &integrationv1.ApparatusTimeouts{
+						Connect: 3,
+						Events:  map[string]uint{"created": 120},
+					},
+					SigningSecret: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: "signing"},
+						Key:                  "key",
+					},
+					Diagnostics: &integrationv1.ApparatusDiagnostics{LogLines: 50},
+				},
+				Resources: []*integrationv1.Resource{
+					{
+						ResourceAction: integrationv1.ResourceAction{
+							Do:     "apply",
+							Engine: integrationv1.JsonnetTemplateEngine,
+							Source: &integrationv1.ResourceSource{
+								Kind: integrationv1.SourceKind("oci"),
+								OCI:  &integrationv1.SourceOCI{Ref: "registry.example.com/charts/app:1.0.0"},
+							},
+						},
+						When: []integrationv1.When{"coupled"},
+					},
+				},
+				RetainGeneratedValues: true,
+				StrictTemplates:       &strict,
+			},
+			Status: integrationv1.PlugStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Coupled",
+						Status:             metav1.ConditionTrue,
+						Reason:             "Coupled",
+						LastTransitionTime: metav1.Unix(1700000000, 0),
+					},
+				},
+				ConditionHistory: []integrationv1.ConditionTransition{
+					{
+						Type:   "Coupled",
+						Status: metav1.ConditionTrue,
+						Reason: "Coupled",
+						Time:   metav1.Unix(1700000000, 0),
+					},
+				},
+				LastApparatusResponseCode: 200,
+				RenderedResourceCount:     3,
+			},
+		}
+	}
+
+	toSpoke := func(hub *integrationv1.Plug) *integrationv1beta1.Plug {
+		spoke := &integrationv1beta1.Plug{}
+		Expect(spoke.ConvertFrom(hub.DeepCopy())).To(Succeed())
+		return spoke
+	}
+
+	toHub := func(spoke *integrationv1beta1.Plug) *integrationv1.Plug {
+		hub := &integrationv1.Plug{}
+		Expect(spoke.DeepCopy().ConvertTo(hub)).To(Succeed())
+		return hub
+	}
+
+	It("should restore the v1 fields of a plug after a round trip through v1beta1", func() {
+		hub := newPlug()
+		spoke := toSpoke(hub)
+		Expect(spoke.APIVersion).To(Equal(integrationv1beta1.GroupVersion.String()))
+		Expect(spoke.Annotations).To(HaveKey(integrationv1beta1.ConversionDataAnnotation))
+		Expect(spoke.Annotations).To(HaveKeyWithValue("team", "data"))
+		Expect(toHub(spoke)).To(Equal(hub))
+	})
+
+	It("should not annotate objects that lose nothing", func() {
+		hub := newPlug()
+		hub.Spec = integrationv1.PlugSpec{
+			Socket: integrationv1.NamespacedName{Name: "postgres"},
+			Config: map[string]string{"database": "app"},
+		}
+		hub.Status = integrationv1.PlugStatus{}
+		spoke := toSpoke(hub)
+		Expect(spoke.Annotations).To(Equal(map[string]string{"team": "data"}))
+		Expect(toHub(spoke)).To(Equal(hub))
+	})
+
+	It("should drop the lost fields of a list item changed through v1beta1", func() {
+		spoke := toSpoke(newPlug())
+		spoke.Spec.Vars[1].ObjRef.Name = "postgres-0"
+		hub := toHub(spoke)
+		Expect(hub.Spec.Vars[0].FieldRef.FieldPath).To(Equal("spec.clusterIP"))
+		Expect(hub.Spec.Vars[1].ObjRef.Name).To(Equal("postgres-0"))
+		Expect(hub.Spec.Vars[1].ObjRef.Selector).To(BeNil())
+		Expect(hub.Spec.Vars[1].FieldRef.CEL).To(BeEmpty())
+	})
+
+	It("should not restore fields below a field removed through v1beta1", func() {
+		spoke := toSpoke(newPlug())
+		spoke.Spec.Apparatus = nil
+		hub := toHub(spoke)
+		Expect(hub.Spec.Apparatus).To(BeNil())
+		Expect(hub.Spec.StrictTemplates).To(Equal(&strict))
+		Expect(hub.Annotations).NotTo(HaveKey(integrationv1beta1.ConversionDataAnnotation))
+	})
+
+	It("should restore the v1 fields of a socket after a round trip through v1beta1", func() {
+		hub := &integrationv1.Socket{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: integrationv1.GroupVersion.String(),
+				Kind:       "Socket",
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "db"},
+			Spec: integrationv1.SocketSpec{
+				ResultTemplateEngine: integrationv1.JsonnetTemplateEngine,
+				Validation: &integrationv1.SocketSpecValidation{
+					Rules: []integrationv1.ValidationRule{{Rule: "plug.metadata.namespace == 'app'"}},
+				},
+			},
+			Status: integrationv1.SocketStatus{
+				Summary: &integrationv1.SocketSummary{Coupled: 2, Failed: 1},
+			},
+		}
+		spoke := &integrationv1beta1.Socket{}
+		Expect(spoke.ConvertFrom(hub.DeepCopy())).To(Succeed())
+		Expect(spoke.Spec.Validation.Rules).To(HaveLen(1))
+		roundTrip := &integrationv1.Socket{}
+		Expect(spoke.ConvertTo(roundTrip)).To(Succeed())
+		Expect(roundTrip).To(Equal(hub))
+	})
+
+	It("should keep the owner reference of a deferred resource", func() {
+		hub := &integrationv1.DeferredResource{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: integrationv1.GroupVersion.String(),
+				Kind:       "DeferredResource",
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "app"},
+			Spec: integrationv1.DeferredResourceSpec{
+				Timeout:  30,
+				Resource: &apiextv1.JSON{Raw: []byte(`{"kind":"ConfigMap"}`)},
+			},
+			Status: integrationv1.DeferredResourceStatus{
+				OwnerReference: metav1.OwnerReference{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+					Name:       "config",
+					UID:        types.UID("4a7c"),
+				},
+				ConditionHistory: []integrationv1.ConditionTransition{
+					{Type: "Resolved", Status: metav1.ConditionTrue, Time: metav1.Unix(1700000000, 0)},
+				},
+			},
+		}
+		spoke := &integrationv1beta1.DeferredResource{}
+		Expect(spoke.ConvertFrom(hub.DeepCopy())).To(Succeed())
+		Expect(spoke.Status.OwnerReference.UID).To(Equal(types.UID("4a7c")))
+		roundTrip := &integrationv1.DeferredResource{}
+		Expect(spoke.ConvertTo(roundTrip)).To(Succeed())
+		Expect(roundTrip).To(Equal(hub))
+	})
+
+	It("should convert coupling approvals without loss", func() {
+		hub := &integrationv1.CouplingApproval{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: integrationv1.GroupVersion.String(),
+				Kind:       "CouplingApproval",
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "db"},
+			Spec: integrationv1.CouplingApprovalSpec{
+				Socket:   "postgres",
+				Plug:     integrationv1.NamespacedName{Name: "postgres", Namespace: "app"},
+				Approver: "dba@example.com",
+			},
+		}
+		spoke := &integrationv1beta1.CouplingApproval{}
+		Expect(spoke.ConvertFrom(hub.DeepCopy())).To(Succeed())
+		Expect(spoke.Annotations).To(BeNil())
+		roundTrip := &integrationv1.CouplingApproval{}
+		Expect(spoke.ConvertTo(roundTrip)).To(Succeed())
+		Expect(roundTrip).To(Equal(hub))
+	})
+})
@@ -29,7 +29,6 @@ package v1beta1
 import (
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/kustomize/api/resid"
 )
 
 // DeferredResourceSpec defines the desired state of DeferredResource
@@ -56,6 +55,7 @@ type DeferredResourceStatus struct {
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:deprecatedversion:warning="integration.rock8s.com/v1beta1 DeferredResource is deprecated, use integration.rock8s.com/v1"
 
 // DeferredResource is the Schema for the deferredresources API
 type DeferredResource struct {
@@ -80,7 +80,7 @@ type DeferredResourceList struct {
 // APIVersion is added to keep the backward compatibility of using ObjectReference
 // for Var.ObjRef
 type WaitForTarget struct {
-	resid.Gvk  `json:",inline,omitempty" yaml:",inline,omitempty"`
+	Gvk        `json:",inline,omitempty" yaml:",inline,omitempty"`
 	APIVersion string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
 	Name       string `json:"name,omitempty" yaml:"name,omitempty"`
 }
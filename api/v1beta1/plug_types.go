@@ -139,6 +139,7 @@ type CoupledSocket struct {
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:deprecatedversion:warning="integration.rock8s.com/v1beta1 Plug is deprecated, use integration.rock8s.com/v1"
 
 // Plug is the Schema for the plugs API
 type Plug struct {
@@ -29,7 +29,6 @@ package v1beta1
 import (
 	v1 "k8s.io/api/core/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
-	"sigs.k8s.io/kustomize/api/resid"
 	kustomizeTypes "sigs.k8s.io/kustomize/api/types"
 )
 
@@ -111,13 +110,23 @@ type Var struct {
 	FieldRef kustomizeTypes.FieldSelector `json:"fieldref,omitempty" yaml:"fieldref,omitempty"`
 }
 
+// Gvk identifies a kind of kubernetes object by Group, Version and Kind. It replaces the
+// resid.Gvk of kustomize/api, which v0.13 removed together with the api/resid package, since
+// its successor kyaml/resid.Gvk has an unexported isClusterScoped field that controller-gen
+// rejects when generating the CRDs
+type Gvk struct {
+	Group   string `json:"group,omitempty" yaml:"group,omitempty"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Kind    string `json:"kind,omitempty" yaml:"kind,omitempty"`
+}
+
 // Target refers to a kubernetes object by Group, Version, Kind and Name
 // gvk.Gvk contains Group, Version and Kind
 // APIVersion is added to keep the backward compatibility of using ObjectReference
 // for Var.ObjRef
 type Target struct {
 	APIVersion        string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
-	resid.Gvk         `json:",inline,omitempty" yaml:",inline,omitempty"`
+	Gvk               `json:",inline,omitempty" yaml:",inline,omitempty"`
 	Name              string `json:"name,omitempty" yaml:"name,omitempty"`
 	Namespace         string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 	TemplateName      string `json:"templateName,omitempty" yaml:"templateName,omitempty"`
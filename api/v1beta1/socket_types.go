@@ -97,6 +97,9 @@ type SocketSpec struct {
 
 	// validation
 	Validation *SocketSpecValidation `json:"validation,omitempty"`
+
+	// approval
+	Approval *SocketSpecApproval `json:"approval,omitempty"`
 }
 
 type Interface struct {
@@ -135,6 +138,35 @@ type SocketSpecValidation struct {
 
 	// namespace blacklist
 	NamespaceBlacklist []string `json:"namespaceBlacklist,omitempty"`
+
+	// namespace selector matched against the labels of the plug namespace
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// plug selector matched against the labels of the plug
+	PlugSelector *metav1.LabelSelector `json:"plugSelector,omitempty"`
+
+	// service accounts plugs are allowed to run integrations with,
+	// either as name or namespace/name
+	AllowedServiceAccounts []string `json:"allowedServiceAccounts,omitempty"`
+
+	// CEL rules evaluated against the plug, socket, plugConfig and namespace
+	Rules []ValidationRule `json:"rules,omitempty"`
+}
+
+type ValidationRule struct {
+	// CEL expression that must evaluate to true for the plug to couple
+	Rule string `json:"rule"`
+
+	// message reported when the rule is violated
+	Message string `json:"message,omitempty"`
+}
+
+type SocketSpecApproval struct {
+	// require a coupling approval before a plug can couple
+	Required bool `json:"required,omitempty"`
+
+	// approvers permitted to approve couplings, any approver is permitted if empty
+	Approvers []string `json:"approvers,omitempty"`
 }
 
 // SocketStatus defines the observed state of Socket
@@ -161,10 +193,14 @@ type CoupledPlug struct {
 
 	// UID of the plug
 	UID types.UID `json:"uid"`
+
+	// approver of the coupling
+	ApprovedBy string `json:"approvedBy,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:deprecatedversion:warning="integration.rock8s.com/v1beta1 Socket is deprecated, use integration.rock8s.com/v1"
 
 // Socket is the Schema for the sockets API
 type Socket struct {
@@ -0,0 +1,154 @@
+/**
+ * File: /apparatus/apparatus.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 14:08:40
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+// Package apparatus helps writing apparatus servers for the integration operator.
+//
+// Implement the Apparatus interface, embedding UnimplementedApparatus for the events
+// the apparatus does not handle, and serve it with NewHandler, or with NewGRPCServer
+// when the apparatus uses the grpc protocol.
+package apparatus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
+)
+
+type ConfigRequest = protocol.ConfigRequest
+
+type ConfigResponse = protocol.ConfigResponse
+
+type EventRequest = protocol.EventRequest
+
+type OperationStatusResponse = protocol.OperationStatusResponse
+
+// Apparatus handles the requests the operator sends to an apparatus
+type Apparatus interface {
+	// Config returns the config of the plug or socket
+	Config(ctx context.Context, request *ConfigRequest) (ConfigResponse, error)
+
+	// Created is invoked when the plug or socket is created
+	Created(ctx context.Context, request *EventRequest) error
+
+	// Coupled is invoked when the plug and socket are coupled
+	Coupled(ctx context.Context, request *EventRequest) error
+
+	// Updated is invoked when the plug or socket is updated
+	Updated(ctx context.Context, request *EventRequest) error
+
+	// Decoupled is invoked when the plug and socket are decoupled
+	Decoupled(ctx context.Context, request *EventRequest) error
+
+	// Deleted is invoked when the plug or socket is deleted
+	Deleted(ctx context.Context, request *EventRequest) error
+
+	// OperationStatus returns the status of an operation accepted by an event, which the
+	// operator polls at the default status url of the operation
+	OperationStatus(ctx context.Context, operationID string) (OperationStatusResponse, error)
+}
+
+// UnimplementedApparatus responds to every request without doing anything
+type UnimplementedApparatus struct{}
+
+func (UnimplementedApparatus) Config(ctx context.Context, request *ConfigRequest) (ConfigResponse, error) {
+	return ConfigResponse{}, nil
+}
+
+func (UnimplementedApparatus) Created(ctx context.Context, request *EventRequest) error {
+	return nil
+}
+
+func (UnimplementedApparatus) Coupled(ctx context.Context, request *EventRequest) error {
+	return nil
+}
+
+func (UnimplementedApparatus) Updated(ctx context.Context, request *EventRequest) error {
+	return nil
+}
+
+func (UnimplementedApparatus) Decoupled(ctx context.Context, request *EventRequest) error {
+	return nil
+}
+
+func (UnimplementedApparatus) Deleted(ctx context.Context, request *EventRequest) error {
+	return nil
+}
+
+func (UnimplementedApparatus) OperationStatus(
+	ctx context.Context,
+	operationID string,
+) (OperationStatusResponse, error) {
+	return OperationStatusResponse{}, NewError(http.StatusNotFound, "operation "+operationID+" not found")
+}
+
+// Error is an error responded to the operator with a status
+type Error struct {
+	Status  int
+	Message string
+}
+
+// NewError creates an error responded to the operator with the status
+func NewError(status int, message string) *Error {
+	return &Error{
+		Status:  status,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Accepted is returned by an event to respond that the event is processed asynchronously
+type Accepted struct {
+	protocol.AcceptedResponse
+}
+
+// Accept returns the error an event responds with to process the event asynchronously in
+// the operation, the status url is optional and defaults to /operations/<operationId> which
+// NewHandler serves with OperationStatus
+func Accept(operationID string, statusURL string) *Accepted {
+	return &Accepted{
+		AcceptedResponse: protocol.AcceptedResponse{
+			OperationID: operationID,
+			StatusURL:   statusURL,
+		},
+	}
+}
+
+func (a *Accepted) Error() string {
+	return "operation " + a.OperationID + " accepted"
+}
+
+func errorStatus(err error) int {
+	var apparatusError *Error
+	if errors.As(err, &apparatusError) && apparatusError.Status != 0 {
+		return apparatusError.Status
+	}
+	return http.StatusInternalServerError
+}
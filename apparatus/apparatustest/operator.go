@@ -0,0 +1,267 @@
+/**
+ * File: /apparatus/apparatustest/operator.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 14:40:27
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+// Package apparatustest fakes the integration operator to test apparatus servers.
+package apparatustest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Operator sends the requests of the integration operator to the handler of an apparatus
+type Operator struct {
+	// handler of the apparatus
+	Handler http.Handler
+
+	// secret requests are signed with, requests are not signed when empty
+	SigningSecret []byte
+
+	// protocol version of the requests, defaults to version 2
+	Version protocol.Version
+
+	// callback url sent with events
+	CallbackURL string
+}
+
+// ResponseError is a response of the apparatus with an error status
+type ResponseError struct {
+	Status int
+	Body   string
+}
+
+func (e *ResponseError) Error() string {
+	return "apparatus responded with " + strconv.Itoa(e.Status) + " status: " + e.Body
+}
+
+// NewOperator creates a fake operator for the handler of an apparatus
+func NewOperator(handler http.Handler) *Operator {
+	return &Operator{
+		Handler: handler,
+		Version: protocol.Version2,
+	}
+}
+
+// Capabilities requests the capabilities of the apparatus
+func (o *Operator) Capabilities() (*protocol.Capabilities, error) {
+	response := o.do(http.MethodGet, protocol.CapabilitiesPath, nil)
+	if response.Code >= 400 {
+		return nil, &ResponseError{Status: response.Code, Body: response.Body.String()}
+	}
+	capabilities := &protocol.Capabilities{}
+	if err := json.Unmarshal(response.Body.Bytes(), capabilities); err != nil {
+		return nil, err
+	}
+	return capabilities, nil
+}
+
+// Healthz probes the health of the apparatus
+func (o *Operator) Healthz() error {
+	response := o.do(http.MethodGet, protocol.HealthzPath, nil)
+	if response.Code >= 400 {
+		return &ResponseError{Status: response.Code, Body: response.Body.String()}
+	}
+	return nil
+}
+
+// Config requests the config of the plug or socket from the apparatus
+func (o *Operator) Config(request *protocol.ConfigRequest) (protocol.ConfigResponse, error) {
+	request.Version = o.version()
+	request.Event = protocol.ConfigEvent
+	body, err := o.marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	response := o.do(http.MethodPost, "/config", body)
+	if response.Code >= 400 {
+		return nil, &ResponseError{Status: response.Code, Body: response.Body.String()}
+	}
+	config := protocol.ConfigResponse{}
+	if err := json.Unmarshal(response.Body.Bytes(), &config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Event sends the event to the apparatus and returns the accepted operation when
+// the apparatus processes the event asynchronously
+func (o *Operator) Event(
+	event protocol.Event,
+	request *protocol.EventRequest,
+) (*protocol.AcceptedResponse, error) {
+	request.Version = o.version()
+	request.Event = event
+	if request.CallbackURL == "" {
+		request.CallbackURL = o.CallbackURL
+	}
+	body, err := o.marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	response := o.do(http.MethodPost, "/"+string(event), body)
+	if response.Code >= 400 {
+		return nil, &ResponseError{Status: response.Code, Body: response.Body.String()}
+	}
+	if response.Code == http.StatusAccepted {
+		accepted := &protocol.AcceptedResponse{}
+		if err := json.Unmarshal(response.Body.Bytes(), accepted); err != nil {
+			return nil, err
+		}
+		return accepted, nil
+	}
+	return nil, nil
+}
+
+// Coupled sends the coupled event for the plug and socket to the apparatus
+func (o *Operator) Coupled(
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	plugConfig map[string]string,
+	socketConfig map[string]string,
+) (*protocol.AcceptedResponse, error) {
+	return o.Event(protocol.CoupledEvent, &protocol.EventRequest{
+		Plug:         plug,
+		Socket:       socket,
+		PlugConfig:   plugConfig,
+		SocketConfig: socketConfig,
+	})
+}
+
+// Decoupled sends the decoupled event for the plug and socket to the apparatus
+func (o *Operator) Decoupled(
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	plugConfig map[string]string,
+	socketConfig map[string]string,
+) (*protocol.AcceptedResponse, error) {
+	return o.Event(protocol.DecoupledEvent, &protocol.EventRequest{
+		Plug:         plug,
+		Socket:       socket,
+		PlugConfig:   plugConfig,
+		SocketConfig: socketConfig,
+	})
+}
+
+// OperationStatus polls the status of an operation the apparatus accepted
+func (o *Operator) OperationStatus(operationID string) (*protocol.OperationStatusResponse, error) {
+	response := o.do(http.MethodGet, "/operations/"+operationID, nil)
+	if response.Code >= 400 {
+		return nil, &ResponseError{Status: response.Code, Body: response.Body.String()}
+	}
+	status := &protocol.OperationStatusResponse{}
+	if err := json.Unmarshal(response.Body.Bytes(), status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (o *Operator) version() protocol.Version {
+	if o.Version == 0 {
+		return protocol.Version2
+	}
+	return o.Version
+}
+
+// marshal encodes the request the way the operator does for the protocol version
+func (o *Operator) marshal(request interface{}) ([]byte, error) {
+	body, err := json.Marshal(request)
+	if err != nil || o.version() != protocol.Version1 {
+		return body, err
+	}
+	legacyRequest := map[string]interface{}{}
+	if err := json.Unmarshal(body, &legacyRequest); err != nil {
+		return nil, err
+	}
+	if legacyRequest["event"] != string(protocol.ConfigEvent) {
+		legacyRequest["version"] = "1"
+	}
+	delete(legacyRequest, "event")
+	return json.Marshal(legacyRequest)
+}
+
+func (o *Operator) do(method string, path string, body []byte) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, bytes.NewReader(body))
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if len(o.SigningSecret) > 0 {
+		timestamp := time.Now()
+		request.Header.Set(protocol.TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+		request.Header.Set(protocol.SignatureHeader, protocol.Sign(
+			o.SigningSecret,
+			timestamp,
+			method,
+			request.URL.EscapedPath(),
+			body,
+		))
+	}
+	response := httptest.NewRecorder()
+	o.Handler.ServeHTTP(response, request)
+	return response
+}
+
+// NewPlug creates a plug for the socket to send to the apparatus
+func NewPlug(name string, namespace string, socketName string) *integrationv1.Plug {
+	return &integrationv1.Plug{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: integrationv1.GroupVersion.String(),
+			Kind:       "Plug",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(strings.Join([]string{namespace, name, "plug"}, "-")),
+		},
+		Spec: integrationv1.PlugSpec{
+			Socket: integrationv1.NamespacedName{Name: socketName},
+		},
+	}
+}
+
+// NewSocket creates a socket to send to the apparatus
+func NewSocket(name string, namespace string) *integrationv1.Socket {
+	return &integrationv1.Socket{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: integrationv1.GroupVersion.String(),
+			Kind:       "Socket",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(strings.Join([]string{namespace, name, "socket"}, "-")),
+		},
+	}
+}
@@ -0,0 +1,270 @@
+/**
+ * File: /apparatus/grpc.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 16:05:39
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package apparatus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol/apparatuspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+type grpcServer struct {
+	apparatuspb.UnimplementedApparatusServer
+	apparatus Apparatus
+	options   HandlerOptions
+}
+
+// NewGRPCServer serves the apparatus to an operator using the grpc protocol, register it
+// with apparatuspb.RegisterApparatusServer on a server created with GRPCServerOption
+func NewGRPCServer(apparatus Apparatus, options *HandlerOptions) apparatuspb.ApparatusServer {
+	s := &grpcServer{apparatus: apparatus}
+	if options != nil {
+		s.options = *options
+	}
+	if len(s.options.Events) == 0 {
+		s.options.Events = protocol.AllEvents
+	}
+	return s
+}
+
+func (s *grpcServer) GetConfig(
+	ctx context.Context,
+	request *apparatuspb.ConfigRequest,
+) (*apparatuspb.ConfigResponse, error) {
+	if err := s.verify(ctx); err != nil {
+		return nil, err
+	}
+	configRequest := &ConfigRequest{
+		Version:    protocol.Version(request.Version),
+		Event:      protocol.ConfigEvent,
+		PlugData:   request.PlugData,
+		SocketData: request.SocketData,
+		Vars:       request.Vars,
+	}
+	var err error
+	if configRequest.Plug, configRequest.Socket, err = unmarshalObjects(request.Plug, request.Socket); err != nil {
+		return nil, err
+	}
+	config, err := s.apparatus.Config(ctx, configRequest)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &apparatuspb.ConfigResponse{Config: config}, nil
+}
+
+func (s *grpcServer) OnEvent(
+	ctx context.Context,
+	request *apparatuspb.EventRequest,
+) (*apparatuspb.EventResponse, error) {
+	if err := s.verify(ctx); err != nil {
+		return nil, err
+	}
+	var handle func(ctx context.Context, request *EventRequest) error
+	switch protocol.Event(request.Event) {
+	case protocol.CreatedEvent:
+		handle = s.apparatus.Created
+	case protocol.CoupledEvent:
+		handle = s.apparatus.Coupled
+	case protocol.UpdatedEvent:
+		handle = s.apparatus.Updated
+	case protocol.DecoupledEvent:
+		handle = s.apparatus.Decoupled
+	case protocol.DeletedEvent:
+		handle = s.apparatus.Deleted
+	default:
+		return nil, status.Error(codes.InvalidArgument, "unknown event "+request.Event)
+	}
+	eventRequest := &EventRequest{
+		Version:      protocol.Version(request.Version),
+		Event:        protocol.Event(request.Event),
+		PlugConfig:   request.PlugConfig,
+		SocketConfig: request.SocketConfig,
+		CallbackURL:  request.CallbackUrl,
+	}
+	var err error
+	if eventRequest.Plug, eventRequest.Socket, err = unmarshalObjects(request.Plug, request.Socket); err != nil {
+		return nil, err
+	}
+	if err := handle(ctx, eventRequest); err != nil {
+		var accepted *Accepted
+		if errors.As(err, &accepted) {
+			return &apparatuspb.EventResponse{
+				OperationId: accepted.OperationID,
+				StatusUrl:   accepted.StatusURL,
+			}, nil
+		}
+		return nil, grpcError(err)
+	}
+	return &apparatuspb.EventResponse{}, nil
+}
+
+func (s *grpcServer) Health(
+	ctx context.Context,
+	request *apparatuspb.HealthRequest,
+) (*apparatuspb.HealthResponse, error) {
+	response := &apparatuspb.HealthResponse{Status: apparatuspb.HealthResponse_STATUS_SERVING}
+	if s.options.HealthCheck != nil {
+		if err := s.options.HealthCheck(ctx); err != nil {
+			response.Status = apparatuspb.HealthResponse_STATUS_NOT_SERVING
+		}
+	}
+	for _, event := range s.options.Events {
+		response.Events = append(response.Events, string(event))
+	}
+	return response, nil
+}
+
+// verify checks the signature in the metadata against the full method and the bytes of
+// the request received by the server
+func (s *grpcServer) verify(ctx context.Context) error {
+	if len(s.options.SigningSecret) == 0 {
+		return nil
+	}
+	payload, ok := ctx.Value(receivedPayloadKey{}).(*receivedPayload)
+	if !ok {
+		return status.Error(
+			codes.FailedPrecondition,
+			"grpc server must be created with apparatus.GRPCServerOption to verify signed requests",
+		)
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	method, _ := grpc.Method(ctx)
+	if err := protocol.Verify(
+		s.options.SigningSecret,
+		firstMetadata(md, protocol.TimestampHeader),
+		firstMetadata(md, protocol.SignatureHeader),
+		http.MethodPost,
+		method,
+		payload.data,
+		s.options.SignatureTolerance,
+	); err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return nil
+}
+
+type receivedPayloadKey struct{}
+
+type receivedPayload struct {
+	data []byte
+}
+
+// GRPCServerOption keeps the bytes of the requests the server receives, so signed requests
+// are verified against exactly what the operator sent. Pass it to grpc.NewServer when the
+// apparatus has a signing secret.
+func GRPCServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(&payloadHandler{})
+}
+
+// payloadHandler records the bytes of the request of each rpc in its context
+type payloadHandler struct{}
+
+func (h *payloadHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, receivedPayloadKey{}, &receivedPayload{})
+}
+
+func (h *payloadHandler) HandleRPC(ctx context.Context, rpcStats stats.RPCStats) {
+	in, ok := rpcStats.(*stats.InPayload)
+	if !ok || in.Client {
+		return
+	}
+	if payload, ok := ctx.Value(receivedPayloadKey{}).(*receivedPayload); ok {
+		payload.data = in.Data
+	}
+}
+
+func (h *payloadHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *payloadHandler) HandleConn(ctx context.Context, connStats stats.ConnStats) {}
+
+func firstMetadata(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func unmarshalObjects(
+	plugBody []byte,
+	socketBody []byte,
+) (*integrationv1.Plug, *integrationv1.Socket, error) {
+	var plug *integrationv1.Plug
+	var socket *integrationv1.Socket
+	if len(plugBody) > 0 {
+		plug = &integrationv1.Plug{}
+		if err := json.Unmarshal(plugBody, plug); err != nil {
+			return nil, nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if len(socketBody) > 0 {
+		socket = &integrationv1.Socket{}
+		if err := json.Unmarshal(socketBody, socket); err != nil {
+			return nil, nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	return plug, socket, nil
+}
+
+// grpcError converts the error to a grpc status with the code equivalent to its http status
+func grpcError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := codes.Internal
+	switch errorStatus(err) {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.Aborted
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		code = codes.Unimplemented
+	case http.StatusGatewayTimeout:
+		code = codes.DeadlineExceeded
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,215 @@
+/**
+ * File: /apparatus/grpc_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 16:40:52
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package apparatus_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gitlab.com/bitspur/rock8s/integration-operator/apparatus"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol/apparatuspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+var _ = Describe("GRPCServer", func() {
+	var (
+		testApp *testApparatus
+		server  *grpc.Server
+		conn    *grpc.ClientConn
+		client  apparatuspb.ApparatusClient
+		options *apparatus.HandlerOptions
+	)
+
+	JustBeforeEach(func() {
+		listener := bufconn.Listen(1 << 20)
+		server = grpc.NewServer(apparatus.GRPCServerOption())
+		apparatuspb.RegisterApparatusServer(server, apparatus.NewGRPCServer(testApp, options))
+		go server.Serve(listener)
+		var err error
+		conn, err = grpc.Dial(
+			"bufconn",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		client = apparatuspb.NewApparatusClient(conn)
+	})
+
+	BeforeEach(func() {
+		testApp = &testApparatus{}
+		options = nil
+	})
+
+	AfterEach(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	It("responds with the config", func() {
+		response, err := client.GetConfig(context.Background(), &apparatuspb.ConfigRequest{
+			Version: 2,
+			Plug:    []byte(`{"metadata":{"name":"my-plug"}}`),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Config).To(HaveKeyWithValue("hello", "my-plug"))
+		Expect(testApp.configRequests[0].Event).To(Equal(protocol.ConfigEvent))
+		Expect(testApp.configRequests[0].Socket).To(BeNil())
+	})
+
+	It("routes events to the apparatus", func() {
+		response, err := client.OnEvent(context.Background(), &apparatuspb.EventRequest{
+			Version:    2,
+			Event:      string(protocol.CoupledEvent),
+			Socket:     []byte(`{"metadata":{"name":"my-socket"}}`),
+			PlugConfig: map[string]string{"a": "b"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.OperationId).To(BeEmpty())
+		Expect(testApp.eventRequests[0].Socket.Name).To(Equal("my-socket"))
+		Expect(testApp.eventRequests[0].PlugConfig).To(HaveKeyWithValue("a", "b"))
+	})
+
+	It("rejects unknown events", func() {
+		_, err := client.OnEvent(context.Background(), &apparatuspb.EventRequest{Event: "exploded"})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+	})
+
+	It("responds with the operation of accepted events", func() {
+		testApp.err = apparatus.Accept("op-1", "http://my-apparatus/operations/op-1")
+		response, err := client.OnEvent(context.Background(), &apparatuspb.EventRequest{
+			Event: string(protocol.CoupledEvent),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.OperationId).To(Equal("op-1"))
+		Expect(response.StatusUrl).To(Equal("http://my-apparatus/operations/op-1"))
+	})
+
+	It("converts errors to grpc statuses", func() {
+		testApp.err = apparatus.NewError(http.StatusConflict, "not ready")
+		_, err := client.OnEvent(context.Background(), &apparatuspb.EventRequest{
+			Event: string(protocol.CoupledEvent),
+		})
+		Expect(status.Code(err)).To(Equal(codes.Aborted))
+		Expect(status.Convert(err).Message()).To(Equal("not ready"))
+	})
+
+	Context("with events", func() {
+		BeforeEach(func() {
+			options = &apparatus.HandlerOptions{Events: []protocol.Event{protocol.ConfigEvent}}
+		})
+
+		It("reports the events in its health", func() {
+			response, err := client.Health(context.Background(), &apparatuspb.HealthRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Status).To(Equal(apparatuspb.HealthResponse_STATUS_SERVING))
+			Expect(response.Events).To(Equal([]string{"config"}))
+		})
+	})
+
+	Context("with a signing secret", func() {
+		BeforeEach(func() {
+			options = &apparatus.HandlerOptions{SigningSecret: []byte("secret")}
+		})
+
+		signBody := func(secret string, method string, body []byte) (context.Context, grpc.CallOption) {
+			timestamp := time.Now()
+			return metadata.AppendToOutgoingContext(
+				context.Background(),
+				protocol.TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10),
+				protocol.SignatureHeader, protocol.Sign([]byte(secret), timestamp, http.MethodPost, method, body),
+			), grpc.ForceCodec(apparatuspb.MarshaledCodec{Body: body})
+		}
+
+		sign := func(secret string, method string, request proto.Message) (context.Context, grpc.CallOption) {
+			body, err := proto.Marshal(request)
+			Expect(err).NotTo(HaveOccurred())
+			return signBody(secret, method, body)
+		}
+
+		It("accepts signed requests", func() {
+			request := &apparatuspb.EventRequest{
+				Event:      string(protocol.CoupledEvent),
+				PlugConfig: map[string]string{"a": "b", "c": "d"},
+			}
+			ctx, codec := sign("secret", apparatuspb.Apparatus_OnEvent_FullMethodName, request)
+			_, err := client.OnEvent(ctx, request, codec)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("verifies the bytes that were sent instead of a canonical encoding", func() {
+			configBody, err := proto.Marshal(&apparatuspb.EventRequest{PlugConfig: map[string]string{"a": "b"}})
+			Expect(err).NotTo(HaveOccurred())
+			eventBody, err := proto.Marshal(&apparatuspb.EventRequest{Event: string(protocol.CoupledEvent)})
+			Expect(err).NotTo(HaveOccurred())
+			ctx, codec := signBody(
+				"secret",
+				apparatuspb.Apparatus_OnEvent_FullMethodName,
+				append(configBody, eventBody...),
+			)
+			_, err = client.OnEvent(ctx, &apparatuspb.EventRequest{}, codec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testApp.eventRequests).To(HaveLen(1))
+			Expect(testApp.eventRequests[0].Event).To(Equal(protocol.CoupledEvent))
+			Expect(testApp.eventRequests[0].PlugConfig).To(HaveKeyWithValue("a", "b"))
+		})
+
+		It("rejects unsigned requests", func() {
+			_, err := client.GetConfig(context.Background(), &apparatuspb.ConfigRequest{})
+			Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+		})
+
+		It("rejects requests signed with another secret", func() {
+			request := &apparatuspb.EventRequest{Event: string(protocol.CoupledEvent)}
+			ctx, codec := sign("other", apparatuspb.Apparatus_OnEvent_FullMethodName, request)
+			_, err := client.OnEvent(ctx, request, codec)
+			Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+			Expect(testApp.eventRequests).To(BeEmpty())
+		})
+
+		It("rejects requests signed for another method", func() {
+			request := &apparatuspb.EventRequest{Event: string(protocol.CoupledEvent)}
+			ctx, codec := sign("secret", apparatuspb.Apparatus_GetConfig_FullMethodName, request)
+			_, err := client.OnEvent(ctx, request, codec)
+			Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+			Expect(testApp.eventRequests).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,241 @@
+/**
+ * File: /apparatus/handler.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 14:21:03
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package apparatus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
+)
+
+const operationsPath = "/operations/"
+
+// HandlerOptions configures the handler of an apparatus
+type HandlerOptions struct {
+	// secret the operator signs requests with, requests are not verified when empty
+	SigningSecret []byte
+
+	// how old a signed request may be, defaults to 5 minutes
+	SignatureTolerance time.Duration
+
+	// events the apparatus reports in its capabilities, defaults to every event
+	Events []protocol.Event
+
+	// limit of the size of request bodies in bytes, defaults to 10 MiB
+	MaxBodySize int64
+
+	// checks the health of the apparatus, the apparatus is unhealthy when it returns an error
+	HealthCheck func(ctx context.Context) error
+}
+
+type handler struct {
+	apparatus Apparatus
+	options   HandlerOptions
+}
+
+// NewHandler routes the requests of the operator to the apparatus
+func NewHandler(apparatus Apparatus, options *HandlerOptions) http.Handler {
+	h := &handler{apparatus: apparatus}
+	if options != nil {
+		h.options = *options
+	}
+	if len(h.options.Events) == 0 {
+		h.options.Events = protocol.AllEvents
+	}
+	if h.options.MaxBodySize <= 0 {
+		h.options.MaxBodySize = 10 << 20
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ping", h.ping)
+	mux.HandleFunc(protocol.HealthzPath, h.healthz)
+	mux.HandleFunc(protocol.CapabilitiesPath, h.capabilities)
+	mux.HandleFunc("/config", h.config)
+	mux.HandleFunc("/created", h.event(protocol.CreatedEvent, apparatus.Created))
+	mux.HandleFunc("/coupled", h.event(protocol.CoupledEvent, apparatus.Coupled))
+	mux.HandleFunc("/updated", h.event(protocol.UpdatedEvent, apparatus.Updated))
+	mux.HandleFunc("/decoupled", h.event(protocol.DecoupledEvent, apparatus.Decoupled))
+	mux.HandleFunc("/deleted", h.event(protocol.DeletedEvent, apparatus.Deleted))
+	mux.HandleFunc(operationsPath, h.operationStatus)
+	return mux
+}
+
+func (h *handler) ping(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *handler) healthz(w http.ResponseWriter, r *http.Request) {
+	if h.options.HealthCheck != nil {
+		if err := h.options.HealthCheck(r.Context()); err != nil {
+			writeError(w, NewError(http.StatusServiceUnavailable, err.Error()))
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *handler) capabilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, protocol.Capabilities{
+		Versions: []protocol.Version{protocol.Version1, protocol.Version2},
+		Events:   h.options.Events,
+	})
+}
+
+func (h *handler) config(w http.ResponseWriter, r *http.Request) {
+	body, ok := h.readBody(w, r)
+	if !ok {
+		return
+	}
+	request := &ConfigRequest{}
+	if err := json.Unmarshal(body, request); err != nil {
+		writeError(w, NewError(http.StatusBadRequest, err.Error()))
+		return
+	}
+	if request.Event == "" {
+		request.Event = protocol.ConfigEvent
+	}
+	config, err := h.apparatus.Config(r.Context(), request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if config == nil {
+		config = ConfigResponse{}
+	}
+	writeJSON(w, http.StatusOK, config)
+}
+
+func (h *handler) event(
+	event protocol.Event,
+	handle func(ctx context.Context, request *EventRequest) error,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, ok := h.readBody(w, r)
+		if !ok {
+			return
+		}
+		request := &EventRequest{}
+		if err := json.Unmarshal(body, request); err != nil {
+			writeError(w, NewError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		if request.Event == "" {
+			request.Event = event
+		}
+		if err := handle(r.Context(), request); err != nil {
+			var accepted *Accepted
+			if errors.As(err, &accepted) {
+				writeJSON(w, http.StatusAccepted, accepted.AcceptedResponse)
+				return
+			}
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// operationStatus responds with the status of the operation the operator polls, which is
+// not signed like the other GET requests of the operator
+func (h *handler) operationStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	operationID := strings.TrimPrefix(r.URL.Path, operationsPath)
+	if operationID == "" || strings.Contains(operationID, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	status, err := h.apparatus.OperationStatus(r.Context(), operationID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if status.Status == "" {
+		status.Status = protocol.OperationPending
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// readBody reads the body of a POST request and verifies its signature
+func (h *handler) readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.options.MaxBodySize))
+	if err != nil {
+		writeError(w, NewError(http.StatusRequestEntityTooLarge, err.Error()))
+		return nil, false
+	}
+	if len(h.options.SigningSecret) > 0 {
+		if err := protocol.Verify(
+			h.options.SigningSecret,
+			r.Header.Get(protocol.TimestampHeader),
+			r.Header.Get(protocol.SignatureHeader),
+			r.Method,
+			requestPath(r),
+			body,
+			h.options.SignatureTolerance,
+		); err != nil {
+			writeError(w, NewError(http.StatusUnauthorized, err.Error()))
+			return nil, false
+		}
+	}
+	return body, true
+}
+
+// requestPath gets the escaped path the request was sent to, which is read from the
+// request uri so the signature still matches when the handler is mounted behind
+// http.StripPrefix
+func requestPath(r *http.Request) string {
+	if requestURL, err := url.ParseRequestURI(r.RequestURI); err == nil {
+		return requestURL.EscapedPath()
+	}
+	return r.URL.EscapedPath()
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	http.Error(w, strings.TrimSpace(err.Error()), errorStatus(err))
+}
@@ -0,0 +1,254 @@
+/**
+ * File: /apparatus/handler_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 14:55:31
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package apparatus_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gitlab.com/bitspur/rock8s/integration-operator/apparatus"
+	"gitlab.com/bitspur/rock8s/integration-operator/apparatus/apparatustest"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
+)
+
+type testApparatus struct {
+	apparatus.UnimplementedApparatus
+	configRequests []*apparatus.ConfigRequest
+	eventRequests  []*apparatus.EventRequest
+	err            error
+}
+
+func (a *testApparatus) Config(
+	ctx context.Context,
+	request *apparatus.ConfigRequest,
+) (apparatus.ConfigResponse, error) {
+	a.configRequests = append(a.configRequests, request)
+	if a.err != nil {
+		return nil, a.err
+	}
+	return apparatus.ConfigResponse{"hello": request.Plug.Name}, nil
+}
+
+func (a *testApparatus) OperationStatus(
+	ctx context.Context,
+	operationID string,
+) (apparatus.OperationStatusResponse, error) {
+	if operationID != "op-1" {
+		return a.UnimplementedApparatus.OperationStatus(ctx, operationID)
+	}
+	return apparatus.OperationStatusResponse{Status: protocol.OperationSucceeded, Message: "provisioned"}, nil
+}
+
+func (a *testApparatus) Coupled(ctx context.Context, request *apparatus.EventRequest) error {
+	a.eventRequests = append(a.eventRequests, request)
+	return a.err
+}
+
+var _ = Describe("Handler", func() {
+	var (
+		testApp  *testApparatus
+		operator *apparatustest.Operator
+		plug     = apparatustest.NewPlug("my-plug", "plug-ns", "my-socket")
+		socket   = apparatustest.NewSocket("my-socket", "socket-ns")
+	)
+
+	BeforeEach(func() {
+		testApp = &testApparatus{}
+		operator = apparatustest.NewOperator(apparatus.NewHandler(testApp, nil))
+	})
+
+	It("responds with the config", func() {
+		config, err := operator.Config(&protocol.ConfigRequest{Plug: plug, Socket: socket})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(Equal(protocol.ConfigResponse{"hello": "my-plug"}))
+		Expect(testApp.configRequests).To(HaveLen(1))
+		Expect(testApp.configRequests[0].Version).To(Equal(protocol.Version2))
+		Expect(testApp.configRequests[0].Event).To(Equal(protocol.ConfigEvent))
+	})
+
+	It("routes events to the apparatus", func() {
+		accepted, err := operator.Coupled(plug, socket, map[string]string{"a": "b"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(accepted).To(BeNil())
+		Expect(testApp.eventRequests).To(HaveLen(1))
+		Expect(testApp.eventRequests[0].Event).To(Equal(protocol.CoupledEvent))
+		Expect(testApp.eventRequests[0].PlugConfig).To(HaveKeyWithValue("a", "b"))
+		Expect(testApp.eventRequests[0].Socket.Name).To(Equal("my-socket"))
+	})
+
+	It("responds to unimplemented events", func() {
+		_, err := operator.Decoupled(plug, socket, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("accepts legacy version 1 requests", func() {
+		operator.Version = protocol.Version1
+		_, err := operator.Coupled(plug, socket, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(testApp.eventRequests[0].Version).To(Equal(protocol.Version1))
+		Expect(testApp.eventRequests[0].Event).To(Equal(protocol.CoupledEvent))
+		_, err = operator.Config(&protocol.ConfigRequest{Plug: plug})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(testApp.configRequests[0].Event).To(Equal(protocol.ConfigEvent))
+	})
+
+	It("responds with the status of the error", func() {
+		testApp.err = apparatus.NewError(http.StatusConflict, "not ready")
+		_, err := operator.Coupled(plug, socket, nil, nil)
+		var responseError *apparatustest.ResponseError
+		Expect(errors.As(err, &responseError)).To(BeTrue())
+		Expect(responseError.Status).To(Equal(http.StatusConflict))
+		Expect(responseError.Body).To(ContainSubstring("not ready"))
+
+		testApp.err = errors.New("boom")
+		_, err = operator.Config(&protocol.ConfigRequest{Plug: plug})
+		Expect(errors.As(err, &responseError)).To(BeTrue())
+		Expect(responseError.Status).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("accepts events processed asynchronously", func() {
+		testApp.err = apparatus.Accept("op-1", "")
+		accepted, err := operator.Coupled(plug, socket, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(accepted).NotTo(BeNil())
+		Expect(accepted.OperationID).To(Equal("op-1"))
+	})
+
+	It("responds with the status of operations at the default status url", func() {
+		status, err := operator.OperationStatus("op-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Status).To(Equal(protocol.OperationSucceeded))
+		Expect(status.Message).To(Equal("provisioned"))
+		_, err = operator.OperationStatus("op-2")
+		var responseError *apparatustest.ResponseError
+		Expect(errors.As(err, &responseError)).To(BeTrue())
+		Expect(responseError.Status).To(Equal(http.StatusNotFound))
+	})
+
+	It("reports its capabilities", func() {
+		operator = apparatustest.NewOperator(apparatus.NewHandler(testApp, &apparatus.HandlerOptions{
+			Events: []protocol.Event{protocol.ConfigEvent, protocol.CoupledEvent},
+		}))
+		capabilities, err := operator.Capabilities()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(capabilities.SupportsVersion(protocol.Version2)).To(BeTrue())
+		Expect(capabilities.SupportsEvent(protocol.CoupledEvent)).To(BeTrue())
+		Expect(capabilities.SupportsEvent(protocol.DecoupledEvent)).To(BeFalse())
+	})
+
+	It("reports its health", func() {
+		Expect(operator.Healthz()).To(Succeed())
+		operator = apparatustest.NewOperator(apparatus.NewHandler(testApp, &apparatus.HandlerOptions{
+			HealthCheck: func(ctx context.Context) error {
+				return errors.New("database is down")
+			},
+		}))
+		err := operator.Healthz()
+		var responseError *apparatustest.ResponseError
+		Expect(errors.As(err, &responseError)).To(BeTrue())
+		Expect(responseError.Status).To(Equal(http.StatusServiceUnavailable))
+		Expect(responseError.Body).To(ContainSubstring("database is down"))
+	})
+
+	Context("with a signing secret", func() {
+		BeforeEach(func() {
+			operator = apparatustest.NewOperator(apparatus.NewHandler(testApp, &apparatus.HandlerOptions{
+				SigningSecret: []byte("secret"),
+			}))
+		})
+
+		It("accepts signed requests", func() {
+			operator.SigningSecret = []byte("secret")
+			_, err := operator.Coupled(plug, socket, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects unsigned requests", func() {
+			_, err := operator.Coupled(plug, socket, nil, nil)
+			var responseError *apparatustest.ResponseError
+			Expect(errors.As(err, &responseError)).To(BeTrue())
+			Expect(responseError.Status).To(Equal(http.StatusUnauthorized))
+			Expect(testApp.eventRequests).To(BeEmpty())
+		})
+
+		It("rejects requests signed with another secret", func() {
+			operator.SigningSecret = []byte("other")
+			_, err := operator.Config(&protocol.ConfigRequest{Plug: plug})
+			var responseError *apparatustest.ResponseError
+			Expect(errors.As(err, &responseError)).To(BeTrue())
+			Expect(responseError.Status).To(Equal(http.StatusUnauthorized))
+		})
+
+		signedRequest := func(signedPath string, path string) *http.Request {
+			body, err := json.Marshal(&protocol.EventRequest{
+				Version: protocol.Version2,
+				Event:   protocol.CoupledEvent,
+				Plug:    plug,
+				Socket:  socket,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			timestamp := time.Now()
+			request := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set(protocol.TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+			request.Header.Set(protocol.SignatureHeader, protocol.Sign(
+				[]byte("secret"),
+				timestamp,
+				http.MethodPost,
+				signedPath,
+				body,
+			))
+			return request
+		}
+
+		It("rejects a signed body replayed against another path", func() {
+			response := httptest.NewRecorder()
+			apparatus.NewHandler(testApp, &apparatus.HandlerOptions{
+				SigningSecret: []byte("secret"),
+			}).ServeHTTP(response, signedRequest("/decoupled", "/coupled"))
+			Expect(response.Code).To(Equal(http.StatusUnauthorized))
+			Expect(testApp.eventRequests).To(BeEmpty())
+		})
+
+		It("verifies the original path behind a stripped prefix", func() {
+			response := httptest.NewRecorder()
+			http.StripPrefix("/apparatus", apparatus.NewHandler(testApp, &apparatus.HandlerOptions{
+				SigningSecret: []byte("secret"),
+			})).ServeHTTP(response, signedRequest("/apparatus/coupled", "/apparatus/coupled"))
+			Expect(response.Code).To(BeNumerically("<", 300))
+			Expect(testApp.eventRequests).To(HaveLen(1))
+		})
+	})
+})
@@ -40,3 +40,47 @@ var StartTime metav1.Time = metav1.Now()
 var DebugPlugEndpoint = os.Getenv("DEBUG_PLUG_ENDPOINT")
 
 var DebugSocketEndpoint = os.Getenv("DEBUG_SOCKET_ENDPOINT")
+
+var MaxConditionHistory = 20
+
+var WarningEventInterval time.Duration = time.Minute * 5
+
+var CloudEventSinks = os.Getenv("CLOUD_EVENT_SINKS")
+
+var CloudEventBufferSize = 1000
+
+var CloudEventRetryCount = 3
+
+var CloudEventRetryWaitTime time.Duration = time.Second
+
+var CloudEventTimeout time.Duration = time.Second * 10
+
+var ApparatusCallbackURL = os.Getenv("APPARATUS_CALLBACK_URL")
+
+var ApparatusCapabilitiesTTL time.Duration = time.Minute * 5
+
+var ApparatusHealthTTL time.Duration = time.Second * 10
+
+var ApparatusDiagnosticsMaxSize = 256 * 1024
+
+var ApparatusCallbackMaxSize = 64 * 1024
+
+var ResourceSourcesPath = os.Getenv("RESOURCE_SOURCES_PATH")
+
+var ResourceSourceMaxSize = 32 * 1024 * 1024
+
+var ResourceSourceCacheTTL time.Duration = time.Minute * 5
+
+var ResourceSourceCacheSize = 8
+
+var StrictTemplates = os.Getenv("STRICT_TEMPLATES") == "true"
+
+var TemplateCacheSize = 1024
+
+var CELProgramCacheSize = 1024
+
+var CELCostLimit uint64 = 1000000
+
+var CELInterruptCheckFrequency uint = 100
+
+var EnableWebhooks = os.Getenv("ENABLE_WEBHOOKS") != "false"
@@ -56,7 +56,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	"gitlab.com/bitspur/rock8s/integration-operator/util"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 )
@@ -74,7 +74,7 @@ type DeferredResourceReconciler struct {
 func (r *DeferredResourceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.V(1).Info("DeferredResource Reconcile")
-	deferredResourceUtil := util.NewDeferredResourceUtil(&r.Client, ctx, &req, &integrationv1beta1.NamespacedName{
+	deferredResourceUtil := util.NewDeferredResourceUtil(&r.Client, ctx, &req, &integrationv1.NamespacedName{
 		Name:      req.NamespacedName.Name,
 		Namespace: req.NamespacedName.Namespace,
 	})
@@ -88,11 +88,11 @@ func (r *DeferredResourceReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	)
 
 	if deferredResource.GetDeletionTimestamp() != nil {
-		if controllerutil.ContainsFinalizer(deferredResource, integrationv1beta1.Finalizer) {
+		if controllerutil.ContainsFinalizer(deferredResource, integrationv1.Finalizer) {
 			if err := deferredResourceUtil.DeleteResource(deferredResource, kubectlUtil); err != nil {
 				return deferredResourceUtil.Error(err, deferredResource)
 			}
-			controllerutil.RemoveFinalizer(deferredResource, integrationv1beta1.Finalizer)
+			controllerutil.RemoveFinalizer(deferredResource, integrationv1.Finalizer)
 			return deferredResourceUtil.Update(deferredResource, true)
 		}
 		return ctrl.Result{}, nil
@@ -111,7 +111,7 @@ func (r *DeferredResourceReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	}
 
 	if deferredResource.Spec.WaitFor != nil {
-		for _, waitFor := range *deferredResource.Spec.WaitFor {
+		for _, waitFor := range deferredResource.Spec.WaitFor {
 			apiVersion := waitFor.APIVersion
 			if apiVersion == "" {
 				group := ""
@@ -161,6 +161,6 @@ func (r *DeferredResourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}).
-		For(&integrationv1beta1.DeferredResource{}).
+		For(&integrationv1.DeferredResource{}).
 		Complete(r)
 }
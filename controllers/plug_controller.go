@@ -39,10 +39,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	"gitlab.com/bitspur/rock8s/integration-operator/coupler"
 	"gitlab.com/bitspur/rock8s/integration-operator/util"
 )
@@ -57,31 +60,33 @@ type PlugReconciler struct {
 //+kubebuilder:rbac:groups=integration.rock8s.com,resources=plugs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=integration.rock8s.com,resources=plugs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=integration.rock8s.com,resources=plugs/finalizers,verbs=update
+//+kubebuilder:rbac:groups=integration.rock8s.com,resources=couplingapprovals,verbs=get;list;watch
 
 func (r *PlugReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = util.WithTemplatePass(ctx)
 	logger := log.FromContext(ctx)
 	logger.V(1).Info("Plug Reconcile")
-	namespacedName := integrationv1beta1.NamespacedName{
+	namespacedName := integrationv1.NamespacedName{
 		Name:      req.NamespacedName.Name,
 		Namespace: req.NamespacedName.Namespace,
 	}
-	plug := &integrationv1beta1.Plug{}
+	plug := &integrationv1.Plug{}
 	if err := r.Client.Get(ctx, util.EnsureNamespacedName(&namespacedName, util.GetOperatorNamespace()), plug); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 	plug = plug.DeepCopy()
-	socketUtil := util.NewSocketUtil(&r.Client, ctx, &req, &integrationv1beta1.NamespacedName{
+	socketUtil := util.NewSocketUtil(&r.Client, ctx, &req, &integrationv1.NamespacedName{
 		Name:      plug.Spec.Socket.Name,
 		Namespace: util.Default(plug.Spec.Socket.Namespace, req.NamespacedName.Namespace),
-	})
+	}, r.Recorder)
 	socket, err := socketUtil.Get()
-	plugUtil := util.NewPlugUtil(&r.Client, ctx, &req, &namespacedName, socket)
+	plugUtil := util.NewPlugUtil(&r.Client, ctx, &req, &namespacedName, socket, r.Recorder)
 	if err != nil && !errors.IsNotFound(err) {
 		return plugUtil.Error(err, plug)
 	}
 
 	if plug.GetDeletionTimestamp() != nil {
-		if controllerutil.ContainsFinalizer(plug, integrationv1beta1.Finalizer) {
+		if controllerutil.ContainsFinalizer(plug, integrationv1.Finalizer) {
 			if plug.Status.CoupledSocket != nil && socket != nil {
 				if err := coupler.Decouple(&r.Client, ctx, &req, plugUtil, socketUtil, plug, socket, r.Recorder); err != nil {
 					return plugUtil.Error(err, plug)
@@ -90,14 +95,14 @@ func (r *PlugReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 			if err := coupler.DeletedPlug(plug, r.Recorder); err != nil {
 				return plugUtil.Error(err, plug)
 			}
-			controllerutil.RemoveFinalizer(plug, integrationv1beta1.Finalizer)
+			controllerutil.RemoveFinalizer(plug, integrationv1.Finalizer)
 			return plugUtil.Update(plug, true)
 		}
 		return ctrl.Result{}, nil
 	}
 
-	if !controllerutil.ContainsFinalizer(plug, integrationv1beta1.Finalizer) {
-		controllerutil.AddFinalizer(plug, integrationv1beta1.Finalizer)
+	if !controllerutil.ContainsFinalizer(plug, integrationv1.Finalizer) {
+		controllerutil.AddFinalizer(plug, integrationv1.Finalizer)
 		return plugUtil.Update(plug, true)
 	}
 
@@ -137,6 +142,23 @@ func (r *PlugReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}).
 		WithEventFilter(filterPlugPredicate()).
-		For(&integrationv1beta1.Plug{}).
+		For(&integrationv1.Plug{}).
+		Watches(
+			&source.Kind{Type: &integrationv1.CouplingApproval{}},
+			handler.EnqueueRequestsFromMapFunc(mapCouplingApprovalToPlug),
+		).
+		Watches(
+			&source.Channel{Source: util.PlugOperationEvents},
+			&handler.EnqueueRequestForObject{},
+		).
 		Complete(r)
 }
+
+func mapCouplingApprovalToPlug(obj client.Object) []reconcile.Request {
+	approval, ok := obj.(*integrationv1.CouplingApproval)
+	if !ok {
+		return nil
+	}
+	plugNamespacedName := util.EnsureNamespacedName(&approval.Spec.Plug, approval.Namespace)
+	return []reconcile.Request{{NamespacedName: plugNamespacedName}}
+}
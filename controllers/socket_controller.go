@@ -40,10 +40,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	"gitlab.com/bitspur/rock8s/integration-operator/coupler"
 	"gitlab.com/bitspur/rock8s/integration-operator/util"
 )
@@ -62,17 +64,17 @@ type SocketReconciler struct {
 func (r *SocketReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.V(1).Info("Socket Reconcile")
-	socketUtil := util.NewSocketUtil(&r.Client, ctx, &req, &integrationv1beta1.NamespacedName{
+	socketUtil := util.NewSocketUtil(&r.Client, ctx, &req, &integrationv1.NamespacedName{
 		Name:      req.NamespacedName.Name,
 		Namespace: req.NamespacedName.Namespace,
-	})
+	}, r.Recorder)
 	socket, err := socketUtil.Get()
 	if err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
 	if socket.GetDeletionTimestamp() != nil {
-		if controllerutil.ContainsFinalizer(socket, integrationv1beta1.Finalizer) {
+		if controllerutil.ContainsFinalizer(socket, integrationv1.Finalizer) {
 			coupledPlugs := socket.Status.CoupledPlugs
 			if len(coupledPlugs) > 0 {
 				for _, coupledPlug := range coupledPlugs {
@@ -84,10 +86,10 @@ func (r *SocketReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 					return socketUtil.Error(err, socket)
 				}
 				for _, coupledPlug := range coupledPlugs {
-					plugUtil := util.NewPlugUtil(&r.Client, ctx, &req, &integrationv1beta1.NamespacedName{
+					plugUtil := util.NewPlugUtil(&r.Client, ctx, &req, &integrationv1.NamespacedName{
 						Name:      coupledPlug.Name,
 						Namespace: coupledPlug.Namespace,
-					}, socket)
+					}, socket, r.Recorder)
 					plug, err := plugUtil.Get()
 					if err != nil {
 						if errors.IsNotFound(err) {
@@ -107,14 +109,14 @@ func (r *SocketReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			if err := coupler.DeletedSocket(socket, r.Recorder); err != nil {
 				return socketUtil.Error(err, socket)
 			}
-			controllerutil.RemoveFinalizer(socket, integrationv1beta1.Finalizer)
+			controllerutil.RemoveFinalizer(socket, integrationv1.Finalizer)
 			return socketUtil.Update(socket, true)
 		}
 		return ctrl.Result{}, nil
 	}
 
-	if !controllerutil.ContainsFinalizer(socket, integrationv1beta1.Finalizer) {
-		controllerutil.AddFinalizer(socket, integrationv1beta1.Finalizer)
+	if !controllerutil.ContainsFinalizer(socket, integrationv1.Finalizer) {
+		controllerutil.AddFinalizer(socket, integrationv1.Finalizer)
 		return socketUtil.Update(socket, true)
 	}
 
@@ -131,10 +133,10 @@ func (r *SocketReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 
 	setSocketStatus := false
 	for _, coupledPlug := range socket.Status.CoupledPlugs {
-		plugUtil := util.NewPlugUtil(&r.Client, ctx, &req, &integrationv1beta1.NamespacedName{
+		plugUtil := util.NewPlugUtil(&r.Client, ctx, &req, &integrationv1.NamespacedName{
 			Name:      coupledPlug.Name,
 			Namespace: coupledPlug.Namespace,
-		}, socket)
+		}, socket, r.Recorder)
 		if _, err := plugUtil.Get(); err != nil {
 			if errors.IsNotFound(err) {
 				if _, err := socketUtil.RemoveCoupledPlugStatus(coupledPlug.UID, socket); err != nil {
@@ -151,10 +153,10 @@ func (r *SocketReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	}
 
 	for _, coupledPlug := range socket.Status.CoupledPlugs {
-		plugUtil := util.NewPlugUtil(&r.Client, ctx, &req, &integrationv1beta1.NamespacedName{
+		plugUtil := util.NewPlugUtil(&r.Client, ctx, &req, &integrationv1.NamespacedName{
 			Name:      coupledPlug.Name,
 			Namespace: coupledPlug.Namespace,
-		}, socket)
+		}, socket, r.Recorder)
 		plug, err := plugUtil.Get()
 		if err != nil {
 			return socketUtil.Error(err, socket)
@@ -187,9 +189,21 @@ func (r *SocketReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			maxConcurrentReconciles = val
 		}
 	}
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&integrationv1.Plug{},
+		util.PlugSocketField,
+		util.IndexPlugSocket,
+	); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}).
 		WithEventFilter(filterSocketPredicate()).
-		For(&integrationv1beta1.Socket{}).
+		For(&integrationv1.Socket{}).
+		Watches(
+			&source.Channel{Source: util.SocketOperationEvents},
+			&handler.EnqueueRequestForObject{},
+		).
 		Complete(r)
 }
@@ -29,7 +29,7 @@ package coupler
 import (
 	"context"
 
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	"gitlab.com/bitspur/rock8s/integration-operator/util"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/tools/record"
@@ -43,10 +43,11 @@ func Couple(
 	req *ctrl.Request,
 	plugUtil *util.PlugUtil,
 	socketUtil *util.SocketUtil,
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	recorder record.EventRecorder,
 ) (ctrl.Result, error) {
+	approvalUtil := util.NewApprovalUtil(client, ctx)
 	configUtil := util.NewConfigUtil(ctx)
 	if plug == nil {
 		var err error
@@ -69,6 +70,15 @@ func Couple(
 	if socketUtil.CoupledPlugExists(socket.Status.CoupledPlugs, plug.UID) &&
 		plug.Status.CoupledSocket != nil &&
 		plug.Status.CoupledResult != nil {
+		if approvalUtil.Required(socket) {
+			approval, err := approvalUtil.GetApproval(plug, socket)
+			if err != nil {
+				return plugUtil.Error(err, plug)
+			}
+			if approval == nil {
+				return Revoke(client, ctx, req, plugUtil, socketUtil, plug, socket, recorder)
+			}
+		}
 		coupledCondition, err := plugUtil.GetCoupledCondition(plug)
 		if err != nil {
 			return plugUtil.Error(err, plug)
@@ -77,16 +87,27 @@ func Couple(
 			return plugUtil.UpdateCoupledStatus(util.UpdatingInProcess, plug, socket, true)
 		}
 		if plug.Generation > plug.Status.CoupledResult.ObservedGeneration {
-			if err := Update(client, ctx, req, plugUtil, socketUtil, plug, socket, recorder); err != nil {
+			// the changed spec of the plug must still pass the validation of the socket
+			validationUtil := util.NewValidationUtil(client, ctx)
+			if err := validationUtil.Validate(plug, socket); err != nil {
 				return plugUtil.Error(err, plug)
 			}
-			plugConfig, err := configUtil.GetPlugConfig(plug, socket)
-			if err != nil {
+			var plugConfig map[string]string
+			if validationUtil.RulesRequired(socket) {
+				plugConfig, err = configUtil.GetPlugConfig(plug, socket)
+				if err != nil {
+					plugUtil.SetConfigResolvedCondition(plug, err)
+					return plugUtil.Error(err, plug)
+				}
+				if err := validationUtil.ValidateRules(plug, socket, plugConfig); err != nil {
+					return plugUtil.Error(err, plug)
+				}
+			}
+			if err := Update(client, ctx, req, plugUtil, socketUtil, plug, socket, recorder); err != nil {
 				return plugUtil.Error(err, plug)
 			}
-			socketConfig, err := configUtil.GetSocketConfig(plug, socket)
+			plugConfig, socketConfig, err := resolveConfig(configUtil, plugUtil, socketUtil, plug, socket, plugConfig)
 			if err != nil {
-				socketUtil.Error(err, socket)
 				return plugUtil.Error(err, plug)
 			}
 			if _, err := socketUtil.UpdateCoupledStatus(util.SocketCoupled, socket, nil, false); err != nil {
@@ -98,23 +119,44 @@ func Couple(
 		return ctrl.Result{}, nil
 	}
 
-	if err := util.Validate(plug, socket); err != nil {
+	validationUtil := util.NewValidationUtil(client, ctx)
+	if err := validationUtil.Validate(plug, socket); err != nil {
 		return plugUtil.Error(err, plug)
 	}
 
-	plugConfig, err := configUtil.GetPlugConfig(plug, socket)
-	if err != nil {
-		return plugUtil.Error(err, plug)
+	approvedBy := ""
+	if approvalUtil.Required(socket) {
+		approval, err := approvalUtil.GetApproval(plug, socket)
+		if err != nil {
+			return plugUtil.Error(err, plug)
+		}
+		if approval == nil {
+			if socketUtil.CoupledPlugExists(socket.Status.CoupledPlugs, plug.UID) {
+				return Revoke(client, ctx, req, plugUtil, socketUtil, plug, socket, recorder)
+			}
+			return plugUtil.UpdateCoupledStatus(util.AwaitingApproval, plug, nil, false)
+		}
+		approvedBy = approval.Spec.Approver
 	}
-	socketConfig, err := configUtil.GetSocketConfig(plug, socket)
-	if err != nil {
-		socketUtil.Error(err, socket)
-		return plugUtil.Error(err, plug)
+
+	// the rules are evaluated against the config of the plug, which is only resolved
+	// once the cheaper checks passed, since it may call out to the apparatus
+	var plugConfig map[string]string
+	if validationUtil.RulesRequired(socket) {
+		var err error
+		plugConfig, err = configUtil.GetPlugConfig(plug, socket)
+		if err != nil {
+			plugUtil.SetConfigResolvedCondition(plug, err)
+			return plugUtil.Error(err, plug)
+		}
+		if err := validationUtil.ValidateRules(plug, socket, plugConfig); err != nil {
+			return plugUtil.Error(err, plug)
+		}
 	}
 
 	if !socketUtil.CoupledPlugExists(socket.Status.CoupledPlugs, plug.UID) {
 		if plug.Status.CoupledSocket != nil {
-			if _, err := socketUtil.UpdateAppendCoupledPlugStatus(plug, socket, false); err != nil {
+			if _, err := socketUtil.UpdateAppendCoupledPlugStatus(plug, socket, approvedBy, false); err != nil {
 				return plugUtil.Error(err, plug)
 			}
 			return plugUtil.UpdateCoupledStatus(util.CouplingInProcess, plug, socket, true)
@@ -126,22 +168,30 @@ func Couple(
 		if coupledCondition.Reason != string(util.CouplingInProcess) {
 			return plugUtil.UpdateCoupledStatus(util.CouplingInProcess, plug, nil, true)
 		}
-		err = CoupledPlug(plug, socket, plugConfig, socketConfig, recorder)
+		plugConfig, socketConfig, err := resolveConfig(configUtil, plugUtil, socketUtil, plug, socket, plugConfig)
+		if err != nil {
+			return plugUtil.Error(err, plug)
+		}
+		err = CoupledPlug(ctx, plug, socket, plugConfig, socketConfig, recorder)
 		if err != nil {
 			return plugUtil.Error(err, plug)
 		}
-		err = CoupledSocket(plug, socket, plugConfig, socketConfig, recorder)
+		err = CoupledSocket(ctx, plug, socket, plugConfig, socketConfig, recorder)
 		if err != nil {
 			socketUtil.Error(err, socket)
 			return plugUtil.Error(err, plug)
 		}
-		if _, err := socketUtil.UpdateAppendCoupledPlugStatus(plug, socket, false); err != nil {
+		if _, err := socketUtil.UpdateAppendCoupledPlugStatus(plug, socket, approvedBy, false); err != nil {
 			return plugUtil.Error(err, plug)
 		}
 		return plugUtil.UpdateCoupledStatus(util.CouplingInProcess, plug, socket, true)
 	}
 
 	if plug.Status.CoupledResult == nil {
+		plugConfig, socketConfig, err := resolveConfig(configUtil, plugUtil, socketUtil, plug, socket, plugConfig)
+		if err != nil {
+			return plugUtil.Error(err, plug)
+		}
 		if _, err := socketUtil.UpdateCoupledStatus(util.SocketCoupled, socket, nil, false); err != nil {
 			socketUtil.Error(err, socket)
 			return plugUtil.Error(err, plug)
@@ -151,3 +201,31 @@ func Couple(
 
 	return ctrl.Result{}, nil
 }
+
+// resolveConfig resolves the config of the plug, unless it was already resolved, and the
+// config of the socket, reporting the outcome in the ConfigResolved condition of the plug
+func resolveConfig(
+	configUtil *util.ConfigUtil,
+	plugUtil *util.PlugUtil,
+	socketUtil *util.SocketUtil,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	plugConfig map[string]string,
+) (map[string]string, map[string]string, error) {
+	if plugConfig == nil {
+		var err error
+		plugConfig, err = configUtil.GetPlugConfig(plug, socket)
+		if err != nil {
+			plugUtil.SetConfigResolvedCondition(plug, err)
+			return nil, nil, err
+		}
+	}
+	socketConfig, err := configUtil.GetSocketConfig(plug, socket)
+	if err != nil {
+		plugUtil.SetConfigResolvedCondition(plug, err)
+		socketUtil.Error(err, socket)
+		return nil, nil, err
+	}
+	plugUtil.SetConfigResolvedCondition(plug, nil)
+	return plugConfig, socketConfig, nil
+}
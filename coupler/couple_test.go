@@ -0,0 +1,114 @@
+/**
+ * File: /coupler/couple_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 14:03:15
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package coupler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/coupler"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Couple", func() {
+	It("should validate the rules of the socket when a coupled plug is updated", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(integrationv1.AddToScheme(scheme)).To(Succeed())
+		socket := &integrationv1.Socket{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default", UID: "socket"},
+			Spec: integrationv1.SocketSpec{
+				Validation: &integrationv1.SocketSpecValidation{
+					Rules: []integrationv1.ValidationRule{{
+						Rule:    `int(plugConfig.size) <= 100`,
+						Message: "size must be at most 100",
+					}},
+				},
+			},
+			Status: integrationv1.SocketStatus{
+				CoupledPlugs: []*integrationv1.CoupledPlug{{Name: "app", Namespace: "default", UID: "plug"}},
+			},
+		}
+		plug := &integrationv1.Plug{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "plug", Generation: 2},
+			Spec: integrationv1.PlugSpec{
+				Socket: integrationv1.NamespacedName{Name: "postgres"},
+				Config: map[string]string{"size": "1000"},
+			},
+			Status: integrationv1.PlugStatus{
+				CoupledSocket: &integrationv1.CoupledSocket{Name: "postgres", Namespace: "default", UID: "socket"},
+				CoupledResult: &integrationv1.CoupledResultStatus{ObservedGeneration: 1},
+				Conditions: []metav1.Condition{{
+					Type:               string(util.ConditionTypeCoupled),
+					Status:             metav1.ConditionTrue,
+					Reason:             string(util.UpdatingInProcess),
+					ObservedGeneration: 2,
+					LastTransitionTime: metav1.Now(),
+				}},
+			},
+		}
+		namespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+		var fakeClient client.Client = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(namespace, plug.DeepCopy(), socket.DeepCopy()).
+			Build()
+		// the utils are created without a cluster, and the plug and socket need none of it
+		ctx := util.WithRestConfig(context.Background(), &rest.Config{Host: "https://127.0.0.1:1"})
+		req := &ctrl.Request{NamespacedName: types.NamespacedName{Name: "app", Namespace: "default"}}
+		recorder := record.NewFakeRecorder(10)
+		plugUtil := util.NewPlugUtil(&fakeClient, ctx, req, &integrationv1.NamespacedName{
+			Name:      "app",
+			Namespace: "default",
+		}, socket, recorder)
+		socketUtil := util.NewSocketUtil(&fakeClient, ctx, req, &integrationv1.NamespacedName{
+			Name:      "postgres",
+			Namespace: "default",
+		}, recorder)
+		Expect(fakeClient.Get(ctx, req.NamespacedName, plug)).To(Succeed())
+		_, err := coupler.Couple(&fakeClient, ctx, req, plugUtil, socketUtil, plug, socket, recorder)
+		Expect(err).To(MatchError("size must be at most 100"))
+		updatedPlug := &integrationv1.Plug{}
+		Expect(fakeClient.Get(ctx, req.NamespacedName, updatedPlug)).To(Succeed())
+		failedCondition := meta.FindStatusCondition(updatedPlug.Status.Conditions, string(util.ConditionTypeFailed))
+		Expect(failedCondition).NotTo(BeNil())
+		Expect(failedCondition.Message).To(ContainSubstring("size must be at most 100"))
+		Expect(updatedPlug.Status.CoupledResult.ObservedGeneration).To(Equal(int64(1)))
+	})
+})
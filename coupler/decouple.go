@@ -29,7 +29,7 @@ package coupler
 import (
 	"context"
 
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	"gitlab.com/bitspur/rock8s/integration-operator/util"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -42,8 +42,8 @@ func Decouple(
 	req *ctrl.Request,
 	plugUtil *util.PlugUtil,
 	socketUtil *util.SocketUtil,
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	recorder record.EventRecorder,
 ) error {
 	configUtil := util.NewConfigUtil(ctx)
@@ -72,10 +72,10 @@ func Decouple(
 		return err
 	}
 
-	if err := DecoupledPlug(plug, socket, plugConfig, socketConfig, recorder); err != nil {
+	if err := DecoupledPlug(ctx, plug, socket, plugConfig, socketConfig, recorder); err != nil {
 		return err
 	}
-	if err := DecoupledSocket(plug, socket, plugConfig, socketConfig, recorder); err != nil {
+	if err := DecoupledSocket(ctx, plug, socket, plugConfig, socketConfig, recorder); err != nil {
 		socketUtil.Error(err, socket)
 		return err
 	}
@@ -29,13 +29,13 @@ package coupler
 import (
 	"context"
 
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	"gitlab.com/bitspur/rock8s/integration-operator/util"
 	"k8s.io/client-go/tools/record"
 )
 
 func CreatedPlug(
-	plug *integrationv1beta1.Plug,
+	plug *integrationv1.Plug,
 	recorder record.EventRecorder,
 ) error {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -45,7 +45,7 @@ func CreatedPlug(
 }
 
 func DeletedPlug(
-	plug *integrationv1beta1.Plug,
+	plug *integrationv1.Plug,
 	recorder record.EventRecorder,
 ) error {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -55,39 +55,42 @@ func DeletedPlug(
 }
 
 func CoupledPlug(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	ctx context.Context,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig util.Config,
 	socketConfig util.Config,
 	recorder record.EventRecorder,
 ) error {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	eventUtil := util.NewEventUtil(ctx)
 	return eventUtil.PlugCoupled(plug, socket, &plugConfig, &socketConfig, recorder)
 }
 
 func UpdatedPlug(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	ctx context.Context,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig util.Config,
 	socketConfig util.Config,
 	recorder record.EventRecorder,
 ) error {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	eventUtil := util.NewEventUtil(ctx)
 	return eventUtil.PlugUpdated(plug, socket, &plugConfig, &socketConfig, recorder)
 }
 
 func DecoupledPlug(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	ctx context.Context,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig util.Config,
 	socketConfig util.Config,
 	recorder record.EventRecorder,
 ) error {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	eventUtil := util.NewEventUtil(ctx)
 	return eventUtil.PlugDecoupled(plug, socket, &plugConfig, &socketConfig, recorder)
@@ -0,0 +1,57 @@
+/**
+ * File: /coupler/revoke.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 10:38:12
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package coupler
+
+import (
+	"context"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Revoke decouples a plug whose approval to couple to the socket was revoked
+// and puts it back into the AwaitingApproval state
+func Revoke(
+	client *client.Client,
+	ctx context.Context,
+	req *ctrl.Request,
+	plugUtil *util.PlugUtil,
+	socketUtil *util.SocketUtil,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	recorder record.EventRecorder,
+) (ctrl.Result, error) {
+	if err := Decouple(client, ctx, req, plugUtil, socketUtil, plug, socket, recorder); err != nil {
+		return plugUtil.Error(err, plug)
+	}
+	plug.Status.CoupledSocket = nil
+	plug.Status.CoupledResult = nil
+	return plugUtil.UpdateCoupledStatus(util.AwaitingApproval, plug, nil, false)
+}
@@ -29,7 +29,7 @@ package coupler
 import (
 	"context"
 
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	"gitlab.com/bitspur/rock8s/integration-operator/util"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -42,8 +42,8 @@ func Update(
 	req *ctrl.Request,
 	plugUtil *util.PlugUtil,
 	socketUtil *util.SocketUtil,
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	recorder record.EventRecorder,
 ) error {
 	configUtil := util.NewConfigUtil(ctx)
@@ -68,18 +68,21 @@ func Update(
 
 	plugConfig, err := configUtil.GetPlugConfig(plug, socket)
 	if err != nil {
+		plugUtil.SetConfigResolvedCondition(plug, err)
 		return err
 	}
 	socketConfig, err := configUtil.GetSocketConfig(plug, socket)
 	if err != nil {
+		plugUtil.SetConfigResolvedCondition(plug, err)
 		socketUtil.Error(err, socket)
 		return err
 	}
+	plugUtil.SetConfigResolvedCondition(plug, nil)
 
-	if err = UpdatedPlug(plug, socket, plugConfig, socketConfig, recorder); err != nil {
+	if err = UpdatedPlug(ctx, plug, socket, plugConfig, socketConfig, recorder); err != nil {
 		return err
 	}
-	if err = UpdatedSocket(plug, socket, plugConfig, socketConfig, recorder); err != nil {
+	if err = UpdatedSocket(ctx, plug, socket, plugConfig, socketConfig, recorder); err != nil {
 		socketUtil.Error(err, socket)
 		return err
 	}
@@ -0,0 +1,44 @@
+/**
+ * File: /hack/templatecontextschema/main.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 10:41:37
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+// Command templatecontextschema prints the json schema of the template context.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+)
+
+func main() {
+	schema, err := util.TemplateContextSchema()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(string(schema))
+}
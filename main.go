@@ -27,8 +27,12 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"net/http"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -40,9 +44,14 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
 	"gitlab.com/bitspur/rock8s/integration-operator/controllers"
+	"gitlab.com/bitspur/rock8s/integration-operator/render"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -55,6 +64,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(integrationv1beta1.AddToScheme(scheme))
+	utilruntime.Must(integrationv1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -63,13 +73,23 @@ func init() {
 //+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=impersonate
 //+kubebuilder:rbac:groups="",resources=services;pods,verbs=create;delete;get;list;patch;update;watch
 //+kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=create;update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=create;update;delete
+//+kubebuilder:rbac:groups="",resources=pods/log,verbs=get
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		os.Exit(render.Command(os.Args[2:], os.Stdout, os.Stderr))
+	}
 	var enableLeaderElection bool
 	var metricsAddr string
 	var probeAddr string
+	var apparatusCallbackAddr string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&apparatusCallbackAddr, "apparatus-callback-bind-address", ":8082",
+		"The address the apparatus operation callback endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -129,8 +149,47 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "DeferredResource")
 		os.Exit(1)
 	}
+	if config.EnableWebhooks {
+		if err = (&integrationv1.Socket{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Socket")
+			os.Exit(1)
+		}
+		if err = (&integrationv1.Plug{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Plug")
+			os.Exit(1)
+		}
+		if err = (&integrationv1.DeferredResource{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DeferredResource")
+			os.Exit(1)
+		}
+		if err = (&integrationv1.CouplingApproval{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "CouplingApproval")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
+	if apparatusCallbackAddr != "" && apparatusCallbackAddr != "0" {
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			server := &http.Server{
+				Addr:              apparatusCallbackAddr,
+				Handler:           util.NewApparatusCallbackHandler(mgr.GetClient()),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			go func() {
+				<-ctx.Done()
+				server.Shutdown(context.Background())
+			}()
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		})); err != nil {
+			setupLog.Error(err, "unable to set up apparatus callback server")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
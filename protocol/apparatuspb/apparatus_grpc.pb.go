@@ -0,0 +1,189 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: apparatus.proto
+
+package apparatuspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Apparatus_GetConfig_FullMethodName = "/integration.rock8s.apparatus.v1.Apparatus/GetConfig"
+	Apparatus_OnEvent_FullMethodName   = "/integration.rock8s.apparatus.v1.Apparatus/OnEvent"
+	Apparatus_Health_FullMethodName    = "/integration.rock8s.apparatus.v1.Apparatus/Health"
+)
+
+// ApparatusClient is the client API for Apparatus service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ApparatusClient interface {
+	// GetConfig returns the config of the plug or socket
+	GetConfig(ctx context.Context, in *ConfigRequest, opts ...grpc.CallOption) (*ConfigResponse, error)
+	// OnEvent is invoked when the plug or socket is created, coupled, updated, decoupled or deleted
+	OnEvent(ctx context.Context, in *EventRequest, opts ...grpc.CallOption) (*EventResponse, error)
+	// Health reports whether the apparatus is serving and the events it supports
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+}
+
+type apparatusClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewApparatusClient(cc grpc.ClientConnInterface) ApparatusClient {
+	return &apparatusClient{cc}
+}
+
+func (c *apparatusClient) GetConfig(ctx context.Context, in *ConfigRequest, opts ...grpc.CallOption) (*ConfigResponse, error) {
+	out := new(ConfigResponse)
+	err := c.cc.Invoke(ctx, Apparatus_GetConfig_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *apparatusClient) OnEvent(ctx context.Context, in *EventRequest, opts ...grpc.CallOption) (*EventResponse, error) {
+	out := new(EventResponse)
+	err := c.cc.Invoke(ctx, Apparatus_OnEvent_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *apparatusClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, Apparatus_Health_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ApparatusServer is the server API for Apparatus service.
+// All implementations must embed UnimplementedApparatusServer
+// for forward compatibility
+type ApparatusServer interface {
+	// GetConfig returns the config of the plug or socket
+	GetConfig(context.Context, *ConfigRequest) (*ConfigResponse, error)
+	// OnEvent is invoked when the plug or socket is created, coupled, updated, decoupled or deleted
+	OnEvent(context.Context, *EventRequest) (*EventResponse, error)
+	// Health reports whether the apparatus is serving and the events it supports
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	mustEmbedUnimplementedApparatusServer()
+}
+
+// UnimplementedApparatusServer must be embedded to have forward compatible implementations.
+type UnimplementedApparatusServer struct {
+}
+
+func (UnimplementedApparatusServer) GetConfig(context.Context, *ConfigRequest) (*ConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedApparatusServer) OnEvent(context.Context, *EventRequest) (*EventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OnEvent not implemented")
+}
+func (UnimplementedApparatusServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedApparatusServer) mustEmbedUnimplementedApparatusServer() {}
+
+// UnsafeApparatusServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ApparatusServer will
+// result in compilation errors.
+type UnsafeApparatusServer interface {
+	mustEmbedUnimplementedApparatusServer()
+}
+
+func RegisterApparatusServer(s grpc.ServiceRegistrar, srv ApparatusServer) {
+	s.RegisterService(&Apparatus_ServiceDesc, srv)
+}
+
+func _Apparatus_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ApparatusServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Apparatus_GetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ApparatusServer).GetConfig(ctx, req.(*ConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Apparatus_OnEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ApparatusServer).OnEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Apparatus_OnEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ApparatusServer).OnEvent(ctx, req.(*EventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Apparatus_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ApparatusServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Apparatus_Health_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ApparatusServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Apparatus_ServiceDesc is the grpc.ServiceDesc for Apparatus service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Apparatus_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "integration.rock8s.apparatus.v1.Apparatus",
+	HandlerType: (*ApparatusServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetConfig",
+			Handler:    _Apparatus_GetConfig_Handler,
+		},
+		{
+			MethodName: "OnEvent",
+			Handler:    _Apparatus_OnEvent_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _Apparatus_Health_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "apparatus.proto",
+}
@@ -0,0 +1,59 @@
+/**
+ * File: protocol/apparatuspb/codec.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 15:12:40
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package apparatuspb
+
+import (
+	"errors"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// MarshaledCodec sends the bytes a request was already marshaled to instead of marshaling
+// it again, so a signature of the bytes covers exactly what is sent on the wire. Use it
+// for a single call with grpc.ForceCodec.
+type MarshaledCodec struct {
+	Body []byte
+}
+
+// Marshal returns the bytes the request was already marshaled to
+func (c MarshaledCodec) Marshal(v interface{}) ([]byte, error) {
+	return c.Body, nil
+}
+
+// Unmarshal unmarshals the response with protobuf
+func (c MarshaledCodec) Unmarshal(data []byte, v interface{}) error {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return errors.New("response is not a proto message")
+	}
+	return proto.Unmarshal(data, message)
+}
+
+// Name is the content subtype of the codec, which is the default proto codec on the wire
+func (c MarshaledCodec) Name() string {
+	return "proto"
+}
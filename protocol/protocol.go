@@ -0,0 +1,161 @@
+/**
+ * File: /protocol/protocol.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 13:05:52
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+// Package protocol contains the payloads exchanged between the integration operator and an apparatus.
+package protocol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+)
+
+// Version of the apparatus protocol
+type Version int
+
+// UnmarshalJSON accepts the version as a number or a string, because version 1
+// of the protocol sends the version of event requests as a string
+func (v *Version) UnmarshalJSON(data []byte) error {
+	version, err := strconv.Atoi(strings.Trim(string(data), `"`))
+	if err != nil {
+		return fmt.Errorf("invalid protocol version %s", string(data))
+	}
+	*v = Version(version)
+	return nil
+}
+
+const (
+	// Version1 is the original protocol, assumed when an apparatus does not report its capabilities
+	Version1 Version = 1
+	// Version2 adds capabilities, typed payloads and the event name to event requests
+	Version2 Version = 2
+)
+
+// Event the operator sends to an apparatus
+type Event string
+
+const (
+	ConfigEvent    Event = "config"
+	CreatedEvent   Event = "created"
+	CoupledEvent   Event = "coupled"
+	UpdatedEvent   Event = "updated"
+	DecoupledEvent Event = "decoupled"
+	DeletedEvent   Event = "deleted"
+)
+
+// AllEvents are the events of an apparatus that does not report its capabilities
+var AllEvents = []Event{
+	ConfigEvent,
+	CreatedEvent,
+	CoupledEvent,
+	UpdatedEvent,
+	DecoupledEvent,
+	DeletedEvent,
+}
+
+// CapabilitiesPath is requested with GET when the operator first talks to an apparatus
+const CapabilitiesPath = "/capabilities"
+
+// HealthzPath is requested with GET to probe the health of an apparatus
+const HealthzPath = "/healthz"
+
+// Capabilities is the response of an apparatus to a request of its capabilities
+type Capabilities struct {
+	// protocol versions the apparatus supports
+	Versions []Version `json:"versions"`
+
+	// events the apparatus handles, the operator does not send other events
+	Events []Event `json:"events"`
+}
+
+// SupportsVersion reports whether the apparatus supports the protocol version
+func (c *Capabilities) SupportsVersion(version Version) bool {
+	for _, v := range c.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsEvent reports whether the apparatus handles the event
+func (c *Capabilities) SupportsEvent(event Event) bool {
+	for _, e := range c.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigRequest is the body the operator POSTs to /config
+type ConfigRequest struct {
+	Version    Version               `json:"version"`
+	Event      Event                 `json:"event"`
+	Plug       *integrationv1.Plug   `json:"plug,omitempty"`
+	Socket     *integrationv1.Socket `json:"socket,omitempty"`
+	PlugData   map[string]string     `json:"plugData,omitempty"`
+	SocketData map[string]string     `json:"socketData,omitempty"`
+	Vars       map[string]string     `json:"vars,omitempty"`
+}
+
+// ConfigResponse is the config an apparatus responds with
+type ConfigResponse map[string]string
+
+// EventRequest is the body the operator POSTs to /<event>
+type EventRequest struct {
+	Version      Version               `json:"version"`
+	Event        Event                 `json:"event"`
+	Plug         *integrationv1.Plug   `json:"plug,omitempty"`
+	Socket       *integrationv1.Socket `json:"socket,omitempty"`
+	PlugConfig   map[string]string     `json:"plugConfig,omitempty"`
+	SocketConfig map[string]string     `json:"socketConfig,omitempty"`
+	CallbackURL  string                `json:"callbackUrl,omitempty"`
+}
+
+// AcceptedResponse is the body of a 202 response to an event the apparatus processes asynchronously
+type AcceptedResponse struct {
+	OperationID string `json:"operationId"`
+	StatusURL   string `json:"statusUrl,omitempty"`
+}
+
+// OperationStatus of an asynchronous operation
+type OperationStatus string
+
+const (
+	OperationPending   OperationStatus = "pending"
+	OperationSucceeded OperationStatus = "succeeded"
+	OperationFailed    OperationStatus = "failed"
+)
+
+// OperationStatusResponse is the status of an asynchronous operation, returned by the status url
+// of the operation or posted to the callback url of the event
+type OperationStatusResponse struct {
+	Status  OperationStatus `json:"status"`
+	Message string          `json:"message,omitempty"`
+}
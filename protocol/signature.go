@@ -0,0 +1,96 @@
+/**
+ * File: /protocol/signature.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 13:52:18
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package protocol
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader contains the signature of a request to an apparatus
+	SignatureHeader = "X-Integration-Signature"
+	// TimestampHeader contains the unix time a request to an apparatus was signed at
+	TimestampHeader = "X-Integration-Timestamp"
+)
+
+const signaturePrefix = "sha256="
+
+// DefaultSignatureTolerance is how old a signed request may be before it is rejected
+const DefaultSignatureTolerance = time.Minute * 5
+
+// Sign returns the signature of a request signed at the timestamp, which is the hex
+// encoded HMAC-SHA256 of the string <timestamp>.<METHOD> <path>\n<body>, so a signed
+// body cannot be replayed against another endpoint of the apparatus
+func Sign(secret []byte, timestamp time.Time, method string, path string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write([]byte(strings.ToUpper(method)))
+	mac.Write([]byte(" "))
+	mac.Write([]byte(path))
+	mac.Write([]byte("\n"))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a request against its method,
+// path and body
+func Verify(
+	secret []byte,
+	timestampHeader string,
+	signatureHeader string,
+	method string,
+	path string,
+	body []byte,
+	tolerance time.Duration,
+) error {
+	if timestampHeader == "" || signatureHeader == "" {
+		return errors.New("request is not signed")
+	}
+	unix, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return errors.New("invalid signature timestamp")
+	}
+	timestamp := time.Unix(unix, 0)
+	if tolerance <= 0 {
+		tolerance = DefaultSignatureTolerance
+	}
+	if age := time.Since(timestamp); age > tolerance || age < -tolerance {
+		return errors.New("signature timestamp is outside the tolerance")
+	}
+	if !strings.HasPrefix(signatureHeader, signaturePrefix) ||
+		!hmac.Equal([]byte(Sign(secret, timestamp, method, path, body)), []byte(signatureHeader)) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/**
+ * File: /render/apparatus.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 14:20:45
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package render
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
+)
+
+// ApparatusFixtures are the canned responses of the plug and socket apparatus
+type ApparatusFixtures struct {
+	Plug   *ApparatusFixture `json:"plug,omitempty"`
+	Socket *ApparatusFixture `json:"socket,omitempty"`
+}
+
+// ApparatusFixture is the canned response of an apparatus
+type ApparatusFixture struct {
+	// Capabilities the apparatus advertises, all events of version 1 when not set
+	Capabilities *protocol.Capabilities `json:"capabilities,omitempty"`
+
+	// Config is the body of the response to the config request
+	Config map[string]interface{} `json:"config,omitempty"`
+
+	// Status is the status code of the response to the config request, 200 when not set
+	Status int `json:"status,omitempty"`
+}
+
+// newApparatusServer serves the canned responses of the plug apparatus under /plug and of the
+// socket apparatus under /socket
+func newApparatusServer(fixtures *ApparatusFixtures) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(r.URL.Path, "/")
+		owner, path, _ := strings.Cut(path, "/")
+		var fixture *ApparatusFixture
+		if owner == "plug" {
+			fixture = fixtures.Plug
+		} else if owner == "socket" {
+			fixture = fixtures.Socket
+		}
+		if fixture == nil {
+			writeStatus(w, http.StatusNotFound, "", "no canned response for the "+owner+" apparatus")
+			return
+		}
+		switch "/" + path {
+		case protocol.HealthzPath:
+			w.WriteHeader(http.StatusOK)
+		case protocol.CapabilitiesPath:
+			if fixture.Capabilities == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			writeJSON(w, http.StatusOK, fixture.Capabilities)
+		case "/config":
+			config := fixture.Config
+			if config == nil {
+				config = map[string]interface{}{}
+			}
+			status := fixture.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			writeJSON(w, status, config)
+		default:
+			writeJSON(w, http.StatusOK, map[string]interface{}{})
+		}
+	}))
+}
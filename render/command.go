@@ -0,0 +1,315 @@
+/**
+ * File: /render/command.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 14:52:03
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
+)
+
+// stringsFlag is a flag that can be set more than once
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// Command runs the render subcommand with the args following it and returns its exit code,
+// 1 when the coupling has errors and 2 when the input cannot be rendered
+func Command(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var plugPath, socketPath, apparatusPath, event, output string
+	var fixturePaths stringsFlag
+	var verbose bool
+	flags.StringVar(&plugPath, "plug", "", "The file with the plug to render.")
+	flags.StringVar(&socketPath, "socket", "", "The file with the socket to render, the plug file when not set.")
+	flags.Var(&fixturePaths, "fixtures",
+		"A file or directory of resources the plug and socket reference, such as secrets, configmaps and var targets. "+
+			"Can be set more than once.")
+	flags.StringVar(&apparatusPath, "apparatus", "", "The file with the canned responses of the plug and socket apparatus.")
+	flags.StringVar(&event, "event", string(integrationv1.CoupledWhen),
+		"The event to render, one of created, coupled, updated or decoupled.")
+	flags.StringVar(&output, "output", "yaml",
+		"The output format, yaml for the resolved config, result, resources and errors or manifests for the applied "+
+			"resources.")
+	flags.BoolVar(&verbose, "v", false, "Log what the operator does while rendering.")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	logWriter := io.Discard
+	if verbose {
+		logWriter = stderr
+	}
+	ctrl.SetLogger(zap.New(zap.WriteTo(logWriter), zap.UseDevMode(true)))
+	if plugPath == "" {
+		fmt.Fprintln(stderr, "render: -plug is required")
+		return 2
+	}
+	if output != "yaml" && output != "manifests" {
+		fmt.Fprintln(stderr, "render: -output must be yaml or manifests")
+		return 2
+	}
+	input, err := loadInput(plugPath, socketPath, fixturePaths, apparatusPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "render: "+err.Error())
+		return 2
+	}
+	input.Event = integrationv1.When(event)
+	result, err := Render(context.Background(), input)
+	if err != nil {
+		fmt.Fprintln(stderr, "render: "+err.Error())
+		return 2
+	}
+	if output == "manifests" {
+		err = writeManifests(stdout, result)
+	} else {
+		err = writeYAML(stdout, result)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "render: "+err.Error())
+		return 2
+	}
+	if len(result.Errors) > 0 {
+		for _, message := range result.Errors {
+			fmt.Fprintln(stderr, "error: "+message)
+		}
+		return 1
+	}
+	return 0
+}
+
+func loadInput(
+	plugPath string,
+	socketPath string,
+	fixturePaths []string,
+	apparatusPath string,
+) (*Input, error) {
+	if socketPath == "" {
+		socketPath = plugPath
+	}
+	input := &Input{
+		Fixtures: []*unstructured.Unstructured{},
+		Plug:     &integrationv1.Plug{},
+		Socket:   &integrationv1.Socket{},
+	}
+	if err := loadObject(plugPath, "Plug", input.Plug); err != nil {
+		return nil, err
+	}
+	if err := loadObject(socketPath, "Socket", input.Socket); err != nil {
+		return nil, err
+	}
+	for _, fixturePath := range fixturePaths {
+		files, err := fixtureFiles(fixturePath)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			objects, err := loadObjects(file)
+			if err != nil {
+				return nil, err
+			}
+			input.Fixtures = append(input.Fixtures, objects...)
+		}
+	}
+	if apparatusPath != "" {
+		body, err := os.ReadFile(apparatusPath)
+		if err != nil {
+			return nil, err
+		}
+		input.Apparatus = &ApparatusFixtures{}
+		if err := yaml.UnmarshalStrict(body, input.Apparatus); err != nil {
+			return nil, fmt.Errorf("%s: %w", apparatusPath, err)
+		}
+	}
+	return input, nil
+}
+
+// loadObject decodes the first resource of the kind in the file into the object, converting
+// v1beta1 resources the way the conversion webhook does
+func loadObject(path string, kind string, obj conversion.Hub) error {
+	objects, err := loadObjects(path)
+	if err != nil {
+		return err
+	}
+	for _, item := range objects {
+		if item.GetKind() != kind || item.GroupVersionKind().Group != integrationv1.GroupVersion.Group {
+			continue
+		}
+		body, err := item.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		switch item.GroupVersionKind().Version {
+		case integrationv1.GroupVersion.Version:
+			return json.Unmarshal(body, obj)
+		case integrationv1beta1.GroupVersion.Version:
+			spoke := newSpoke(kind)
+			if spoke == nil {
+				break
+			}
+			if err := json.Unmarshal(body, spoke); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if err := spoke.ConvertTo(obj); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			return nil
+		}
+		return fmt.Errorf(
+			"%s: %s must be %s or %s",
+			path,
+			kind,
+			integrationv1.GroupVersion.String(),
+			integrationv1beta1.GroupVersion.String(),
+		)
+	}
+	return fmt.Errorf("%s: no %s found", path, kind)
+}
+
+// newSpoke creates the v1beta1 object of the kind
+func newSpoke(kind string) conversion.Convertible {
+	switch kind {
+	case "Plug":
+		return &integrationv1beta1.Plug{}
+	case "Socket":
+		return &integrationv1beta1.Socket{}
+	}
+	return nil
+}
+
+// loadObjects decodes the resources of the yaml or json documents in the file, expanding lists
+func loadObjects(path string) ([]*unstructured.Unstructured, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	objects := []*unstructured.Unstructured{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(body), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+			continue
+		}
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("%s: resources must have a kind and a name", path)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// fixtureFiles gets the yaml and json files of the fixture path
+func fixtureFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	files := []string{}
+	if err := filepath.WalkDir(path, func(file string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch filepath.Ext(file) {
+		case ".yaml", ".yml", ".json":
+			if !entry.IsDir() {
+				files = append(files, file)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func writeYAML(w io.Writer, output *Output) error {
+	body, err := yaml.Marshal(output)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+// writeManifests writes the applied resources as a multi document stream
+func writeManifests(w io.Writer, output *Output) error {
+	for _, action := range output.Resources {
+		if action.Do != integrationv1.ApplyDo {
+			continue
+		}
+		body, err := yaml.Marshal(action.Manifest)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append([]byte("---\n"), body...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
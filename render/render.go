@@ -0,0 +1,242 @@
+/**
+ * File: /render/render.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 14:31:27
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package render
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Input is the plug, socket and fixtures a render couples
+type Input struct {
+	// Apparatus are the canned responses of the plug and socket apparatus
+	Apparatus *ApparatusFixtures
+
+	// Event is the event rendered, coupled when not set
+	Event integrationv1.When
+
+	// Fixtures are the resources the plug and socket reference, such as secrets, configmaps
+	// and var targets
+	Fixtures []*unstructured.Unstructured
+
+	Plug *integrationv1.Plug
+
+	Socket *integrationv1.Socket
+}
+
+// Output is what the operator would have resolved and changed coupling the plug and socket
+type Output struct {
+	// Errors the operator would have set on the plug or socket
+	Errors []string `json:"errors,omitempty"`
+
+	PlugConfig util.Config `json:"plugConfig,omitempty"`
+
+	// Resources the operator would have applied or deleted, in order
+	Resources []*Action `json:"resources"`
+
+	Result *integrationv1.CoupledResult `json:"result,omitempty"`
+
+	SocketConfig util.Config `json:"socketConfig,omitempty"`
+}
+
+func (o *Output) addError(from string, err error) {
+	o.Errors = append(o.Errors, from+": "+err.Error())
+}
+
+// Render runs the config, validation, resource and result logic of the operator for the event
+// against an in memory api server holding the fixtures. Errors of the coupling are reported in
+// the output, the returned error is only set when the input cannot be rendered.
+func Render(ctx context.Context, input *Input) (*Output, error) {
+	if input.Plug == nil || input.Socket == nil {
+		return nil, errors.New("a plug and a socket are required")
+	}
+	event := input.Event
+	if event == "" {
+		event = integrationv1.CoupledWhen
+	}
+	if event != integrationv1.CreatedWhen &&
+		event != integrationv1.CoupledWhen &&
+		event != integrationv1.UpdatedWhen &&
+		event != integrationv1.DecoupledWhen {
+		return nil, fmt.Errorf("event %s cannot be rendered", event)
+	}
+	plug := input.Plug.DeepCopy()
+	socket := input.Socket.DeepCopy()
+	plug.SetGroupVersionKind(integrationv1.GroupVersion.WithKind("Plug"))
+	socket.SetGroupVersionKind(integrationv1.GroupVersion.WithKind("Socket"))
+	defaultObject(plug, "plug")
+	defaultObject(socket, "socket")
+	apparatus := input.Apparatus
+	if apparatus == nil {
+		apparatus = &ApparatusFixtures{}
+	}
+	if plug.Spec.Apparatus != nil && apparatus.Plug == nil {
+		return nil, errors.New("plug " + plug.Name + " has an apparatus without a canned response")
+	}
+	if socket.Spec.Apparatus != nil && apparatus.Socket == nil {
+		return nil, errors.New("socket " + socket.Name + " has an apparatus without a canned response")
+	}
+
+	server := newFakeServer()
+	defer server.close()
+	for _, fixture := range input.Fixtures {
+		server.add(fixture.DeepCopy())
+	}
+	for _, obj := range []runtime.Object{plug, socket} {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		server.add(&unstructured.Unstructured{Object: u})
+	}
+	server.ensureNamespace(plug.Namespace)
+	server.ensureNamespace(socket.Namespace)
+	apparatusServer := newApparatusServer(apparatus)
+	defer apparatusServer.Close()
+
+	// canned responses are served over http whatever the protocol of the apparatus
+	if plug.Spec.Apparatus != nil {
+		plug.Spec.Apparatus.Protocol = integrationv1.HTTPProtocol
+	}
+	if socket.Spec.Apparatus != nil {
+		socket.Spec.Apparatus.Protocol = integrationv1.HTTPProtocol
+	}
+
+	restConfig := &rest.Config{Host: server.server.URL}
+	ctx = util.WithRestConfig(ctx, restConfig)
+	ctx = util.WithApparatusEndpoints(ctx, apparatusServer.URL+"/plug", apparatusServer.URL+"/socket")
+	ctx = util.WithTemplatePass(ctx)
+	output := &Output{Resources: []*Action{}}
+	resourceUtil := util.NewResourceUtil(ctx)
+	collect := func(from string, err error) {
+		if err != nil {
+			output.addError(from, err)
+		}
+		output.Resources = append(output.Resources, server.drain(from)...)
+	}
+	if event == integrationv1.CreatedWhen {
+		collect("plug.resources", resourceUtil.PlugCreated(plug))
+		collect("socket.resources", resourceUtil.SocketCreated(socket))
+		return output, nil
+	}
+
+	var validationUtil *util.ValidationUtil
+	if event == integrationv1.CoupledWhen {
+		validationClient, err := client.New(restConfig, client.Options{})
+		if err != nil {
+			return nil, err
+		}
+		validationUtil = util.NewValidationUtil(&validationClient, ctx)
+		if err := validationUtil.Validate(plug, socket); err != nil {
+			output.addError("socket.validation", err)
+			return output, nil
+		}
+	}
+
+	configUtil := util.NewConfigUtil(ctx)
+	plugConfig, err := configUtil.GetPlugConfig(plug, socket)
+	if err != nil {
+		output.addError("plug.config", err)
+		return output, nil
+	}
+	output.PlugConfig = plugConfig
+	socketConfig, err := configUtil.GetSocketConfig(plug, socket)
+	if err != nil {
+		output.addError("socket.config", err)
+		return output, nil
+	}
+	output.SocketConfig = socketConfig
+	if event == integrationv1.CoupledWhen {
+		if err := validationUtil.ValidateRules(plug, socket, plugConfig); err != nil {
+			output.addError("socket.validation", err)
+			return output, nil
+		}
+	}
+
+	plugConfigValue := util.Config(plugConfig)
+	socketConfigValue := util.Config(socketConfig)
+	switch event {
+	case integrationv1.CoupledWhen:
+		collect("plug.resources", resourceUtil.PlugCoupled(plug, socket, &plugConfigValue, &socketConfigValue))
+		collect("socket.resources", resourceUtil.SocketCoupled(plug, socket, &plugConfigValue, &socketConfigValue))
+	case integrationv1.UpdatedWhen:
+		collect("plug.resources", resourceUtil.PlugUpdated(plug, socket, &plugConfigValue, &socketConfigValue))
+		collect("socket.resources", resourceUtil.SocketUpdated(plug, socket, &plugConfigValue, &socketConfigValue))
+	case integrationv1.DecoupledWhen:
+		collect("plug.resources", resourceUtil.PlugDecoupled(plug, socket, &plugConfigValue, &socketConfigValue))
+		collect("socket.resources", resourceUtil.SocketDecoupled(plug, socket, &plugConfigValue, &socketConfigValue))
+		return output, nil
+	}
+
+	resultUtil := util.NewResultUtil(ctx)
+	coupledResult, err := resultUtil.GetResult(plug, socket, plugConfigValue, socketConfigValue)
+	if err != nil {
+		output.addError("result", err)
+		return output, nil
+	}
+	output.Result = &coupledResult
+	collect("socket.resultResources", resultUtil.SocketTemplateResultResources(
+		plug,
+		socket,
+		plugConfigValue,
+		socketConfigValue,
+		coupledResult.Plug,
+		coupledResult.Socket,
+	))
+	collect("plug.resultResources", resultUtil.PlugTemplateResultResources(
+		plug,
+		socket,
+		plugConfigValue,
+		socketConfigValue,
+		coupledResult.Plug,
+		coupledResult.Socket,
+	))
+	return output, nil
+}
+
+// defaultObject sets the metadata the api server would have set on the plug or socket
+func defaultObject(obj metav1.Object, kind string) {
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace("default")
+	}
+	if obj.GetUID() == "" {
+		obj.SetUID(types.UID("render-" + kind + "-" + obj.GetName()))
+	}
+	if obj.GetGeneration() == 0 {
+		obj.SetGeneration(1)
+	}
+}
@@ -0,0 +1,322 @@
+/**
+ * File: /render/render_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 15:12:58
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package render_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/render"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Render", func() {
+	var plug *integrationv1.Plug
+	var socket *integrationv1.Socket
+	var fixtures []*unstructured.Unstructured
+
+	BeforeEach(func() {
+		plug = &integrationv1.Plug{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec: integrationv1.PlugSpec{
+				Socket:         integrationv1.NamespacedName{Name: "postgres"},
+				Config:         map[string]string{"database": "app"},
+				ConfigTemplate: map[string]string{"user": "{% .plugData.user %}"},
+				DataSecretName: "app-data",
+				ResultResources: []*integrationv1.ResourceAction{{
+					Do: integrationv1.ApplyDo,
+					StringTemplate: `apiVersion: v1
+kind: Secret
+metadata:
+  name: app-db
+stringData:
+  url: postgres://{% .plugConfig.user %}@{% .socketResult.host %}/{% .plugConfig.database %}`,
+				}},
+			},
+		}
+		socket = &integrationv1.Socket{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default"},
+			Spec: integrationv1.SocketSpec{
+				Vars: []*integrationv1.Var{{
+					Name:     "port",
+					ObjRef:   integrationv1.Target{APIVersion: "v1", Gvk: integrationv1.Gvk{Kind: "Service"}, Name: "postgres"},
+					FieldRef: integrationv1.FieldSelector{FieldPath: "spec.ports.0.port"},
+				}},
+				ResultTemplate: map[string]string{
+					"host": "{% .socket.metadata.name %}.{% .socket.metadata.namespace %}.svc:{% .resultVars.port %}",
+				},
+				Resources: []*integrationv1.Resource{{
+					When: []integrationv1.When{integrationv1.CoupledWhen},
+					ResourceAction: integrationv1.ResourceAction{
+						Do: integrationv1.ApplyDo,
+						StringTemplate: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {% .plugConfig.database %}-database
+data:
+  port: "{% .vars.port %}"`,
+					},
+				}},
+			},
+		}
+		socket.Spec.ResultVars = socket.Spec.Vars
+		fixtures = []*unstructured.Unstructured{
+			{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata":   map[string]interface{}{"name": "app-data", "namespace": "default"},
+				"stringData": map[string]interface{}{"user": "alice"},
+			}},
+			{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata":   map[string]interface{}{"name": "postgres", "namespace": "default"},
+				"spec": map[string]interface{}{
+					"ports": []interface{}{map[string]interface{}{"port": int64(5432)}},
+				},
+			}},
+		}
+	})
+
+	It("should render the config, result and resources of the coupling from the fixtures", func() {
+		output, err := render.Render(context.Background(), &render.Input{
+			Fixtures: fixtures,
+			Plug:     plug,
+			Socket:   socket,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.Errors).To(BeEmpty())
+		Expect(output.PlugConfig).To(Equal(util.Config{"database": "app", "user": "alice"}))
+		Expect(output.Result.Socket).To(Equal(map[string]string{"host": "postgres.default.svc:5432"}))
+		Expect(output.Resources).To(HaveLen(2))
+		Expect(output.Resources[0].From).To(Equal("socket.resources"))
+		Expect(output.Resources[0].Do).To(Equal(integrationv1.ApplyDo))
+		Expect(output.Resources[0].Manifest).To(Equal(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "app-database", "namespace": "default"},
+			"data":       map[string]interface{}{"port": "5432"},
+		}))
+		Expect(output.Resources[1].From).To(Equal("plug.resultResources"))
+		Expect(output.Resources[1].Manifest["stringData"]).To(Equal(map[string]interface{}{
+			"url": "postgres://alice@postgres.default.svc:5432/app",
+		}))
+	})
+
+	It("should render concurrently without changing the kubeconfig of the process", func() {
+		kubeconfig, hasKubeconfig := os.LookupEnv("KUBECONFIG")
+		users := []string{"alice", "bob", "carol", "dave"}
+		outputs := make([]*render.Output, len(users))
+		var wg sync.WaitGroup
+		for i, user := range users {
+			i := i
+			userFixtures := []*unstructured.Unstructured{fixtures[0].DeepCopy(), fixtures[1]}
+			userFixtures[0].Object["stringData"] = map[string]interface{}{"user": user}
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				output, err := render.Render(context.Background(), &render.Input{
+					Fixtures: userFixtures,
+					Plug:     plug,
+					Socket:   socket,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				outputs[i] = output
+			}()
+		}
+		wg.Wait()
+		for i, user := range users {
+			Expect(outputs[i].Errors).To(BeEmpty())
+			Expect(outputs[i].PlugConfig).To(HaveKeyWithValue("user", user))
+		}
+		currentKubeconfig, hasCurrentKubeconfig := os.LookupEnv("KUBECONFIG")
+		Expect(hasCurrentKubeconfig).To(Equal(hasKubeconfig))
+		Expect(currentKubeconfig).To(Equal(kubeconfig))
+	})
+
+	It("should report template errors and render the other resources", func() {
+		socket.Spec.Resources[0].StringTemplate = `{% .plugConfig.database | missing %}`
+		output, err := render.Render(context.Background(), &render.Input{
+			Fixtures: fixtures,
+			Plug:     plug,
+			Socket:   socket,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.Errors).To(ConsistOf(ContainSubstring(`socket.resources: resource 0: line 1`)))
+		Expect(output.Resources).To(HaveLen(1))
+		Expect(output.Resources[0].From).To(Equal("plug.resultResources"))
+	})
+
+	It("should use the canned responses of the apparatus", func() {
+		plug.Spec.Apparatus = &integrationv1.SpecApparatus{Endpoint: "http://app-apparatus"}
+		_, err := render.Render(context.Background(), &render.Input{Fixtures: fixtures, Plug: plug, Socket: socket})
+		Expect(err).To(MatchError(ContainSubstring("has an apparatus without a canned response")))
+		output, err := render.Render(context.Background(), &render.Input{
+			Apparatus: &render.ApparatusFixtures{
+				Plug: &render.ApparatusFixture{Config: map[string]interface{}{"password": "secret"}},
+			},
+			Fixtures: fixtures,
+			Plug:     plug,
+			Socket:   socket,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.Errors).To(BeEmpty())
+		Expect(output.PlugConfig).To(HaveKeyWithValue("password", "secret"))
+	})
+
+	It("should report validation errors of the socket", func() {
+		socket.Spec.Validation = &integrationv1.SocketSpecValidation{NamespaceWhitelist: []string{"^production$"}}
+		output, err := render.Render(context.Background(), &render.Input{Fixtures: fixtures, Plug: plug, Socket: socket})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.Errors).To(ConsistOf("socket.validation: namespace default is not whitelisted"))
+		Expect(output.Resources).To(BeEmpty())
+	})
+
+	It("should render the resources deleted when decoupled", func() {
+		socket.Spec.Resources = append(socket.Spec.Resources, &integrationv1.Resource{
+			When: []integrationv1.When{integrationv1.DecoupledWhen},
+			ResourceAction: integrationv1.ResourceAction{
+				Do:             integrationv1.DeleteDo,
+				StringTemplate: "apiVersion: v1\nkind: Service\nmetadata:\n  name: postgres",
+			},
+		})
+		output, err := render.Render(context.Background(), &render.Input{
+			Event:    integrationv1.DecoupledWhen,
+			Fixtures: fixtures,
+			Plug:     plug,
+			Socket:   socket,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.Errors).To(BeEmpty())
+		Expect(output.Result).To(BeNil())
+		Expect(output.Resources).To(HaveLen(1))
+		Expect(output.Resources[0].Do).To(Equal(integrationv1.DeleteDo))
+		Expect(output.Resources[0].Manifest["kind"]).To(Equal("Service"))
+	})
+
+	It("should write the applied manifests of the files and exit with 1 on errors", func() {
+		dir := GinkgoT().TempDir()
+		integration := `apiVersion: integration.rock8s.com/v1
+kind: Socket
+metadata:
+  name: postgres
+spec:
+  resources:
+    - when: [coupled]
+      do: apply
+      template:
+        apiVersion: v1
+        kind: ConfigMap
+        metadata:
+          name: '{% .plugConfig.database %}-database'
+---
+apiVersion: integration.rock8s.com/v1
+kind: Plug
+metadata:
+  name: app
+spec:
+  socket:
+    name: postgres
+  config:
+    database: app
+`
+		integrationPath := filepath.Join(dir, "integration.yaml")
+		Expect(os.WriteFile(integrationPath, []byte(integration), 0600)).To(Succeed())
+		var stdout, stderr bytes.Buffer
+		Expect(render.Command([]string{"-plug", integrationPath, "-output", "manifests"}, &stdout, &stderr)).To(Equal(0))
+		Expect(stdout.String()).To(Equal(`---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-database
+  namespace: default
+`))
+		stdout.Reset()
+		Expect(render.Command([]string{"-plug", integrationPath, "-event", "created"}, &stdout, &stderr)).To(Equal(0))
+		Expect(stdout.String()).To(Equal("resources: []\n"))
+		fixturesPath := filepath.Join(dir, "fixtures")
+		Expect(os.Mkdir(fixturesPath, 0700)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(fixturesPath, "settings.yaml"), []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: default
+data:
+  database: settings
+`), 0600)).To(Succeed())
+		Expect(os.WriteFile(integrationPath, []byte(integration+"  configConfigMapName: settings\n"), 0600)).To(Succeed())
+		stdout.Reset()
+		Expect(render.Command([]string{"-plug", integrationPath, "-fixtures", fixturesPath}, &stdout, &stderr)).To(Equal(0))
+		Expect(stdout.String()).To(ContainSubstring("name: settings-database"))
+		Expect(os.WriteFile(integrationPath, []byte(integration+"  configConfigMapName: missing\n"), 0600)).To(Succeed())
+		stdout.Reset()
+		Expect(render.Command([]string{"-plug", integrationPath, "-fixtures", fixturesPath}, &stdout, &stderr)).To(Equal(1))
+		Expect(stdout.String()).To(ContainSubstring(`plug.config: configmaps "missing" not found`))
+		Expect(stderr.String()).To(ContainSubstring(`error: plug.config: configmaps "missing" not found`))
+	})
+
+	It("should convert v1beta1 plugs and sockets", func() {
+		dir := GinkgoT().TempDir()
+		integrationPath := filepath.Join(dir, "integration.yaml")
+		Expect(os.WriteFile(integrationPath, []byte(`apiVersion: integration.rock8s.com/v1beta1
+kind: Socket
+metadata:
+  name: postgres
+spec:
+  resources:
+    - when: [coupled]
+      do: apply
+      stringTemplate: |
+        apiVersion: v1
+        kind: ConfigMap
+        metadata:
+          name: {% .plugConfig.database %}-database
+---
+apiVersion: integration.rock8s.com/v1beta1
+kind: Plug
+metadata:
+  name: app
+spec:
+  socket:
+    name: postgres
+  config:
+    database: app
+`), 0600)).To(Succeed())
+		var stdout, stderr bytes.Buffer
+		Expect(render.Command([]string{"-plug", integrationPath, "-output", "manifests"}, &stdout, &stderr)).To(Equal(0))
+		Expect(stdout.String()).To(ContainSubstring("name: app-database"))
+	})
+})
@@ -0,0 +1,572 @@
+/**
+ * File: /render/server.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 14:02:11
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package render
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	apidiscovery "k8s.io/api/apidiscovery/v2beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+// clusterScopedKinds are the kinds of the built in api groups that are not namespaced
+var clusterScopedKinds = map[string]bool{
+	"APIService":                       true,
+	"CertificateSigningRequest":        true,
+	"ClusterRole":                      true,
+	"ClusterRoleBinding":               true,
+	"ComponentStatus":                  true,
+	"CSIDriver":                        true,
+	"CSINode":                          true,
+	"CustomResourceDefinition":         true,
+	"FlowSchema":                       true,
+	"IngressClass":                     true,
+	"MutatingWebhookConfiguration":     true,
+	"Namespace":                        true,
+	"Node":                             true,
+	"PersistentVolume":                 true,
+	"PriorityClass":                    true,
+	"PriorityLevelConfiguration":       true,
+	"RuntimeClass":                     true,
+	"SelfSubjectAccessReview":          true,
+	"SelfSubjectRulesReview":           true,
+	"StorageClass":                     true,
+	"SubjectAccessReview":              true,
+	"TokenReview":                      true,
+	"ValidatingAdmissionPolicy":        true,
+	"ValidatingAdmissionPolicyBinding": true,
+	"ValidatingWebhookConfiguration":   true,
+	"VolumeAttachment":                 true,
+}
+
+var secretsResource = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// Action is a change the operator would have made to the cluster
+type Action struct {
+	Do       integrationv1.Do       `json:"do"`
+	From     string                 `json:"from"`
+	Manifest map[string]interface{} `json:"manifest"`
+}
+
+// fakeServer is an in memory api server that serves the fixtures of a render and records the
+// resources the operator applies and deletes instead of changing a cluster
+type fakeServer struct {
+	actions         []*Action
+	kinds           map[schema.GroupVersionKind]schema.GroupVersionResource
+	mutex           sync.Mutex
+	namespaced      map[schema.GroupVersionResource]bool
+	objects         map[string]*unstructured.Unstructured
+	resourceVersion int
+	server          *httptest.Server
+}
+
+func newFakeServer() *fakeServer {
+	s := &fakeServer{
+		actions:    []*Action{},
+		kinds:      map[schema.GroupVersionKind]schema.GroupVersionResource{},
+		namespaced: map[schema.GroupVersionResource]bool{},
+		objects:    map[string]*unstructured.Unstructured{},
+	}
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(integrationv1beta1.AddToScheme(scheme))
+	utilruntime.Must(integrationv1.AddToScheme(scheme))
+	for gvk := range scheme.AllKnownTypes() {
+		if gvk.Version == runtime.APIVersionInternal ||
+			strings.HasSuffix(gvk.Kind, "List") ||
+			strings.HasSuffix(gvk.Kind, "Options") ||
+			gvk.Kind == "WatchEvent" ||
+			gvk.Kind == "Status" {
+			continue
+		}
+		plural, _ := meta.UnsafeGuessKindToResource(gvk)
+		s.register(gvk, plural, !clusterScopedKinds[gvk.Kind])
+	}
+	s.server = httptest.NewServer(s)
+	return s
+}
+
+func (s *fakeServer) close() {
+	s.server.Close()
+}
+
+func (s *fakeServer) register(
+	gvk schema.GroupVersionKind,
+	gvr schema.GroupVersionResource,
+	namespaced bool,
+) {
+	s.kinds[gvk] = gvr
+	s.namespaced[gvr] = namespaced
+}
+
+// add stores the fixture, registering the kinds of custom resource definitions and guessing the
+// resource of kinds the server does not know
+func (s *fakeServer) add(obj *unstructured.Unstructured) {
+	gvk := obj.GroupVersionKind()
+	if gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition" {
+		s.registerDefinition(obj)
+	}
+	gvr, ok := s.kinds[gvk]
+	if !ok {
+		gvr, _ = meta.UnsafeGuessKindToResource(gvk)
+		s.register(gvk, gvr, obj.GetNamespace() != "")
+	}
+	if !s.namespaced[gvr] {
+		obj.SetNamespace("")
+	}
+	s.store(gvr, obj)
+}
+
+func (s *fakeServer) registerDefinition(crd *unstructured.Unstructured) {
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+	scope, _, _ := unstructured.NestedString(crd.Object, "spec", "scope")
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, item := range versions {
+		v, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := v["name"].(string)
+		s.register(
+			schema.GroupVersionKind{Group: group, Version: name, Kind: kind},
+			schema.GroupVersionResource{Group: group, Version: name, Resource: plural},
+			scope != "Cluster",
+		)
+	}
+}
+
+// ensureNamespace adds the namespace when the fixtures do not include it
+func (s *fakeServer) ensureNamespace(name string) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	if _, ok := s.objects[objectKey(gvr, "", name)]; ok {
+		return
+	}
+	namespace := &unstructured.Unstructured{}
+	namespace.SetAPIVersion("v1")
+	namespace.SetKind("Namespace")
+	namespace.SetName(name)
+	namespace.SetLabels(map[string]string{"kubernetes.io/metadata.name": name})
+	s.store(gvr, namespace)
+}
+
+func (s *fakeServer) store(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) {
+	if gvr == secretsResource {
+		convertStringData(obj)
+	}
+	s.resourceVersion++
+	if obj.GetUID() == "" {
+		obj.SetUID(types.UID("render-" + strconv.Itoa(s.resourceVersion)))
+	}
+	if creationTimestamp := obj.GetCreationTimestamp(); creationTimestamp.IsZero() {
+		obj.SetCreationTimestamp(metav1.NewTime(time.Now()))
+	}
+	obj.SetResourceVersion(strconv.Itoa(s.resourceVersion))
+	s.objects[objectKey(gvr, obj.GetNamespace(), obj.GetName())] = obj
+}
+
+// drain removes the recorded actions, marking them with the part of the spec they came from
+func (s *fakeServer) drain(from string) []*Action {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	actions := s.actions
+	s.actions = []*Action{}
+	for _, action := range actions {
+		action.From = from
+	}
+	return actions
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	aggregated := strings.Contains(r.Header.Get("Accept"), discovery.AcceptV2Beta1)
+	switch {
+	case len(segments) == 1 && (segments[0] == "api" || segments[0] == "apis") && aggregated:
+		w.Header().Set("Content-Type", discovery.AcceptV2Beta1)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(s.aggregatedDiscovery(segments[0] == "api"))
+	case len(segments) == 1 && segments[0] == "api":
+		writeJSON(w, http.StatusOK, metav1.APIVersions{
+			TypeMeta: metav1.TypeMeta{Kind: "APIVersions"},
+			Versions: []string{"v1"},
+		})
+	case len(segments) == 1 && segments[0] == "apis":
+		writeJSON(w, http.StatusOK, s.groups())
+	case len(segments) == 2 && segments[0] == "api":
+		writeJSON(w, http.StatusOK, s.resources(schema.GroupVersion{Version: segments[1]}))
+	case len(segments) == 3 && segments[0] == "apis":
+		writeJSON(w, http.StatusOK, s.resources(schema.GroupVersion{Group: segments[1], Version: segments[2]}))
+	case len(segments) > 2 && segments[0] == "api":
+		s.serveResource(w, r, schema.GroupVersion{Version: segments[1]}, segments[2:])
+	case len(segments) > 3 && segments[0] == "apis":
+		s.serveResource(w, r, schema.GroupVersion{Group: segments[1], Version: segments[2]}, segments[3:])
+	default:
+		writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound, "the server could not find the requested resource")
+	}
+}
+
+// groupVersions gets the versions of each group, newest first
+func (s *fakeServer) groupVersions() map[string][]string {
+	versions := map[string][]string{}
+	for gvr := range s.namespaced {
+		if !containsString(versions[gvr.Group], gvr.Version) {
+			versions[gvr.Group] = append(versions[gvr.Group], gvr.Version)
+		}
+	}
+	for _, groupVersions := range versions {
+		sort.Slice(groupVersions, func(i, j int) bool {
+			return version.CompareKubeAwareVersionStrings(groupVersions[i], groupVersions[j]) > 0
+		})
+	}
+	return versions
+}
+
+func (s *fakeServer) groups() *metav1.APIGroupList {
+	groupList := &metav1.APIGroupList{
+		TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"},
+		Groups:   []metav1.APIGroup{},
+	}
+	for group, groupVersions := range s.groupVersions() {
+		if group == "" {
+			continue
+		}
+		apiGroup := metav1.APIGroup{Name: group}
+		for _, groupVersion := range groupVersions {
+			apiGroup.Versions = append(apiGroup.Versions, metav1.GroupVersionForDiscovery{
+				GroupVersion: group + "/" + groupVersion,
+				Version:      groupVersion,
+			})
+		}
+		apiGroup.PreferredVersion = apiGroup.Versions[0]
+		groupList.Groups = append(groupList.Groups, apiGroup)
+	}
+	sort.Slice(groupList.Groups, func(i, j int) bool {
+		return groupList.Groups[i].Name < groupList.Groups[j].Name
+	})
+	return groupList
+}
+
+// aggregatedDiscovery gets the groups with their resources in one response, so the discovery
+// of every action does not request each group version
+func (s *fakeServer) aggregatedDiscovery(core bool) *apidiscovery.APIGroupDiscoveryList {
+	discoveryList := &apidiscovery.APIGroupDiscoveryList{
+		TypeMeta: metav1.TypeMeta{Kind: "APIGroupDiscoveryList", APIVersion: apidiscovery.SchemeGroupVersion.String()},
+		Items:    []apidiscovery.APIGroupDiscovery{},
+	}
+	for group, groupVersions := range s.groupVersions() {
+		if (group == "") != core {
+			continue
+		}
+		groupDiscovery := apidiscovery.APIGroupDiscovery{ObjectMeta: metav1.ObjectMeta{Name: group}}
+		for _, groupVersion := range groupVersions {
+			versionDiscovery := apidiscovery.APIVersionDiscovery{Version: groupVersion}
+			gv := schema.GroupVersion{Group: group, Version: groupVersion}
+			for _, resource := range s.resources(gv).APIResources {
+				scope := apidiscovery.ScopeCluster
+				if resource.Namespaced {
+					scope = apidiscovery.ScopeNamespace
+				}
+				versionDiscovery.Resources = append(versionDiscovery.Resources, apidiscovery.APIResourceDiscovery{
+					Resource:     resource.Name,
+					ResponseKind: &metav1.GroupVersionKind{Group: group, Version: groupVersion, Kind: resource.Kind},
+					Scope:        scope,
+					Verbs:        resource.Verbs,
+				})
+			}
+			groupDiscovery.Versions = append(groupDiscovery.Versions, versionDiscovery)
+		}
+		discoveryList.Items = append(discoveryList.Items, groupDiscovery)
+	}
+	sort.Slice(discoveryList.Items, func(i, j int) bool {
+		return discoveryList.Items[i].Name < discoveryList.Items[j].Name
+	})
+	return discoveryList
+}
+
+func (s *fakeServer) resources(gv schema.GroupVersion) *metav1.APIResourceList {
+	resourceList := &metav1.APIResourceList{
+		TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+		GroupVersion: gv.String(),
+		APIResources: []metav1.APIResource{},
+	}
+	for gvk, gvr := range s.kinds {
+		if gvk.GroupVersion() != gv {
+			continue
+		}
+		resourceList.APIResources = append(resourceList.APIResources, metav1.APIResource{
+			Name:       gvr.Resource,
+			Kind:       gvk.Kind,
+			Namespaced: s.namespaced[gvr],
+			Verbs:      metav1.Verbs{"create", "delete", "get", "list", "patch", "update"},
+		})
+	}
+	sort.Slice(resourceList.APIResources, func(i, j int) bool {
+		return resourceList.APIResources[i].Name < resourceList.APIResources[j].Name
+	})
+	return resourceList
+}
+
+func (s *fakeServer) serveResource(
+	w http.ResponseWriter,
+	r *http.Request,
+	gv schema.GroupVersion,
+	segments []string,
+) {
+	namespace := ""
+	if len(segments) > 2 && segments[0] == "namespaces" {
+		namespace = segments[1]
+		segments = segments[2:]
+	}
+	if len(segments) > 2 {
+		writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound, "subresources are not supported")
+		return
+	}
+	gvr := gv.WithResource(segments[0])
+	if _, ok := s.namespaced[gvr]; !ok {
+		writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound, "the server could not find the requested resource")
+		return
+	}
+	name := ""
+	if len(segments) > 1 {
+		name = segments[1]
+	}
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		s.list(w, r, gvr, namespace)
+	case r.Method == http.MethodGet:
+		s.get(w, gvr, namespace, name)
+	case r.Method == http.MethodPost && name == "":
+		s.write(w, r, gvr, namespace, "", http.StatusCreated)
+	case r.Method == http.MethodPut:
+		s.write(w, r, gvr, namespace, name, http.StatusOK)
+	case r.Method == http.MethodPatch:
+		if r.Header.Get("Content-Type") != string(types.ApplyPatchType) {
+			writeStatus(w, http.StatusUnsupportedMediaType, metav1.StatusReasonUnsupportedMediaType, "only apply patches are supported")
+			return
+		}
+		s.write(w, r, gvr, namespace, name, http.StatusOK)
+	case r.Method == http.MethodDelete && name != "":
+		s.delete(w, gvr, namespace, name)
+	default:
+		writeStatus(w, http.StatusMethodNotAllowed, metav1.StatusReasonMethodNotAllowed, r.Method+" is not supported")
+	}
+}
+
+func (s *fakeServer) get(w http.ResponseWriter, gvr schema.GroupVersionResource, namespace string, name string) {
+	obj, ok := s.objects[objectKey(gvr, namespace, name)]
+	if !ok {
+		writeNotFound(w, gvr, name)
+		return
+	}
+	writeJSON(w, http.StatusOK, obj.Object)
+}
+
+func (s *fakeServer) list(
+	w http.ResponseWriter,
+	r *http.Request,
+	gvr schema.GroupVersionResource,
+	namespace string,
+) {
+	selector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, err.Error())
+		return
+	}
+	keys := []string{}
+	prefix := objectKey(gvr, namespace, "")
+	if namespace == "" {
+		prefix = objectKey(gvr, "", "")
+		prefix = prefix[:len(prefix)-len("/")]
+	}
+	for key, obj := range s.objects {
+		if strings.HasPrefix(key, prefix) && selector.Matches(labels.Set(obj.GetLabels())) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	items := []interface{}{}
+	for _, key := range keys {
+		items = append(items, s.objects[key].Object)
+	}
+	kind := ""
+	for gvk, kindGVR := range s.kinds {
+		if kindGVR == gvr {
+			kind = gvk.Kind
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"apiVersion": gvr.GroupVersion().String(),
+		"kind":       kind + "List",
+		"metadata":   map[string]interface{}{"resourceVersion": strconv.Itoa(s.resourceVersion)},
+		"items":      items,
+	})
+}
+
+func (s *fakeServer) write(
+	w http.ResponseWriter,
+	r *http.Request,
+	gvr schema.GroupVersionResource,
+	namespace string,
+	name string,
+	code int,
+) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, err.Error())
+		return
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(body); err != nil {
+		writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, err.Error())
+		return
+	}
+	obj.SetNamespace(namespace)
+	if name != "" {
+		obj.SetName(name)
+	}
+	key := objectKey(gvr, namespace, obj.GetName())
+	existing, exists := s.objects[key]
+	if r.Method == http.MethodPost && exists {
+		writeStatus(w, http.StatusConflict, metav1.StatusReasonAlreadyExists, obj.GetName()+" already exists")
+		return
+	}
+	if r.Method == http.MethodPatch {
+		s.actions = append(s.actions, &Action{
+			Do:       integrationv1.ApplyDo,
+			Manifest: obj.DeepCopy().Object,
+		})
+	}
+	if exists {
+		obj.SetUID(existing.GetUID())
+		obj.SetCreationTimestamp(existing.GetCreationTimestamp())
+	}
+	s.store(gvr, obj)
+	writeJSON(w, code, obj.Object)
+}
+
+func (s *fakeServer) delete(w http.ResponseWriter, gvr schema.GroupVersionResource, namespace string, name string) {
+	key := objectKey(gvr, namespace, name)
+	obj, ok := s.objects[key]
+	if !ok {
+		writeNotFound(w, gvr, name)
+		return
+	}
+	delete(s.objects, key)
+	manifest := obj.DeepCopy()
+	manifest.SetUID("")
+	manifest.SetResourceVersion("")
+	manifest.SetCreationTimestamp(metav1.Time{})
+	s.actions = append(s.actions, &Action{
+		Do:       integrationv1.DeleteDo,
+		Manifest: manifest.Object,
+	})
+	writeJSON(w, http.StatusOK, metav1.Status{
+		TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+		Status:   metav1.StatusSuccess,
+	})
+}
+
+// convertStringData moves the string data of a secret into its data the way the api server does
+func convertStringData(secret *unstructured.Unstructured) {
+	stringData, ok, _ := unstructured.NestedStringMap(secret.Object, "stringData")
+	if !ok {
+		return
+	}
+	data, _, _ := unstructured.NestedStringMap(secret.Object, "data")
+	if data == nil {
+		data = map[string]string{}
+	}
+	for key, value := range stringData {
+		data[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	unstructured.SetNestedStringMap(secret.Object, data, "data")
+	unstructured.RemoveNestedField(secret.Object, "stringData")
+}
+
+func objectKey(gvr schema.GroupVersionResource, namespace string, name string) string {
+	return gvr.Group + "/" + gvr.Version + "/" + gvr.Resource + "/" + namespace + "/" + name
+}
+
+func containsString(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeNotFound(w http.ResponseWriter, gvr schema.GroupVersionResource, name string) {
+	writeJSON(w, http.StatusNotFound, metav1.Status{
+		TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+		Status:   metav1.StatusFailure,
+		Message:  gvr.Resource + " \"" + name + "\" not found",
+		Reason:   metav1.StatusReasonNotFound,
+		Details:  &metav1.StatusDetails{Name: name, Group: gvr.Group, Kind: gvr.Resource},
+		Code:     http.StatusNotFound,
+	})
+}
+
+func writeStatus(w http.ResponseWriter, code int, reason metav1.StatusReason, message string) {
+	writeJSON(w, code, metav1.Status{
+		TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+		Status:   metav1.StatusFailure,
+		Message:  message,
+		Reason:   reason,
+		Code:     int32(code),
+	})
+}
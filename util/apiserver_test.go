@@ -0,0 +1,186 @@
+/**
+ * File: util/apiserver_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 16:05:22
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+)
+
+// fakeAPIServer serves the discovery of core v1 resources and gets, lists, applies and deletes
+// their objects in memory, which is enough for kubectl and lookup to work with a cluster
+type fakeAPIServer struct {
+	mutex     sync.Mutex
+	objects   map[string]map[string]interface{}
+	requests  map[string]int
+	resources []metav1.APIResource
+	server    *httptest.Server
+}
+
+// newFakeAPIServer starts a fake api server for the core v1 resources, which is closed when
+// the spec ends
+func newFakeAPIServer(resources ...metav1.APIResource) *fakeAPIServer {
+	s := &fakeAPIServer{
+		objects:   map[string]map[string]interface{}{},
+		requests:  map[string]int{},
+		resources: resources,
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	DeferCleanup(s.server.Close)
+	return s
+}
+
+// config gets the config of a client of the fake api server
+func (s *fakeAPIServer) config() *rest.Config {
+	return &rest.Config{Host: s.server.URL}
+}
+
+// add stores the object so it can be read from the fake api server
+func (s *fakeAPIServer) add(resource string, object map[string]interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	metadata := object["metadata"].(map[string]interface{})
+	namespace, _ := metadata["namespace"].(string)
+	s.objects[objectPath(namespace, resource, metadata["name"].(string))] = object
+}
+
+// has reports whether the object is stored in the fake api server
+func (s *fakeAPIServer) has(resource string, namespace string, name string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, ok := s.objects[objectPath(namespace, resource, name)]
+	return ok
+}
+
+// requestCount gets how often the path was requested
+func (s *fakeAPIServer) requestCount(path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests[path]
+}
+
+func (s *fakeAPIServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.requests[r.URL.Path]++
+	w.Header().Set("Content-Type", "application/json")
+	var body interface{}
+	switch r.URL.Path {
+	case "/api":
+		body = metav1.APIVersions{
+			TypeMeta: metav1.TypeMeta{Kind: "APIVersions"},
+			Versions: []string{"v1"},
+		}
+	case "/apis":
+		body = metav1.APIGroupList{TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"}}
+	case "/api/v1":
+		body = metav1.APIResourceList{
+			TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+			GroupVersion: "v1",
+			APIResources: s.resources,
+		}
+	default:
+		if r.Method == http.MethodPatch {
+			object := map[string]interface{}{}
+			Expect(json.NewDecoder(r.Body).Decode(&object)).To(Succeed())
+			s.objects[r.URL.Path] = object
+			body = object
+		} else if r.Method == http.MethodDelete {
+			if _, ok := s.objects[r.URL.Path]; !ok {
+				writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound)
+				return
+			}
+			delete(s.objects, r.URL.Path)
+			body = metav1.Status{TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}, Status: metav1.StatusSuccess}
+		} else if object, ok := s.objects[r.URL.Path]; ok {
+			body = object
+		} else if list := s.list(r); list != nil {
+			body = list
+		} else {
+			writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound)
+			return
+		}
+	}
+	Expect(json.NewEncoder(w).Encode(body)).To(Succeed())
+}
+
+// list lists the objects of the resource in the namespace of the path that match the label
+// selector, returning nil when the path does not list a known resource
+func (s *fakeAPIServer) list(r *http.Request) map[string]interface{} {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/"), "/")
+	if len(parts) != 2 {
+		return nil
+	}
+	for _, resource := range s.resources {
+		if resource.Name != parts[1] {
+			continue
+		}
+		selector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+		Expect(err).NotTo(HaveOccurred())
+		paths := []string{}
+		prefix := objectPath(parts[0], resource.Name, "")
+		for path := range s.objects {
+			if strings.HasPrefix(path, prefix) {
+				paths = append(paths, path)
+			}
+		}
+		sort.Strings(paths)
+		items := []interface{}{}
+		for _, path := range paths {
+			object := s.objects[path]
+			objectLabels := labels.Set{}
+			metadataLabels, _ := object["metadata"].(map[string]interface{})["labels"].(map[string]interface{})
+			for key, value := range metadataLabels {
+				objectLabels[key] = value.(string)
+			}
+			if selector.Matches(objectLabels) {
+				items = append(items, object)
+			}
+		}
+		return map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       resource.Kind + "List",
+			"metadata":   map[string]interface{}{},
+			"items":      items,
+		}
+	}
+	return nil
+}
+
+func objectPath(namespace string, resource string, name string) string {
+	return "/api/v1/namespaces/" + namespace + "/" + resource + "/" + name
+}
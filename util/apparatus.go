@@ -28,10 +28,13 @@ package util
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net"
+	"net/http"
+	"net/url"
 	"strconv"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -39,18 +42,30 @@ import (
 	"github.com/tdewolff/minify"
 	minifyJson "github.com/tdewolff/minify/json"
 	"github.com/tidwall/sjson"
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 var startedApparatusTimers map[string]*time.Timer = map[string]*time.Timer{}
 
+var apparatusCapabilities map[string]*cachedCapabilities = map[string]*cachedCapabilities{}
+
+var apparatusCapabilitiesMutex *sync.Mutex = &sync.Mutex{}
+
+type cachedCapabilities struct {
+	capabilities *protocol.Capabilities
+	endpoint     string
+	expires      time.Time
+}
+
 type ApparatusUtil struct {
 	client   *kubernetes.Clientset
 	ctx      context.Context
@@ -63,7 +78,7 @@ func NewApparatusUtil(
 	ctx context.Context,
 ) *ApparatusUtil {
 	return &ApparatusUtil{
-		client:   kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie()),
+		client:   kubernetes.NewForConfigOrDie(GetRestConfig(ctx)),
 		ctx:      ctx,
 		dataUtil: NewDataUtil(ctx),
 		log:      ctrl.Log.WithName("util.ApparatusUtil"),
@@ -72,76 +87,88 @@ func NewApparatusUtil(
 }
 
 func (u *ApparatusUtil) GetPlugConfig(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 ) ([]byte, error) {
-	client := resty.New()
-	rCh := make(chan *resty.Response)
-	errCh := make(chan error)
-	min := minify.New()
-	min.AddFunc("application/json", minifyJson.Minify)
-	url := u.getPlugEndpoint(plug) + "/config"
-	kubectlUtil := NewKubectlUtil(u.ctx, plug.Namespace, EnsureServiceAccount(plug.Spec.ServiceAccountName))
-	go func() {
-		body := `{"version":1}`
-		var err error
-		if plug != nil {
-			body, err = sjson.Set(body, "plug", plug)
-			if err != nil {
-				errCh <- err
-				return
-			}
-
-			body, err = sjson.Set(body, "socket", socket)
-			if err != nil {
-				errCh <- err
-				return
-			}
-
-			plugData, err := u.dataUtil.GetPlugData(plug)
-			if err != nil {
-				errCh <- err
-				return
-			}
-			body, err = sjson.Set(body, "plugData", plugData)
-			if err != nil {
-				errCh <- err
-				return
-			}
+	return u.getConfig(
+		plug,
+		socket,
+		plug.Spec.Apparatus,
+		string(plug.UID),
+		u.getPlugEndpoint(plug),
+		plug.Spec.Vars,
+		NewKubectlUtil(u.ctx, plug.Namespace, EnsureServiceAccount(plug.Spec.ServiceAccountName)),
+		plug.Namespace,
+		"plug",
+	)
+}
 
-			socketData, err := u.dataUtil.GetSocketData(socket)
-			if err != nil {
-				errCh <- err
-				return
-			}
-			body, err = sjson.Set(body, "socketData", socketData)
-			if err != nil {
-				errCh <- err
-				return
-			}
+func (u *ApparatusUtil) GetSocketConfig(
+	socket *integrationv1.Socket,
+	plug *integrationv1.Plug,
+) ([]byte, error) {
+	return u.getConfig(
+		plug,
+		socket,
+		socket.Spec.Apparatus,
+		string(socket.UID),
+		u.getSocketEndpoint(socket),
+		socket.Spec.Vars,
+		NewKubectlUtil(u.ctx, socket.Namespace, EnsureServiceAccount(socket.Spec.ServiceAccountName)),
+		socket.Namespace,
+		"socket",
+	)
+}
 
-			if plug.Spec.Vars != nil {
-				vars, err := u.varUtil.GetVars(plug.Namespace, plug.Spec.Vars, kubectlUtil, plug, socket)
-				if err != nil {
-					errCh <- err
-					return
-				}
-				body, err = sjson.Set(body, "vars", vars)
-				if err != nil {
-					errCh <- err
-					return
-				}
-			}
+func (u *ApparatusUtil) getConfig(
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	apparatus *integrationv1.SpecApparatus,
+	uid string,
+	endpoint string,
+	specVars []*integrationv1.Var,
+	kubectlUtil *KubectlUtil,
+	namespace string,
+	kind string,
+) ([]byte, error) {
+	if err := u.ensureHealthy(apparatus, endpoint, uid, plug); err != nil {
+		return nil, err
+	}
+	if IsGRPCApparatus(apparatus) {
+		return u.getGRPCConfig(plug, socket, apparatus, uid, endpoint, specVars, kubectlUtil, namespace, kind)
+	}
+	capabilities, err := u.GetCapabilities(apparatus, endpoint, uid, plug)
+	if err != nil {
+		return nil, err
+	}
+	if !capabilities.SupportsEvent(protocol.ConfigEvent) {
+		return []byte("{}"), nil
+	}
+	request, cancel := u.newRequest(apparatus, string(protocol.ConfigEvent), 0)
+	defer cancel()
+	ctx := request.Context()
+	rCh := make(chan *resty.Response, 1)
+	errCh := make(chan error, 1)
+	url := endpoint + "/config"
+	go func() {
+		plugData, socketData, vars, err := u.getConfigData(plug, socket, specVars, kubectlUtil, namespace, kind)
+		if err != nil {
+			errCh <- err
+			return
 		}
-		body, err = min.String("application/json", string(body))
+		body, err := u.createConfigBody(capabilities, plug, socket, plugData, socketData, vars)
 		if err != nil {
 			errCh <- err
 			return
 		}
-		u.log.Info("getting plug config", "method", "POST", "url", url)
-		r, err := client.R().EnableTrace().SetHeaders(map[string]string{
+		if err := u.signRequest(request, apparatus, namespace, url, body); err != nil {
+			errCh <- err
+			return
+		}
+		u.log.Info("getting "+kind+" config", "method", "POST", "url", url)
+		r, err := request.EnableTrace().SetHeaders(map[string]string{
 			"Content-Type": "application/json",
-		}).SetBody([]byte(body)).Post(url)
+		}).SetBody(body).Post(url)
 		if err != nil {
 			errCh <- NewApparatusNetError(err, r)
 			return
@@ -150,110 +177,129 @@ func (u *ApparatusUtil) GetPlugConfig(
 	}()
 	select {
 	case r := <-rCh:
+		RecordApparatusResponseCode(plug, uid, r.StatusCode())
 		if r.IsError() {
 			return r.Body(), NewApparatusNetError(
-				errors.New("config failed with "+strconv.Itoa(r.StatusCode())+" status from POST "+url),
+				errors.New("config failed with "+strconv.Itoa(r.StatusCode())+" status from POST "+url+
+					responseSnippet(r.Body())),
 				r,
-			)
+			).WithDiagnostics(u.collectDiagnostics(
+				apparatus,
+				plug,
+				socket,
+				uid,
+				string(protocol.ConfigEvent),
+				r.StatusCode(),
+				r.Body(),
+			))
 		}
 		return r.Body(), nil
 	case err := <-errCh:
+		if netErr, ok := err.(ApparatusNetError); ok {
+			return nil, u.netFailure(netErr, apparatus, plug, socket, uid, string(protocol.ConfigEvent))
+		}
 		return nil, err
+	case <-ctx.Done():
+		return nil, u.netFailure(
+			NewApparatusNetError(ctx.Err(), nil),
+			apparatus,
+			plug,
+			socket,
+			uid,
+			string(protocol.ConfigEvent),
+		)
 	}
 }
 
-func (u *ApparatusUtil) GetSocketConfig(
-	socket *integrationv1beta1.Socket,
-	plug *integrationv1beta1.Plug,
-) ([]byte, error) {
-	client := resty.New()
-	rCh := make(chan *resty.Response)
-	errCh := make(chan error)
-	min := minify.New()
-	min.AddFunc("application/json", minifyJson.Minify)
-	url := u.getSocketEndpoint(socket) + "/config"
-	kubectlUtil := NewKubectlUtil(u.ctx, socket.Namespace, EnsureServiceAccount(socket.Spec.ServiceAccountName))
-	go func() {
-		body := `{"version":1}`
-		var err error
-		if socket != nil {
-			body, err = sjson.Set(body, "socket", socket)
-			if err != nil {
-				errCh <- err
-				return
-			}
-
-			body, err = sjson.Set(body, "plug", plug)
-			if err != nil {
-				errCh <- err
-				return
-			}
-
-			socketData, err := u.dataUtil.GetSocketData(socket)
-			if err != nil {
-				errCh <- err
-				return
-			}
-			body, err = sjson.Set(body, "socketData", socketData)
-			if err != nil {
-				errCh <- err
-				return
-			}
-
-			plugData, err := u.dataUtil.GetPlugData(plug)
-			if err != nil {
-				errCh <- err
-				return
-			}
-			body, err = sjson.Set(body, "plugData", plugData)
-			if err != nil {
-				errCh <- err
-				return
-			}
-
-			if socket.Spec.Vars != nil {
-				vars, err := u.varUtil.GetVars(socket.Namespace, socket.Spec.Vars, kubectlUtil, plug, socket)
-				if err != nil {
-					errCh <- err
-					return
-				}
-				body, err = sjson.Set(body, "vars", vars)
-				if err != nil {
-					errCh <- err
-					return
-				}
-			}
+// getConfigData gets the data and vars sent to the apparatus of the plug or socket of the kind in
+// a config request
+func (u *ApparatusUtil) getConfigData(
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	specVars []*integrationv1.Var,
+	kubectlUtil *KubectlUtil,
+	namespace string,
+	kind string,
+) (map[string]string, map[string]string, map[string]string, error) {
+	plugData, err := u.dataUtil.GetPlugData(plug)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	socketData, err := u.dataUtil.GetSocketData(socket)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var vars map[string]string
+	if specVars != nil {
+		strict := PlugStrictTemplates(plug)
+		if kind == "socket" {
+			strict = SocketStrictTemplates(socket)
 		}
-		body, err = min.String("application/json", body)
+		varValues, err := u.varUtil.GetVars(namespace, specVars, kubectlUtil, plug, socket, strict)
 		if err != nil {
-			errCh <- err
-			return
+			return nil, nil, nil, err
 		}
-		u.log.Info("getting socket config", "method", "POST", "url", url)
-		r, err := client.R().EnableTrace().SetHeaders(map[string]string{
-			"Content-Type": "application/json",
-		}).SetBody([]byte(body)).Post(url)
+		vars, err = varStrings(varValues)
 		if err != nil {
-			errCh <- NewApparatusNetError(err, r)
-			return
+			return nil, nil, nil, err
 		}
-		rCh <- r
-	}()
-	select {
-	case r := <-rCh:
-		if r.IsError() {
-			return r.Body(), NewApparatusNetError(
-				errors.New("config failed with "+strconv.Itoa(r.StatusCode())+" status from POST "+url),
-				r,
-			)
+	}
+	return plugData, socketData, vars, nil
+}
+
+func (u *ApparatusUtil) createConfigBody(
+	capabilities *protocol.Capabilities,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	plugData map[string]string,
+	socketData map[string]string,
+	vars map[string]string,
+) ([]byte, error) {
+	if capabilities.SupportsVersion(protocol.Version2) {
+		return json.Marshal(protocol.ConfigRequest{
+			Version:    protocol.Version2,
+			Event:      protocol.ConfigEvent,
+			Plug:       plug,
+			Socket:     socket,
+			PlugData:   plugData,
+			SocketData: socketData,
+			Vars:       vars,
+		})
+	}
+	min := minify.New()
+	min.AddFunc("application/json", minifyJson.Minify)
+	body := `{"version":1}`
+	var err error
+	body, err = sjson.Set(body, "plug", plug)
+	if err != nil {
+		return nil, err
+	}
+	body, err = sjson.Set(body, "socket", socket)
+	if err != nil {
+		return nil, err
+	}
+	body, err = sjson.Set(body, "plugData", plugData)
+	if err != nil {
+		return nil, err
+	}
+	body, err = sjson.Set(body, "socketData", socketData)
+	if err != nil {
+		return nil, err
+	}
+	if vars != nil {
+		body, err = sjson.Set(body, "vars", vars)
+		if err != nil {
+			return nil, err
 		}
-		return r.Body(), nil
-	case err := <-errCh:
+	}
+	body, err = min.String("application/json", body)
+	if err != nil {
 		return nil, err
 	}
+	return []byte(body), nil
 }
 
-func (u *ApparatusUtil) PlugCreated(plug *integrationv1beta1.Plug) error {
+func (u *ApparatusUtil) PlugCreated(plug *integrationv1.Plug) error {
 	if plug.Spec.Apparatus == nil {
 		return nil
 	}
@@ -272,8 +318,8 @@ func (u *ApparatusUtil) PlugCreated(plug *integrationv1beta1.Plug) error {
 }
 
 func (u *ApparatusUtil) PlugCoupled(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) error {
@@ -295,8 +341,8 @@ func (u *ApparatusUtil) PlugCoupled(
 }
 
 func (u *ApparatusUtil) PlugUpdated(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) error {
@@ -318,8 +364,8 @@ func (u *ApparatusUtil) PlugUpdated(
 }
 
 func (u *ApparatusUtil) PlugDecoupled(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) error {
@@ -341,7 +387,7 @@ func (u *ApparatusUtil) PlugDecoupled(
 }
 
 func (u *ApparatusUtil) PlugDeleted(
-	plug *integrationv1beta1.Plug,
+	plug *integrationv1.Plug,
 ) error {
 	if plug.Spec.Apparatus == nil {
 		return nil
@@ -360,7 +406,7 @@ func (u *ApparatusUtil) PlugDeleted(
 	)
 }
 
-func (u *ApparatusUtil) SocketCreated(socket *integrationv1beta1.Socket) error {
+func (u *ApparatusUtil) SocketCreated(socket *integrationv1.Socket) error {
 	if socket.Spec.Apparatus == nil {
 		return nil
 	}
@@ -379,8 +425,8 @@ func (u *ApparatusUtil) SocketCreated(socket *integrationv1beta1.Socket) error {
 }
 
 func (u *ApparatusUtil) SocketCoupled(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) error {
@@ -402,8 +448,8 @@ func (u *ApparatusUtil) SocketCoupled(
 }
 
 func (u *ApparatusUtil) SocketUpdated(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) error {
@@ -425,8 +471,8 @@ func (u *ApparatusUtil) SocketUpdated(
 }
 
 func (u *ApparatusUtil) SocketDecoupled(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) error {
@@ -448,7 +494,7 @@ func (u *ApparatusUtil) SocketDecoupled(
 }
 
 func (u *ApparatusUtil) SocketDeleted(
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 ) error {
 	if socket.Spec.Apparatus == nil {
 		return nil
@@ -467,29 +513,30 @@ func (u *ApparatusUtil) SocketDeleted(
 	)
 }
 
+// NotRunning reports whether the error was caused by an apparatus that is not running
 func (u *ApparatusUtil) NotRunning(err error) bool {
-	if strings.Contains(err.Error(), "connect: connection refused") {
-		return true
+	if unhealthyErr, ok := err.(ApparatusUnhealthyError); ok {
+		return unhealthyErr.NotRunning()
 	}
 	if netErr, ok := err.(ApparatusNetError); ok {
 		return netErr.NotRunning()
 	}
+	if grpcErr, ok := err.(ApparatusGRPCError); ok {
+		return grpcErr.NotRunning()
+	}
 	return false
 }
 
 func (u *ApparatusUtil) RenewIdleTimeout(
-	apparatus *integrationv1beta1.SpecApparatus,
+	apparatus *integrationv1.SpecApparatus,
 	name string,
 	namespace string,
 	uid string,
 ) {
-	if apparatus == nil {
+	if apparatus == nil || len(apparatus.Containers) <= 0 {
 		return
 	}
-	idleTimeout := time.Second * 60
-	if apparatus.IdleTimeout != 0 {
-		idleTimeout = time.Second * time.Duration(apparatus.IdleTimeout)
-	}
+	idleTimeout := getIdleTimeout(apparatus, uid)
 	if timer, ok := startedApparatusTimers[uid]; ok {
 		timer.Reset(idleTimeout)
 	} else {
@@ -520,15 +567,15 @@ func (u *ApparatusUtil) RenewIdleTimeout(
 }
 
 func (u *ApparatusUtil) StartFromSocket(
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 	requeueAfter *time.Duration,
 ) (bool, error) {
 	return u.Start(nil, socket, requeueAfter)
 }
 
 func (u *ApparatusUtil) Start(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	requeueAfter *time.Duration,
 ) (bool, error) {
 	plugStarted := false
@@ -566,7 +613,7 @@ func (u *ApparatusUtil) Start(
 }
 
 func (u *ApparatusUtil) start(
-	apparatus *integrationv1beta1.SpecApparatus,
+	apparatus *integrationv1.SpecApparatus,
 	name string,
 	namespace string,
 	uid string,
@@ -574,13 +621,10 @@ func (u *ApparatusUtil) start(
 	serviceAccountName string,
 	requeueAfter *time.Duration,
 ) (bool, error) {
-	if apparatus == nil || len(*apparatus.Containers) <= 0 {
+	if apparatus == nil || len(apparatus.Containers) <= 0 {
 		return false, nil
 	}
-	idleTimeout := time.Second * 60
-	if apparatus.IdleTimeout != 0 {
-		idleTimeout = time.Second * time.Duration(apparatus.IdleTimeout)
-	}
+	idleTimeout := getIdleTimeout(apparatus, uid)
 	if requeueAfter != nil {
 		idleTimeout = idleTimeout + *requeueAfter
 	}
@@ -619,7 +663,7 @@ func (u *ApparatusUtil) start(
 					},
 				},
 			},
-			Containers: *apparatus.Containers,
+			Containers: apparatus.Containers,
 		},
 	}
 	service := &v1.Service{
@@ -689,12 +733,14 @@ func (u *ApparatusUtil) start(
 		})
 	}
 	if !alreadyExists {
+		forgetCachedCapabilities(uid)
+		forgetCachedHealth(uid)
 		u.log.Info("started apparatus " + namespace + "/" + name)
 	}
 	return true, nil
 }
 
-func (u *ApparatusUtil) createPlugOwnerReference(plug *integrationv1beta1.Plug) metav1.OwnerReference {
+func (u *ApparatusUtil) createPlugOwnerReference(plug *integrationv1.Plug) metav1.OwnerReference {
 	return metav1.OwnerReference{
 		APIVersion: plug.APIVersion,
 		Kind:       plug.Kind,
@@ -703,7 +749,7 @@ func (u *ApparatusUtil) createPlugOwnerReference(plug *integrationv1beta1.Plug)
 	}
 }
 
-func (u *ApparatusUtil) createSocketOwnerReference(socket *integrationv1beta1.Socket) metav1.OwnerReference {
+func (u *ApparatusUtil) createSocketOwnerReference(socket *integrationv1.Socket) metav1.OwnerReference {
 	return metav1.OwnerReference{
 		APIVersion: socket.APIVersion,
 		Kind:       socket.Kind,
@@ -712,26 +758,55 @@ func (u *ApparatusUtil) createSocketOwnerReference(socket *integrationv1beta1.So
 	}
 }
 
-func (u *ApparatusUtil) getPlugEndpoint(plug *integrationv1beta1.Plug) string {
-	return u.getEndpoint(plug.Name+"-apparatus", plug.Namespace, plug.Spec.Apparatus, config.DebugPlugEndpoint)
+type apparatusEndpointsKey struct{}
+
+type apparatusEndpoints struct {
+	plug   string
+	socket string
+}
+
+// WithApparatusEndpoints makes the apparatus utils created with the returned context send the
+// requests of every plug and socket to the endpoints, like the debug endpoints of the process
+func WithApparatusEndpoints(ctx context.Context, plugEndpoint string, socketEndpoint string) context.Context {
+	return context.WithValue(ctx, apparatusEndpointsKey{}, apparatusEndpoints{
+		plug:   plugEndpoint,
+		socket: socketEndpoint,
+	})
+}
+
+// getDebugEndpoints gets the endpoints of the context, falling back to the debug endpoints of
+// the process
+func (u *ApparatusUtil) getDebugEndpoints() apparatusEndpoints {
+	if u.ctx != nil {
+		if endpoints, ok := u.ctx.Value(apparatusEndpointsKey{}).(apparatusEndpoints); ok {
+			return endpoints
+		}
+	}
+	return apparatusEndpoints{
+		plug:   config.DebugPlugEndpoint,
+		socket: config.DebugSocketEndpoint,
+	}
+}
+
+func (u *ApparatusUtil) getPlugEndpoint(plug *integrationv1.Plug) string {
+	return u.getEndpoint(plug.Name+"-apparatus", plug.Namespace, plug.Spec.Apparatus, u.getDebugEndpoints().plug)
 }
 
-func (u *ApparatusUtil) getSocketEndpoint(socket *integrationv1beta1.Socket) string {
-	return u.getEndpoint(socket.Name+"-apparatus", socket.Namespace, socket.Spec.Apparatus, config.DebugSocketEndpoint)
+func (u *ApparatusUtil) getSocketEndpoint(socket *integrationv1.Socket) string {
+	return u.getEndpoint(socket.Name+"-apparatus", socket.Namespace, socket.Spec.Apparatus, u.getDebugEndpoints().socket)
 }
 
 func (u *ApparatusUtil) getEndpoint(
 	name string,
 	namespace string,
-	apparatus *integrationv1beta1.SpecApparatus,
+	apparatus *integrationv1.SpecApparatus,
 	debugEndpoint string,
 ) string {
 	endpoint := debugEndpoint
 	if endpoint == "" {
 		endpoint = apparatus.Endpoint
 		if endpoint == "" || endpoint[0] == '/' {
-			if apparatus.Containers != nil &&
-				len(*apparatus.Containers) > 0 {
+			if len(apparatus.Containers) > 0 {
 				endpoint = name + "." + namespace + ".svc.cluster.local" + endpoint
 			} else {
 				return "http://localhost" + endpoint
@@ -748,11 +823,11 @@ func (u *ApparatusUtil) getEndpoint(
 }
 
 func (u *ApparatusUtil) processEvent(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
-	apparatus *integrationv1beta1.SpecApparatus,
+	apparatus *integrationv1.SpecApparatus,
 	name string,
 	namespace string,
 	uid string,
@@ -760,69 +835,434 @@ func (u *ApparatusUtil) processEvent(
 	eventName string,
 ) error {
 	u.RenewIdleTimeout(apparatus, name, namespace, uid)
+	owner := getOperationOwner(plug, socket, uid)
+	if operation := owner.get(eventName); operation != nil {
+		return u.resolveOperation(owner, operation, apparatus, plug, socket, uid, eventName)
+	}
+	if err := u.ensureHealthy(apparatus, endpoint, uid, plug); err != nil {
+		return err
+	}
+	if IsGRPCApparatus(apparatus) {
+		return u.processGRPCEvent(
+			plug,
+			socket,
+			plugConfig,
+			socketConfig,
+			apparatus,
+			namespace,
+			uid,
+			endpoint,
+			eventName,
+			owner,
+		)
+	}
+	capabilities, err := u.GetCapabilities(apparatus, endpoint, uid, plug)
+	if err != nil {
+		return err
+	}
+	if !capabilities.SupportsEvent(protocol.Event(eventName)) {
+		u.log.V(1).Info("skipped event "+eventName+" not supported by apparatus", "endpoint", endpoint)
+		return nil
+	}
+	callbackID := string(uuid.NewUUID())
+	body, err := u.createEventBody(
+		capabilities,
+		plug,
+		socket,
+		plugConfig,
+		socketConfig,
+		eventName,
+		owner.callbackURL(callbackID),
+	)
+	if err != nil {
+		return err
+	}
+	request, cancel := u.newRequest(apparatus, eventName, 3)
+	defer cancel()
+	ctx := request.Context()
+	url := endpoint + "/" + eventName
+	if err := u.signRequest(request, apparatus, namespace, url, body); err != nil {
+		return err
+	}
+	rCh := make(chan *resty.Response, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		u.log.Info("triggered event "+eventName, "method", "POST", "url", url)
+		r, err := request.EnableTrace().SetHeaders(map[string]string{
+			"Content-Type": "application/json",
+		}).SetBody(body).Post(url)
+		if err != nil {
+			errCh <- NewApparatusNetError(err, r)
+			return
+		}
+		rCh <- r
+	}()
+	select {
+	case r := <-rCh:
+		RecordApparatusResponseCode(plug, uid, r.StatusCode())
+		if r.IsError() {
+			return NewApparatusNetError(
+				errors.New("event "+eventName+" failed with "+strconv.Itoa(r.StatusCode())+" status from POST "+url+
+					responseSnippet(r.Body())),
+				r,
+			).WithDiagnostics(u.collectDiagnostics(
+				apparatus,
+				plug,
+				socket,
+				uid,
+				eventName,
+				r.StatusCode(),
+				r.Body(),
+			))
+		}
+		if r.StatusCode() == http.StatusAccepted {
+			accepted := protocol.AcceptedResponse{}
+			if err := json.Unmarshal(r.Body(), &accepted); err == nil && accepted.OperationID != "" {
+				statusURL := accepted.StatusURL
+				if statusURL == "" {
+					statusURL = endpoint + "/operations/" + accepted.OperationID
+				}
+				operation := owner.start(accepted.OperationID, callbackID, apparatus, eventName, statusURL)
+				u.log.Info("apparatus accepted event "+eventName, "operationId", operation.ID, "completion", operation.Completion)
+				return NewApparatusPendingError(operation.ID, getAsyncPollInterval(apparatus))
+			}
+		}
+		return nil
+	case err := <-errCh:
+		if netErr, ok := err.(ApparatusNetError); ok {
+			return u.netFailure(netErr, apparatus, plug, socket, uid, eventName)
+		}
+		return err
+	case <-ctx.Done():
+		return u.netFailure(NewApparatusNetError(ctx.Err(), nil), apparatus, plug, socket, uid, eventName)
+	}
+}
+
+func (u *ApparatusUtil) createEventBody(
+	capabilities *protocol.Capabilities,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	plugConfig *Config,
+	socketConfig *Config,
+	eventName string,
+	callbackURL string,
+) ([]byte, error) {
+	if capabilities.SupportsVersion(protocol.Version2) {
+		eventRequest := protocol.EventRequest{
+			Version:     protocol.Version2,
+			Event:       protocol.Event(eventName),
+			Plug:        plug,
+			Socket:      socket,
+			CallbackURL: callbackURL,
+		}
+		if plugConfig != nil {
+			eventRequest.PlugConfig = *plugConfig
+		}
+		if socketConfig != nil {
+			eventRequest.SocketConfig = *socketConfig
+		}
+		return json.Marshal(eventRequest)
+	}
 	min := minify.New()
 	min.AddFunc("application/json", minifyJson.Minify)
-	client := resty.New()
-	rCh := make(chan *resty.Response)
-	errCh := make(chan error)
 	body := `{"version":"1"}`
 	var err error
 	if plug != nil {
 		body, err = sjson.Set(body, "plug", plug)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	if socket != nil {
 		body, err = sjson.Set(body, "socket", socket)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	if plugConfig != nil {
 		body, err = sjson.Set(body, "plugConfig", plugConfig)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	if socketConfig != nil {
 		body, err = sjson.Set(body, "socketConfig", socketConfig)
 		if err != nil {
-			return err
+			return nil, err
+		}
+	}
+	if callbackURL != "" {
+		body, err = sjson.Set(body, "callbackUrl", callbackURL)
+		if err != nil {
+			return nil, err
 		}
 	}
 	body, err = min.String("application/json", body)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	url := endpoint + "/" + eventName
-	go func() {
-		u.log.Info("triggered event "+eventName, "method", "POST", "url", url)
-		r, err := client.SetRetryCount(3).R().EnableTrace().SetHeaders(map[string]string{
-			"Content-Type": "application/json",
-		}).SetBody([]byte(body)).Post(url)
+	return []byte(body), nil
+}
+
+// GetCapabilities negotiates the protocol with the apparatus, assuming version 1 with
+// every event when the apparatus does not report its capabilities
+func (u *ApparatusUtil) GetCapabilities(
+	apparatus *integrationv1.SpecApparatus,
+	endpoint string,
+	uid string,
+	plug *integrationv1.Plug,
+) (*protocol.Capabilities, error) {
+	if capabilities := getCachedCapabilities(uid, endpoint); capabilities != nil {
+		return capabilities, nil
+	}
+	request, cancel := u.newRequest(apparatus, "capabilities", 0)
+	defer cancel()
+	url := endpoint + protocol.CapabilitiesPath
+	u.log.V(1).Info("getting apparatus capabilities", "method", "GET", "url", url)
+	r, err := request.SetHeader("Accept", "application/json").Get(url)
+	if err != nil {
+		return nil, NewApparatusNetError(err, r)
+	}
+	capabilities := &protocol.Capabilities{}
+	if r.IsError() {
+		if r.StatusCode() != http.StatusNotFound &&
+			r.StatusCode() != http.StatusMethodNotAllowed &&
+			r.StatusCode() != http.StatusNotImplemented {
+			RecordApparatusResponseCode(plug, uid, r.StatusCode())
+			return nil, NewApparatusNetError(
+				errors.New("capabilities failed with "+strconv.Itoa(r.StatusCode())+" status from GET "+url),
+				r,
+			)
+		}
+	} else if err := json.Unmarshal(r.Body(), capabilities); err != nil {
+		capabilities = &protocol.Capabilities{}
+	}
+	if len(capabilities.Versions) == 0 {
+		capabilities.Versions = []protocol.Version{protocol.Version1}
+	}
+	if len(capabilities.Events) == 0 {
+		capabilities.Events = protocol.AllEvents
+	}
+	setCachedCapabilities(uid, endpoint, capabilities)
+	return capabilities, nil
+}
+
+// resolveOperation checks whether the pending operation of the event completed, polling the
+// apparatus unless the apparatus calls back, and forgets the operation once it is resolved
+func (u *ApparatusUtil) resolveOperation(
+	owner *operationOwner,
+	operation *integrationv1.PendingOperation,
+	apparatus *integrationv1.SpecApparatus,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	uid string,
+	eventName string,
+) error {
+	if operation.Status == string(protocol.OperationPending) &&
+		operation.Completion == integrationv1.PollCompletion &&
+		operation.StatusURL != "" {
+		u.log.Info("polling apparatus operation "+operation.ID, "method", "GET", "url", operation.StatusURL)
+		request, cancel := u.newRequest(apparatus, eventName, 0)
+		defer cancel()
+		r, err := request.SetHeader("Accept", "application/json").Get(operation.StatusURL)
 		if err != nil {
-			errCh <- NewApparatusNetError(err, r)
+			return NewApparatusNetError(err, r)
 		}
-		rCh <- r
-	}()
-	select {
-	case r := <-rCh:
+		RecordApparatusResponseCode(plug, uid, r.StatusCode())
 		if r.IsError() {
 			return NewApparatusNetError(
-				errors.New("event "+eventName+" failed with "+strconv.Itoa(r.StatusCode())+" status from POST "+url),
+				errors.New("operation "+operation.ID+" failed with "+strconv.Itoa(r.StatusCode())+
+					" status from GET "+operation.StatusURL),
 				r,
 			)
 		}
+		status := protocol.OperationStatusResponse{}
+		if err := json.Unmarshal(r.Body(), &status); err != nil {
+			return NewApparatusNetError(err, r)
+		}
+		if status.Status == protocol.OperationSucceeded || status.Status == protocol.OperationFailed {
+			operation.Status = string(status.Status)
+			operation.Message = status.Message
+		}
+	}
+	id := operation.ID
+	if operation.Status == string(protocol.OperationSucceeded) {
+		owner.forget(eventName)
+		u.log.Info("apparatus operation "+id+" succeeded", "event", eventName)
 		return nil
-	case err := <-errCh:
+	}
+	if operation.Status == string(protocol.OperationFailed) {
+		message := "event " + eventName + " failed in apparatus operation " + id
+		if operation.Message != "" {
+			message += ": " + operation.Message
+		}
+		body := []byte(operation.Message)
+		owner.forget(eventName)
+		return NewApparatusOperationError(errors.New(message)).WithDiagnostics(u.collectDiagnostics(
+			apparatus,
+			plug,
+			socket,
+			uid,
+			eventName,
+			0,
+			body,
+		))
+	}
+	if time.Now().After(operation.Deadline.Time) {
+		owner.forget(eventName)
+		return NewApparatusOperationError(errors.New(
+			"event " + eventName + " timed out waiting for apparatus operation " + id,
+		))
+	}
+	return NewApparatusPendingError(id, getAsyncPollInterval(apparatus))
+}
+
+// signRequest signs the POST request to the url with the signing secret of the apparatus
+func (u *ApparatusUtil) signRequest(
+	request *resty.Request,
+	apparatus *integrationv1.SpecApparatus,
+	namespace string,
+	requestURL string,
+	body []byte,
+) error {
+	signingSecret, err := u.getSigningSecret(apparatus, namespace)
+	if err != nil || signingSecret == nil {
 		return err
 	}
+	parsedURL, err := url.Parse(requestURL)
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now()
+	request.SetHeader(protocol.TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	request.SetHeader(protocol.SignatureHeader, protocol.Sign(
+		signingSecret,
+		timestamp,
+		http.MethodPost,
+		parsedURL.EscapedPath(),
+		body,
+	))
+	return nil
+}
+
+// getSigningSecret gets the secret requests to the apparatus are signed with, which
+// is nil when requests are not signed
+func (u *ApparatusUtil) getSigningSecret(
+	apparatus *integrationv1.SpecApparatus,
+	namespace string,
+) ([]byte, error) {
+	if apparatus == nil || apparatus.SigningSecret == nil {
+		return nil, nil
+	}
+	secret, err := u.client.CoreV1().Secrets(namespace).Get(
+		u.ctx,
+		apparatus.SigningSecret.Name,
+		metav1.GetOptions{},
+	)
+	if err != nil {
+		return nil, err
+	}
+	signingSecret, ok := secret.Data[apparatus.SigningSecret.Key]
+	if !ok {
+		return nil, errors.New(
+			"key " + apparatus.SigningSecret.Key + " not found in secret " + namespace + "/" + apparatus.SigningSecret.Name,
+		)
+	}
+	return signingSecret, nil
+}
+
+func getCachedCapabilities(uid string, endpoint string) *protocol.Capabilities {
+	apparatusCapabilitiesMutex.Lock()
+	defer apparatusCapabilitiesMutex.Unlock()
+	cached, ok := apparatusCapabilities[uid]
+	if !ok || cached.endpoint != endpoint || time.Now().After(cached.expires) {
+		return nil
+	}
+	return cached.capabilities
+}
+
+func setCachedCapabilities(uid string, endpoint string, capabilities *protocol.Capabilities) {
+	apparatusCapabilitiesMutex.Lock()
+	defer apparatusCapabilitiesMutex.Unlock()
+	apparatusCapabilities[uid] = &cachedCapabilities{
+		capabilities: capabilities,
+		endpoint:     endpoint,
+		expires:      time.Now().Add(config.ApparatusCapabilitiesTTL),
+	}
+}
+
+func forgetCachedCapabilities(uid string) {
+	apparatusCapabilitiesMutex.Lock()
+	defer apparatusCapabilitiesMutex.Unlock()
+	delete(apparatusCapabilities, uid)
+}
+
+// newRequest creates a request to the apparatus that is canceled with the reconcile
+// context or when the timeout of the apparatus for the event expires
+func (u *ApparatusUtil) newRequest(
+	apparatus *integrationv1.SpecApparatus,
+	eventName string,
+	retryCount int,
+) (*resty.Request, context.CancelFunc) {
+	connectTimeout, timeout := getApparatusTimeouts(apparatus, eventName)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	client := resty.New().SetTransport(transport).SetRetryCount(retryCount)
+	ctx, cancel := context.WithTimeout(u.ctx, timeout)
+	return client.R().SetContext(ctx), func() {
+		cancel()
+		transport.CloseIdleConnections()
+	}
+}
+
+func getApparatusTimeouts(
+	apparatus *integrationv1.SpecApparatus,
+	eventName string,
+) (time.Duration, time.Duration) {
+	connectTimeout := time.Second * 10
+	timeout := time.Second * 60
+	if apparatus == nil || apparatus.Timeouts == nil {
+		return connectTimeout, timeout
+	}
+	if apparatus.Timeouts.Connect != 0 {
+		connectTimeout = time.Second * time.Duration(apparatus.Timeouts.Connect)
+	}
+	if apparatus.Timeouts.Total != 0 {
+		timeout = time.Second * time.Duration(apparatus.Timeouts.Total)
+	}
+	if eventTimeout, ok := apparatus.Timeouts.Events[eventName]; ok && eventTimeout != 0 {
+		timeout = time.Second * time.Duration(eventTimeout)
+	}
+	return connectTimeout, timeout
+}
+
+// netFailure collects diagnostics when the request to the apparatus failed without a response
+func (u *ApparatusUtil) netFailure(
+	err ApparatusNetError,
+	apparatus *integrationv1.SpecApparatus,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	uid string,
+	eventName string,
+) ApparatusNetError {
+	return err.WithDiagnostics(u.collectDiagnostics(
+		apparatus,
+		plug,
+		socket,
+		uid,
+		eventName,
+		0,
+		[]byte(err.Error()),
+	))
 }
 
 type ApparatusNetError struct {
-	err      error
-	response *resty.Response
+	diagnostics string
+	err         error
+	response    *resty.Response
 }
 
 func NewApparatusNetError(err error, response *resty.Response) ApparatusNetError {
@@ -833,19 +1273,28 @@ func NewApparatusNetError(err error, response *resty.Response) ApparatusNetError
 }
 
 func (e ApparatusNetError) Error() string {
-	return e.err.Error()
+	return e.err.Error() + diagnosticsReference(e.diagnostics)
+}
+
+// WithDiagnostics references the configmap containing the diagnostics of the failure
+func (e ApparatusNetError) WithDiagnostics(diagnostics string) ApparatusNetError {
+	e.diagnostics = diagnostics
+	return e
 }
 
 func (e ApparatusNetError) Timeout() bool {
-	netErr, ok := e.err.(net.Error)
-	if ok {
+	var netErr net.Error
+	if errors.As(e.err, &netErr) {
 		return netErr.Timeout()
 	}
 	return false
 }
 
 func (e ApparatusNetError) NotRunning() bool {
-	if e.response != nil && (e.response.StatusCode() > 0 || e.Timeout()) {
+	if e.Timeout() || errors.Is(e.err, context.Canceled) {
+		return false
+	}
+	if e.response != nil && e.response.StatusCode() > 0 {
 		return false
 	}
 	return true
@@ -0,0 +1,255 @@
+/**
+ * File: /util/apparatus_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 12:33:46
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ApparatusUtil", func() {
+	var release chan struct{}
+	var server *httptest.Server
+	var requests chan *http.Request
+	var bodies chan []byte
+	var healthzRequests chan *http.Request
+
+	newPlug := func(apparatus *integrationv1.SpecApparatus) *integrationv1.Plug {
+		return &integrationv1.Plug{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-plug", Namespace: "default", UID: "plug-uid"},
+			Spec:       integrationv1.PlugSpec{Apparatus: apparatus},
+		}
+	}
+
+	BeforeEach(func() {
+		release = make(chan struct{})
+		requests = make(chan *http.Request, 10)
+		bodies = make(chan []byte, 10)
+		healthzRequests = make(chan *http.Request, 10)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v2/capabilities":
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"versions":[1,2],"events":["config","coupled"]}`))
+			case "/v2/coupled", "/v2/decoupled":
+				body, _ := io.ReadAll(r.Body)
+				requests <- r
+				bodies <- body
+			case "/legacy/capabilities":
+				w.WriteHeader(http.StatusNotFound)
+			case "/legacy/coupled":
+				body, _ := io.ReadAll(r.Body)
+				requests <- r
+				bodies <- body
+			case "/drop/coupled":
+				conn, _, err := w.(http.Hijacker).Hijack()
+				if err == nil {
+					conn.Close()
+				}
+			case "/hang/coupled", "/hang/config":
+				select {
+				case <-release:
+				case <-r.Context().Done():
+				}
+			case "/fail/coupled":
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte("database\nexploded"))
+			case "/sick/healthz":
+				healthzRequests <- r
+				w.WriteHeader(http.StatusServiceUnavailable)
+			case "/sick/coupled", "/sick/config":
+				requests <- r
+			default:
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		close(release)
+		server.Close()
+	})
+
+	It("reports an unreachable apparatus as not running", func() {
+		closedServer := httptest.NewServer(http.NotFoundHandler())
+		closedServer.Close()
+		plug := newPlug(&integrationv1.SpecApparatus{Endpoint: closedServer.URL})
+		apparatusUtil := util.NewTestApparatusUtil(context.Background())
+		err := apparatusUtil.PlugCoupled(plug, nil, nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(apparatusUtil.NotRunning(err)).To(BeTrue())
+	})
+
+	It("sends version 2 payloads to an apparatus that supports them", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{Endpoint: server.URL + "/v2"})
+		plugConfig := util.Config{"hello": "world"}
+		Expect(util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, &plugConfig, nil)).To(Succeed())
+		Eventually(requests).Should(Receive())
+		eventRequest := protocol.EventRequest{}
+		Expect(json.Unmarshal(<-bodies, &eventRequest)).To(Succeed())
+		Expect(eventRequest.Version).To(Equal(protocol.Version2))
+		Expect(eventRequest.Event).To(Equal(protocol.CoupledEvent))
+		Expect(eventRequest.Plug.Name).To(Equal("my-plug"))
+		Expect(eventRequest.PlugConfig).To(HaveKeyWithValue("hello", "world"))
+	})
+
+	It("skips events the apparatus does not support", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{Endpoint: server.URL + "/v2"})
+		Expect(util.NewTestApparatusUtil(context.Background()).PlugDecoupled(plug, nil, nil, nil)).To(Succeed())
+		Consistently(requests, 200*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("sends version 1 payloads to an apparatus without capabilities", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{Endpoint: server.URL + "/legacy"})
+		Expect(util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)).To(Succeed())
+		Eventually(requests).Should(Receive())
+		Expect(<-bodies).To(ContainSubstring(`"version":"1"`))
+	})
+
+	It("sends events to the apparatus", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{Endpoint: server.URL + "/ok"})
+		Expect(util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)).To(Succeed())
+	})
+
+	It("times out a hung event after the total timeout", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{
+			Endpoint: server.URL + "/hang",
+			Timeouts: &integrationv1.ApparatusTimeouts{Total: 1},
+		})
+		start := time.Now()
+		err := util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		var netErr util.ApparatusNetError
+		Expect(errors.As(err, &netErr)).To(BeTrue())
+		Expect(netErr.Timeout()).To(BeTrue())
+		Expect(netErr.NotRunning()).To(BeFalse())
+	})
+
+	It("prefers the timeout of the event over the total timeout", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{
+			Endpoint: server.URL + "/hang",
+			Timeouts: &integrationv1.ApparatusTimeouts{
+				Total:  30,
+				Events: map[string]uint{"coupled": 1},
+			},
+		})
+		start := time.Now()
+		Expect(util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)).NotTo(Succeed())
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+	})
+
+	It("cancels the event with the reconcile context", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{Endpoint: server.URL + "/hang"})
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(200*time.Millisecond, cancel)
+		start := time.Now()
+		err := util.NewTestApparatusUtil(ctx).PlugCoupled(plug, nil, nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		var netErr util.ApparatusNetError
+		Expect(errors.As(err, &netErr)).To(BeTrue())
+		Expect(netErr.NotRunning()).To(BeFalse())
+	})
+
+	It("reports error statuses of the apparatus", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{Endpoint: server.URL + "/fail"})
+		err := util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)
+		Expect(err).To(MatchError(ContainSubstring("failed with 500 status")))
+		Expect(err).To(MatchError(ContainSubstring("/fail/coupled: database exploded")))
+		var netErr util.ApparatusNetError
+		Expect(errors.As(err, &netErr)).To(BeTrue())
+		Expect(netErr.NotRunning()).To(BeFalse())
+	})
+
+	It("records the response codes of the apparatus of the plug on the plug only", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{Endpoint: server.URL + "/fail"})
+		apparatusUtil := util.NewTestApparatusUtil(context.Background())
+		Expect(apparatusUtil.PlugCoupled(plug, nil, nil, nil)).NotTo(Succeed())
+		Expect(plug.Status.LastApparatusResponseCode).To(Equal(http.StatusInternalServerError))
+		plug = newPlug(nil)
+		socket := &integrationv1.Socket{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-socket", Namespace: "default", UID: "socket-uid"},
+			Spec: integrationv1.SocketSpec{
+				Apparatus: &integrationv1.SpecApparatus{Endpoint: server.URL + "/fail"},
+			},
+		}
+		Expect(apparatusUtil.SocketCoupled(plug, socket, nil, nil)).NotTo(Succeed())
+		Expect(plug.Status.LastApparatusResponseCode).To(BeZero())
+		Expect(plug.Status.Conditions).To(BeEmpty())
+	})
+
+	It("does not send requests to an unhealthy apparatus", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{Endpoint: server.URL + "/sick"})
+		apparatusUtil := util.NewTestApparatusUtil(context.Background())
+		err := apparatusUtil.PlugCoupled(plug, nil, nil, nil)
+		Expect(err).To(MatchError(ContainSubstring("unhealthy with 503 status")))
+		Expect(apparatusUtil.NotRunning(err)).To(BeFalse())
+		var unhealthyErr util.ApparatusUnhealthyError
+		Expect(errors.As(err, &unhealthyErr)).To(BeTrue())
+		Expect(unhealthyErr.Reason()).To(Equal(util.ApparatusUnhealthy))
+		Expect(requests).NotTo(Receive())
+		health := util.GetApparatusHealth(plug.UID)
+		Expect(health).NotTo(BeNil())
+		Expect(health.Ready).To(BeFalse())
+	})
+
+	It("caches the health of the apparatus", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{Endpoint: server.URL + "/sick"})
+		apparatusUtil := util.NewTestApparatusUtil(context.Background())
+		Expect(apparatusUtil.PlugCoupled(plug, nil, nil, nil)).NotTo(Succeed())
+		Expect(apparatusUtil.PlugCoupled(plug, nil, nil, nil)).NotTo(Succeed())
+		Expect(healthzRequests).To(HaveLen(1))
+	})
+
+	It("does not leak goroutines when requests fail", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{
+			Endpoint: server.URL + "/drop",
+			Timeouts: &integrationv1.ApparatusTimeouts{Total: 5},
+		})
+		apparatusUtil := util.NewTestApparatusUtil(context.Background())
+		Expect(apparatusUtil.PlugCoupled(plug, nil, nil, nil)).NotTo(Succeed())
+		goroutines := runtime.NumGoroutine()
+		for i := 0; i < 8; i++ {
+			Expect(apparatusUtil.PlugCoupled(plug, nil, nil, nil)).NotTo(Succeed())
+		}
+		Eventually(runtime.NumGoroutine, 5*time.Second).Should(BeNumerically("<", goroutines+5))
+	})
+})
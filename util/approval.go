@@ -0,0 +1,104 @@
+/**
+ * File: /util/approval.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 10:21:53
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"context"
+	"errors"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type ApprovalUtil struct {
+	client *client.Client
+	ctx    context.Context
+}
+
+func NewApprovalUtil(
+	client *client.Client,
+	ctx context.Context,
+) *ApprovalUtil {
+	return &ApprovalUtil{
+		client: client,
+		ctx:    ctx,
+	}
+}
+
+func (u *ApprovalUtil) Required(socket *integrationv1.Socket) bool {
+	return socket.Spec.Approval != nil && socket.Spec.Approval.Required
+}
+
+// GetApproval returns the approval of the coupling of the plug to the socket from a permitted
+// approver. The approver of an approval is only trusted when it was recorded by the admission
+// webhook, so approvals cannot be granted while the webhooks are disabled.
+func (u *ApprovalUtil) GetApproval(
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+) (*integrationv1.CouplingApproval, error) {
+	if !config.EnableWebhooks {
+		return nil, NewValidationError(errors.New(
+			"coupling approvals require the admission webhooks to record approvers, enable webhooks",
+		))
+	}
+	approvals := &integrationv1.CouplingApprovalList{}
+	if err := (*u.client).List(u.ctx, approvals, client.InNamespace(socket.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, approval := range approvals.Items {
+		plugNamespacedName := EnsureNamespacedName(&approval.Spec.Plug, approval.Namespace)
+		if approval.Spec.Socket != socket.Name ||
+			plugNamespacedName.Name != plug.Name ||
+			plugNamespacedName.Namespace != plug.Namespace ||
+			approval.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if u.approverPermitted(approval.Spec.Approver, socket) {
+			return approval.DeepCopy(), nil
+		}
+	}
+	return nil, nil
+}
+
+func (u *ApprovalUtil) approverPermitted(
+	approver string,
+	socket *integrationv1.Socket,
+) bool {
+	if approver == "" {
+		return false
+	}
+	if socket.Spec.Approval == nil || len(socket.Spec.Approval.Approvers) == 0 {
+		return true
+	}
+	for _, permittedApprover := range socket.Spec.Approval.Approvers {
+		if permittedApprover == approver {
+			return true
+		}
+	}
+	return false
+}
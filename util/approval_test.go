@@ -0,0 +1,126 @@
+/**
+ * File: /util/approval_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 18:14:20
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Approval", func() {
+	var plug *integrationv1.Plug
+	var socket *integrationv1.Socket
+
+	BeforeEach(func() {
+		plug = &integrationv1.Plug{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "app"},
+		}
+		socket = &integrationv1.Socket{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "postgres"},
+			Spec: integrationv1.SocketSpec{
+				Approval: &integrationv1.SocketSpecApproval{
+					Required:  true,
+					Approvers: []string{"dba@example.com"},
+				},
+			},
+		}
+	})
+
+	approval := func(name string, approver string) *integrationv1.CouplingApproval {
+		return &integrationv1.CouplingApproval{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "postgres"},
+			Spec: integrationv1.CouplingApprovalSpec{
+				Socket:   "postgres",
+				Plug:     integrationv1.NamespacedName{Name: "app", Namespace: "app"},
+				Approver: approver,
+			},
+		}
+	}
+
+	getApproval := func(objects ...client.Object) (*integrationv1.CouplingApproval, error) {
+		scheme := runtime.NewScheme()
+		Expect(integrationv1.AddToScheme(scheme)).To(Succeed())
+		var fakeClient client.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		return util.NewApprovalUtil(&fakeClient, context.Background()).GetApproval(plug, socket)
+	}
+
+	It("should accept approvals recorded for a permitted approver", func() {
+		result, err := getApproval(approval("approval", "dba@example.com"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).NotTo(BeNil())
+		Expect(result.Spec.Approver).To(Equal("dba@example.com"))
+	})
+
+	It("should ignore approvals recorded for other approvers", func() {
+		result, err := getApproval(approval("approval", "mallory@example.com"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(BeNil())
+	})
+
+	It("should ignore approvals without a recorded approver", func() {
+		socket.Spec.Approval.Approvers = nil
+		result, err := getApproval(approval("approval", ""))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(BeNil())
+	})
+
+	It("should accept any recorded approver when no approvers are listed", func() {
+		socket.Spec.Approval.Approvers = nil
+		result, err := getApproval(approval("approval", "ops@example.com"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).NotTo(BeNil())
+	})
+
+	It("should ignore approvals of other plugs and sockets", func() {
+		otherPlug := approval("other-plug", "dba@example.com")
+		otherPlug.Spec.Plug.Name = "other"
+		otherSocket := approval("other-socket", "dba@example.com")
+		otherSocket.Spec.Socket = "mysql"
+		result, err := getApproval(otherPlug, otherSocket)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(BeNil())
+	})
+
+	It("should not permit approvals while webhooks are disabled", func() {
+		config.EnableWebhooks = false
+		DeferCleanup(func() {
+			config.EnableWebhooks = true
+		})
+		result, err := getApproval(approval("approval", "dba@example.com"))
+		Expect(err).To(BeAssignableToTypeOf(util.ValidationError{}))
+		Expect(result).To(BeNil())
+	})
+})
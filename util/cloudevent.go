@@ -0,0 +1,237 @@
+/**
+ * File: /util/cloudevent.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 11:02:14
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-resty/resty/v2"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const CloudEventSpecVersion = "1.0"
+
+const CloudEventTypePrefix = "com.rock8s.integration."
+
+var cloudEventPublisher *CloudEventPublisher
+
+var cloudEventPublisherOnce *sync.Once = &sync.Once{}
+
+// CloudEvent is a CloudEvent in the structured JSON content mode
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            *CloudEventData `json:"data,omitempty"`
+}
+
+type CloudEventData struct {
+	Plug   *CloudEventObject `json:"plug,omitempty"`
+	Socket *CloudEventObject `json:"socket,omitempty"`
+}
+
+type CloudEventObject struct {
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	UID       types.UID `json:"uid"`
+}
+
+type CloudEventPublisher struct {
+	client *resty.Client
+	logger logr.Logger
+	once   *sync.Once
+	sinks  []*cloudEventSink
+}
+
+type cloudEventSink struct {
+	buffer chan *CloudEvent
+	url    string
+}
+
+// NewCloudEventPublisher creates a publisher that delivers cloud events to the sinks in the
+// background, buffering at most bufferSize events per sink, giving up on a delivery attempt
+// after timeout and retrying each delivery retryCount times
+func NewCloudEventPublisher(
+	sinks []string,
+	bufferSize int,
+	retryCount int,
+	retryWaitTime time.Duration,
+	timeout time.Duration,
+) *CloudEventPublisher {
+	cloudEventSinks := make([]*cloudEventSink, 0, len(sinks))
+	for _, sink := range sinks {
+		cloudEventSinks = append(cloudEventSinks, &cloudEventSink{
+			buffer: make(chan *CloudEvent, bufferSize),
+			url:    sink,
+		})
+	}
+	return &CloudEventPublisher{
+		client: resty.New().
+			SetTimeout(timeout).
+			SetRetryCount(retryCount).
+			SetRetryWaitTime(retryWaitTime).
+			SetRetryMaxWaitTime(retryWaitTime * 10).
+			AddRetryCondition(func(r *resty.Response, err error) bool {
+				return err != nil || r.StatusCode() >= 500 || r.StatusCode() == 429
+			}),
+		logger: ctrl.Log.WithName("util.CloudEventPublisher"),
+		once:   &sync.Once{},
+		sinks:  cloudEventSinks,
+	}
+}
+
+// GetCloudEventPublisher returns the publisher for the sinks configured with
+// CLOUD_EVENT_SINKS or nil when no sinks are configured
+func GetCloudEventPublisher() *CloudEventPublisher {
+	cloudEventPublisherOnce.Do(func() {
+		sinks := []string{}
+		for _, sink := range strings.Split(config.CloudEventSinks, ",") {
+			sink = strings.TrimSpace(sink)
+			if sink != "" {
+				sinks = append(sinks, sink)
+			}
+		}
+		if len(sinks) == 0 {
+			return
+		}
+		timeout := config.CloudEventTimeout
+		if value := os.Getenv("CLOUD_EVENT_TIMEOUT"); value != "" {
+			if val, err := strconv.Atoi(value); err == nil && val > 0 {
+				timeout = time.Second * time.Duration(val)
+			}
+		}
+		cloudEventPublisher = NewCloudEventPublisher(
+			sinks,
+			config.CloudEventBufferSize,
+			config.CloudEventRetryCount,
+			config.CloudEventRetryWaitTime,
+			timeout,
+		)
+	})
+	return cloudEventPublisher
+}
+
+// Publish queues the event for delivery to every sink without blocking and returns
+// false when the event was dropped for any sink because the buffer of the sink is full
+func (p *CloudEventPublisher) Publish(event *CloudEvent) bool {
+	if p == nil || event == nil {
+		return false
+	}
+	p.once.Do(func() {
+		for _, sink := range p.sinks {
+			go p.run(sink)
+		}
+	})
+	queued := true
+	for _, sink := range p.sinks {
+		select {
+		case sink.buffer <- event:
+		default:
+			p.logger.Info("dropped cloud event because the buffer is full", "sink", sink.url, "type", event.Type, "id", event.ID)
+			queued = false
+		}
+	}
+	return queued
+}
+
+// run delivers the events queued for the sink, every sink has its own
+// goroutine so a slow sink does not delay the delivery to the others
+func (p *CloudEventPublisher) run(sink *cloudEventSink) {
+	for event := range sink.buffer {
+		if err := p.send(sink.url, event); err != nil {
+			p.logger.Error(err, "failed to deliver cloud event", "sink", sink.url, "type", event.Type, "id", event.ID)
+		}
+	}
+}
+
+func (p *CloudEventPublisher) send(sink string, event *CloudEvent) error {
+	r, err := p.client.R().
+		SetHeader("Content-Type", "application/cloudevents+json; charset=utf-8").
+		SetBody(event).
+		Post(sink)
+	if err != nil {
+		return err
+	}
+	if r.IsError() {
+		return errors.New("sink responded with status " + r.Status())
+	}
+	return nil
+}
+
+// NewCloudEvent creates the cloud event for a lifecycle transition of the object
+func NewCloudEvent(
+	transition string,
+	object client.Object,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+) *CloudEvent {
+	kind := "socket"
+	if _, ok := object.(*integrationv1.Plug); ok {
+		kind = "plug"
+	}
+	data := &CloudEventData{}
+	if plug != nil {
+		data.Plug = &CloudEventObject{
+			Name:      plug.Name,
+			Namespace: plug.Namespace,
+			UID:       plug.UID,
+		}
+	}
+	if socket != nil {
+		data.Socket = &CloudEventObject{
+			Name:      socket.Name,
+			Namespace: socket.Namespace,
+			UID:       socket.UID,
+		}
+	}
+	return &CloudEvent{
+		SpecVersion: CloudEventSpecVersion,
+		ID:          string(uuid.NewUUID()),
+		Source: "/apis/" + integrationv1.GroupVersion.String() +
+			"/namespaces/" + object.GetNamespace() + "/" + kind + "s/" + object.GetName(),
+		Type:            CloudEventTypePrefix + kind + "." + transition,
+		Subject:         object.GetName(),
+		Time:            time.Now().UTC().Format(time.RFC3339Nano),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+}
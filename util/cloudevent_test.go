@@ -0,0 +1,193 @@
+/**
+ * File: /util/cloudevent_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 11:21:05
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type cloudEventReceiver struct {
+	contentTypes []string
+	events       []util.CloudEvent
+	failures     int
+	mutex        sync.Mutex
+	server       *httptest.Server
+}
+
+func newCloudEventReceiver(failures int) *cloudEventReceiver {
+	receiver := &cloudEventReceiver{failures: failures}
+	receiver.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receiver.mutex.Lock()
+		defer receiver.mutex.Unlock()
+		if receiver.failures > 0 {
+			receiver.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		Expect(err).NotTo(HaveOccurred())
+		event := util.CloudEvent{}
+		Expect(json.Unmarshal(body, &event)).To(Succeed())
+		receiver.contentTypes = append(receiver.contentTypes, r.Header.Get("Content-Type"))
+		receiver.events = append(receiver.events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	return receiver
+}
+
+func (r *cloudEventReceiver) received() []util.CloudEvent {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]util.CloudEvent{}, r.events...)
+}
+
+func (r *cloudEventReceiver) receivedContentTypes() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string{}, r.contentTypes...)
+}
+
+var _ = Describe("CloudEventPublisher", func() {
+	plug := &integrationv1.Plug{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-plug", Namespace: "plug-ns", UID: "plug-uid"},
+	}
+	socket := &integrationv1.Socket{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-socket", Namespace: "socket-ns", UID: "socket-uid"},
+	}
+
+	It("delivers structured cloud events to every sink", func() {
+		first := newCloudEventReceiver(0)
+		defer first.server.Close()
+		second := newCloudEventReceiver(0)
+		defer second.server.Close()
+		publisher := util.NewCloudEventPublisher([]string{first.server.URL, second.server.URL}, 10, 0, time.Millisecond, time.Minute)
+
+		Expect(publisher.Publish(util.NewCloudEvent("coupled", plug, plug, socket))).To(BeTrue())
+
+		for _, receiver := range []*cloudEventReceiver{first, second} {
+			Eventually(receiver.received).Should(HaveLen(1))
+			event := receiver.received()[0]
+			Expect(event.SpecVersion).To(Equal("1.0"))
+			Expect(event.ID).NotTo(BeEmpty())
+			Expect(event.Type).To(Equal("com.rock8s.integration.plug.coupled"))
+			Expect(event.Source).To(Equal("/apis/integration.rock8s.com/v1/namespaces/plug-ns/plugs/my-plug"))
+			Expect(event.Subject).To(Equal("my-plug"))
+			Expect(event.Data.Plug.UID).To(BeEquivalentTo("plug-uid"))
+			Expect(event.Data.Socket.Name).To(Equal("my-socket"))
+			Expect(receiver.receivedContentTypes()[0]).To(HavePrefix("application/cloudevents+json"))
+		}
+	})
+
+	It("retries failed deliveries", func() {
+		receiver := newCloudEventReceiver(2)
+		defer receiver.server.Close()
+		publisher := util.NewCloudEventPublisher([]string{receiver.server.URL}, 10, 3, time.Millisecond, time.Minute)
+
+		Expect(publisher.Publish(util.NewCloudEvent("decoupled", socket, plug, socket))).To(BeTrue())
+
+		Eventually(receiver.received).Should(HaveLen(1))
+		Expect(receiver.received()[0].Type).To(Equal("com.rock8s.integration.socket.decoupled"))
+	})
+
+	It("drops events when the buffer is full", func() {
+		block := make(chan struct{})
+		delivering := make(chan struct{}, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			delivering <- struct{}{}
+			<-block
+		}))
+		defer server.Close()
+		defer close(block)
+		publisher := util.NewCloudEventPublisher([]string{server.URL}, 1, 0, time.Millisecond, time.Minute)
+
+		Expect(publisher.Publish(util.NewCloudEvent("created", plug, plug, nil))).To(BeTrue())
+		Eventually(delivering).Should(Receive())
+		Expect(publisher.Publish(util.NewCloudEvent("created", plug, plug, nil))).To(BeTrue())
+		Expect(publisher.Publish(util.NewCloudEvent("created", plug, plug, nil))).To(BeFalse())
+	})
+
+	It("gives up on deliveries to a sink that hangs", func() {
+		block := make(chan struct{})
+		receiver := newCloudEventReceiver(0)
+		defer receiver.server.Close()
+		hanging := true
+		var mutex sync.Mutex
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			hang := hanging
+			hanging = false
+			mutex.Unlock()
+			if hang {
+				<-block
+				return
+			}
+			receiver.server.Config.Handler.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+		defer close(block)
+		publisher := util.NewCloudEventPublisher([]string{server.URL}, 10, 0, time.Millisecond, time.Millisecond*50)
+
+		Expect(publisher.Publish(util.NewCloudEvent("created", plug, plug, nil))).To(BeTrue())
+		Expect(publisher.Publish(util.NewCloudEvent("deleted", plug, plug, nil))).To(BeTrue())
+
+		Eventually(receiver.received).Should(HaveLen(1))
+		Expect(receiver.received()[0].Type).To(Equal("com.rock8s.integration.plug.deleted"))
+	})
+
+	It("does not delay other sinks while a sink is slow", func() {
+		block := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-block
+		}))
+		defer slow.Close()
+		defer close(block)
+		receiver := newCloudEventReceiver(0)
+		defer receiver.server.Close()
+		publisher := util.NewCloudEventPublisher([]string{slow.URL, receiver.server.URL}, 10, 0, time.Millisecond, time.Minute)
+
+		Expect(publisher.Publish(util.NewCloudEvent("created", plug, plug, nil))).To(BeTrue())
+		Expect(publisher.Publish(util.NewCloudEvent("deleted", plug, plug, nil))).To(BeTrue())
+
+		Eventually(receiver.received).Should(HaveLen(2))
+	})
+
+	It("ignores events without a publisher", func() {
+		var publisher *util.CloudEventPublisher
+		Expect(publisher.Publish(util.NewCloudEvent("created", plug, plug, nil))).To(BeFalse())
+	})
+})
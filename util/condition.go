@@ -0,0 +1,75 @@
+/**
+ * File: /util/condition.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 13:12:37
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetCondition sets a condition observed at the generation without touching the other
+// conditions and records a transition in the history when its status or reason changed
+func SetCondition(
+	conditions *[]metav1.Condition,
+	history *[]integrationv1.ConditionTransition,
+	generation int64,
+	conditionType ConditionType,
+	status bool,
+	reason string,
+	message string,
+) {
+	condition := metav1.Condition{
+		Message:            message,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Status:             metav1.ConditionFalse,
+		Type:               string(conditionType),
+	}
+	if status {
+		condition.Status = metav1.ConditionTrue
+	}
+	existingCondition := meta.FindStatusCondition(*conditions, condition.Type)
+	transitioned := existingCondition == nil ||
+		existingCondition.Status != condition.Status ||
+		existingCondition.Reason != condition.Reason
+	meta.SetStatusCondition(conditions, condition)
+	if !transitioned {
+		return
+	}
+	*history = append(*history, integrationv1.ConditionTransition{
+		Type:    condition.Type,
+		Status:  condition.Status,
+		Reason:  condition.Reason,
+		Message: condition.Message,
+		Time:    metav1.Now(),
+	})
+	if len(*history) > config.MaxConditionHistory {
+		*history = (*history)[len(*history)-config.MaxConditionHistory:]
+	}
+}
@@ -0,0 +1,69 @@
+/**
+ * File: /util/condition_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 20:58:42
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("SetCondition", func() {
+	var conditions []metav1.Condition
+	var history []integrationv1.ConditionTransition
+
+	BeforeEach(func() {
+		conditions = nil
+		history = nil
+	})
+
+	It("should record a transition only when the status or reason changes", func() {
+		util.SetCondition(&conditions, &history, 1, util.ConditionTypeCoupled, false, "CouplingInProcess", "coupling")
+		util.SetCondition(&conditions, &history, 2, util.ConditionTypeCoupled, false, "CouplingInProcess", "still coupling")
+		util.SetCondition(&conditions, &history, 2, util.ConditionTypeCoupled, true, "CouplingSucceeded", "coupled")
+		Expect(conditions).To(HaveLen(1))
+		Expect(conditions[0].Status).To(Equal(metav1.ConditionTrue))
+		Expect(conditions[0].ObservedGeneration).To(Equal(int64(2)))
+		Expect(history).To(HaveLen(2))
+		Expect(history[0].Reason).To(Equal("CouplingInProcess"))
+		Expect(history[1].Reason).To(Equal("CouplingSucceeded"))
+	})
+
+	It("should cap the history and keep the latest transitions", func() {
+		for i := 0; i < config.MaxConditionHistory+5; i++ {
+			util.SetCondition(&conditions, &history, 1, util.ConditionTypeFailed, i%2 == 0, "Error", strconv.Itoa(i))
+		}
+		Expect(history).To(HaveLen(config.MaxConditionHistory))
+		Expect(history[0].Message).To(Equal("5"))
+		Expect(history[len(history)-1].Message).To(Equal(strconv.Itoa(config.MaxConditionHistory + 4)))
+	})
+})
@@ -28,38 +28,36 @@ package util
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 type ConfigUtil struct {
-	apparatusUtil *ApparatusUtil
-	client        *kubernetes.Clientset
-	ctx           context.Context
-	dataUtil      *DataUtil
-	varUtil       *VarUtil
+	apparatusUtil       *ApparatusUtil
+	client              *kubernetes.Clientset
+	ctx                 context.Context
+	generatedUtil       *GeneratedUtil
+	templateContextUtil *TemplateContextUtil
 }
 
 func NewConfigUtil(
 	ctx context.Context,
 ) *ConfigUtil {
 	return &ConfigUtil{
-		apparatusUtil: NewApparatusUtil(ctx),
-		client:        kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie()),
-		ctx:           ctx,
-		dataUtil:      NewDataUtil(ctx),
-		varUtil:       NewVarUtil(ctx),
+		apparatusUtil:       NewApparatusUtil(ctx),
+		client:              kubernetes.NewForConfigOrDie(GetRestConfig(ctx)),
+		ctx:                 ctx,
+		generatedUtil:       NewGeneratedUtil(ctx),
+		templateContextUtil: NewTemplateContextUtil(ctx),
 	}
 }
 
 func (u *ConfigUtil) GetPlugConfig(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 ) (map[string]string, error) {
 	plugConfig := make(map[string]string)
 	if plug.Spec.ConfigSecretName != "" {
@@ -81,10 +79,11 @@ func (u *ConfigUtil) GetPlugConfig(
 		}
 	}
 	if plug.Spec.ConfigTemplate != nil {
+		generated := u.generatedUtil.ForPlug(plug)
 		for key, value := range plug.Spec.ConfigTemplate {
-			result, err := u.plugConfigTemplateLookup(plug, value, socket)
+			result, err := u.plugConfigTemplateLookup(plug, value, socket, generated)
 			if err != nil {
-				return nil, err
+				return nil, templateErrorLocation(err, "configTemplate."+key)
 			}
 			plugConfig[key] = result
 		}
@@ -126,8 +125,8 @@ func (u *ConfigUtil) GetPlugConfig(
 }
 
 func (u *ConfigUtil) GetSocketConfig(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 ) (map[string]string, error) {
 	socketConfig := make(map[string]string)
 	if socket.Spec.ConfigSecretName != "" {
@@ -149,10 +148,11 @@ func (u *ConfigUtil) GetSocketConfig(
 		}
 	}
 	if socket.Spec.ConfigTemplate != nil {
+		generated := u.generatedUtil.ForSocket(socket, plug)
 		for key, value := range socket.Spec.ConfigTemplate {
-			result, err := u.socketConfigTemplateLookup(socket, value, plug)
+			result, err := u.socketConfigTemplateLookup(socket, value, plug, generated)
 			if err != nil {
-				return nil, err
+				return nil, templateErrorLocation(err, "configTemplate."+key)
 			}
 			socketConfig[key] = result
 		}
@@ -191,8 +191,8 @@ func (u *ConfigUtil) GetSocketConfig(
 }
 
 func (u *ConfigUtil) ValidatePlugConfig(
-	plug *integrationv1beta1.Plug,
-	configInterface *integrationv1beta1.ConfigInterface,
+	plug *integrationv1.Plug,
+	configInterface *integrationv1.ConfigInterface,
 	plugConfig map[string]string,
 ) (map[string]string, error) {
 	if configInterface == nil {
@@ -214,7 +214,7 @@ func (u *ConfigUtil) ValidatePlugConfig(
 }
 
 func (u *ConfigUtil) ValidateSocketConfig(
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 	socketConfig map[string]string,
 ) (map[string]string, error) {
 	if socket.Spec.Interface == nil {
@@ -239,98 +239,28 @@ func (u *ConfigUtil) ValidateSocketConfig(
 	return validatedSocketConfig, nil
 }
 
-func (u *ConfigUtil) plugConfigTemplateLookup(plug *integrationv1beta1.Plug, configTemplate string, socket *integrationv1beta1.Socket) (string, error) {
-	data, err := u.buildPlugConfigTemplateData(*plug, socket)
+func (u *ConfigUtil) plugConfigTemplateLookup(
+	plug *integrationv1.Plug,
+	configTemplate string,
+	socket *integrationv1.Socket,
+	generated *GeneratedValues,
+) (string, error) {
+	data, err := u.templateContextUtil.Build(PlugTemplateOwner, ConfigTemplateStage, plug, socket, nil, nil, nil, nil)
 	if err != nil {
 		return "", err
 	}
-	return Template(&data, configTemplate)
+	return RenderTemplate(plug.Spec.ConfigTemplateEngine, PlugStrictTemplates(plug), &data, configTemplate, generated.FuncMap())
 }
 
 func (u *ConfigUtil) socketConfigTemplateLookup(
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 	configTemplate string,
-	plug *integrationv1beta1.Plug,
+	plug *integrationv1.Plug,
+	generated *GeneratedValues,
 ) (string, error) {
-	data, err := u.buildSocketConfigTemplateData(*socket, plug)
+	data, err := u.templateContextUtil.Build(SocketTemplateOwner, ConfigTemplateStage, plug, socket, nil, nil, nil, nil)
 	if err != nil {
 		return "", err
 	}
-	return Template(&data, configTemplate)
-}
-
-func (u *ConfigUtil) buildPlugConfigTemplateData(
-	plug integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
-) (map[string]interface{}, error) {
-	kubectlUtil := NewKubectlUtil(u.ctx, plug.Namespace, EnsureServiceAccount(plug.Spec.ServiceAccountName))
-	dataMap := map[string]interface{}{}
-	dataMap["plug"] = plug
-	if socket != nil {
-		dataMap["socket"] = socket
-	}
-	plugData, err := u.dataUtil.GetPlugData(&plug)
-	if err != nil {
-		return dataMap, err
-	}
-	dataMap["plugData"] = plugData
-	socketData, err := u.dataUtil.GetSocketData(socket)
-	if err != nil {
-		return dataMap, err
-	}
-	dataMap["socketData"] = socketData
-	if plug.Spec.Vars != nil {
-		varsMap, err := u.varUtil.GetVars(plug.Namespace, plug.Spec.Vars, kubectlUtil, &plug, socket)
-		if err != nil {
-			return dataMap, err
-		}
-		dataMap["vars"] = varsMap
-	}
-	bData, err := json.Marshal(dataMap)
-	if err != nil {
-		return nil, err
-	}
-	var data map[string]interface{}
-	if err := json.Unmarshal(bData, &data); err != nil {
-		return nil, err
-	}
-	return data, nil
-}
-
-func (u *ConfigUtil) buildSocketConfigTemplateData(
-	socket integrationv1beta1.Socket,
-	plug *integrationv1beta1.Plug,
-) (map[string]interface{}, error) {
-	kubectlUtil := NewKubectlUtil(u.ctx, socket.Namespace, EnsureServiceAccount(socket.Spec.ServiceAccountName))
-	dataMap := map[string]interface{}{}
-	dataMap["socket"] = socket
-	if plug != nil {
-		dataMap["plug"] = plug
-	}
-	socketData, err := u.dataUtil.GetSocketData(&socket)
-	if err != nil {
-		return dataMap, err
-	}
-	dataMap["socketData"] = socketData
-	plugData, err := u.dataUtil.GetPlugData(plug)
-	if err != nil {
-		return dataMap, err
-	}
-	dataMap["plugData"] = plugData
-	if socket.Spec.Vars != nil {
-		varsMap, err := u.varUtil.GetVars(socket.Namespace, socket.Spec.Vars, kubectlUtil, plug, &socket)
-		if err != nil {
-			return dataMap, err
-		}
-		dataMap["vars"] = varsMap
-	}
-	bData, err := json.Marshal(dataMap)
-	if err != nil {
-		return nil, err
-	}
-	var data map[string]interface{}
-	if err := json.Unmarshal(bData, &data); err != nil {
-		return nil, err
-	}
-	return data, nil
+	return RenderTemplate(socket.Spec.ConfigTemplateEngine, SocketStrictTemplates(socket), &data, configTemplate, generated.FuncMap())
 }
@@ -29,10 +29,9 @@ package util
 import (
 	"context"
 
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 type DataUtil struct {
@@ -42,11 +41,12 @@ type DataUtil struct {
 
 func NewDataUtil(ctx context.Context) *DataUtil {
 	return &DataUtil{
-		client: kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie()),
+		client: kubernetes.NewForConfigOrDie(GetRestConfig(ctx)),
+		ctx:    ctx,
 	}
 }
 
-func (u *DataUtil) GetPlugData(plug *integrationv1beta1.Plug) (map[string]string, error) {
+func (u *DataUtil) GetPlugData(plug *integrationv1.Plug) (map[string]string, error) {
 	plugData := make(map[string]string)
 	if plug.Spec.DataSecretName != "" {
 		secret, err := u.client.CoreV1().Secrets(plug.Namespace).Get(
@@ -82,7 +82,7 @@ func (u *DataUtil) GetPlugData(plug *integrationv1beta1.Plug) (map[string]string
 	return plugData, nil
 }
 
-func (u *DataUtil) GetSocketData(socket *integrationv1beta1.Socket) (map[string]string, error) {
+func (u *DataUtil) GetSocketData(socket *integrationv1.Socket) (map[string]string, error) {
 	socketData := make(map[string]string)
 	if socket.Spec.DataSecretName != "" {
 		secret, err := u.client.CoreV1().Secrets(socket.Namespace).Get(
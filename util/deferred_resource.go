@@ -32,7 +32,7 @@ import (
 	"strings"
 	"time"
 
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -56,7 +56,7 @@ func NewDeferredResourceUtil(
 	client *client.Client,
 	ctx context.Context,
 	req *ctrl.Request,
-	namespacedName *integrationv1beta1.NamespacedName,
+	namespacedName *integrationv1.NamespacedName,
 ) *DeferredResourceUtil {
 	operatorNamespace := GetOperatorNamespace()
 	return &DeferredResourceUtil{
@@ -69,17 +69,17 @@ func NewDeferredResourceUtil(
 	}
 }
 
-func (u *DeferredResourceUtil) Get() (*integrationv1beta1.DeferredResource, error) {
+func (u *DeferredResourceUtil) Get() (*integrationv1.DeferredResource, error) {
 	client := *u.client
 	ctx := u.ctx
-	deferredResource := &integrationv1beta1.DeferredResource{}
+	deferredResource := &integrationv1.DeferredResource{}
 	if err := client.Get(ctx, u.namespacedName, deferredResource); err != nil {
 		return nil, err
 	}
 	return deferredResource.DeepCopy(), nil
 }
 
-func (u *DeferredResourceUtil) Update(deferredResource *integrationv1beta1.DeferredResource, requeue bool) (ctrl.Result, error) {
+func (u *DeferredResourceUtil) Update(deferredResource *integrationv1.DeferredResource, requeue bool) (ctrl.Result, error) {
 	client := *u.client
 	ctx := u.ctx
 	if err := client.Update(ctx, deferredResource); err != nil {
@@ -92,7 +92,7 @@ func (u *DeferredResourceUtil) Update(deferredResource *integrationv1beta1.Defer
 }
 
 func (u *DeferredResourceUtil) UpdateStatus(
-	deferredResource *integrationv1beta1.DeferredResource,
+	deferredResource *integrationv1.DeferredResource,
 	requeueAfter int64,
 ) (ctrl.Result, error) {
 	client := *u.client
@@ -109,7 +109,7 @@ func (u *DeferredResourceUtil) UpdateStatus(
 	return ctrl.Result{}, nil
 }
 
-func (u *DeferredResourceUtil) Delete(deferredResource *integrationv1beta1.DeferredResource) (ctrl.Result, error) {
+func (u *DeferredResourceUtil) Delete(deferredResource *integrationv1.DeferredResource) (ctrl.Result, error) {
 	client := *u.client
 	ctx := u.ctx
 	if err := client.Delete(ctx, deferredResource); err != nil {
@@ -119,7 +119,7 @@ func (u *DeferredResourceUtil) Delete(deferredResource *integrationv1beta1.Defer
 }
 
 func (u *DeferredResourceUtil) GetResolvedCondition(
-	deferredResource *integrationv1beta1.DeferredResource,
+	deferredResource *integrationv1.DeferredResource,
 ) (*metav1.Condition, error) {
 	if deferredResource == nil {
 		var err error
@@ -134,7 +134,7 @@ func (u *DeferredResourceUtil) GetResolvedCondition(
 
 func (u *DeferredResourceUtil) Error(
 	err error,
-	deferredResource *integrationv1beta1.DeferredResource,
+	deferredResource *integrationv1.DeferredResource,
 ) (ctrl.Result, error) {
 	e := err
 	if deferredResource == nil {
@@ -149,7 +149,7 @@ func (u *DeferredResourceUtil) Error(
 
 func (u *DeferredResourceUtil) UpdateErrorStatus(
 	err error,
-	deferredResource *integrationv1beta1.DeferredResource,
+	deferredResource *integrationv1.DeferredResource,
 ) (ctrl.Result, error) {
 	e := err
 	if deferredResource == nil {
@@ -173,7 +173,7 @@ func (u *DeferredResourceUtil) UpdateErrorStatus(
 
 func (u *DeferredResourceUtil) UpdateResolvedStatus(
 	conditionResolvedReason DeferredResourceConditionResolvedReason,
-	deferredResource *integrationv1beta1.DeferredResource,
+	deferredResource *integrationv1.DeferredResource,
 	appliedResource *unstructured.Unstructured,
 	message string,
 	requeueAfter int64,
@@ -195,7 +195,7 @@ func (u *DeferredResourceUtil) UpdateResolvedStatus(
 }
 
 func (u *DeferredResourceUtil) ApplyResource(
-	deferredResource *integrationv1beta1.DeferredResource,
+	deferredResource *integrationv1.DeferredResource,
 	kubectlUtil *KubectlUtil,
 ) (ctrl.Result, error) {
 	resource, err := u.getResource(deferredResource, kubectlUtil)
@@ -226,7 +226,7 @@ func (u *DeferredResourceUtil) ApplyResource(
 }
 
 func (u *DeferredResourceUtil) DeleteResource(
-	deferredResource *integrationv1beta1.DeferredResource,
+	deferredResource *integrationv1.DeferredResource,
 	kubectlUtil *KubectlUtil,
 ) error {
 	resource, err := u.getResource(deferredResource, kubectlUtil)
@@ -241,7 +241,7 @@ func (u *DeferredResourceUtil) DeleteResource(
 }
 
 func (u *DeferredResourceUtil) getResource(
-	deferredResource *integrationv1beta1.DeferredResource,
+	deferredResource *integrationv1.DeferredResource,
 	kubectlUtil *KubectlUtil,
 ) ([]byte, error) {
 	var resource unstructured.Unstructured
@@ -259,7 +259,7 @@ func (u *DeferredResourceUtil) getResource(
 func (u *DeferredResourceUtil) setResolvedStatusCondition(
 	conditionResolvedReason DeferredResourceConditionResolvedReason,
 	message string,
-	deferredResource *integrationv1beta1.DeferredResource,
+	deferredResource *integrationv1.DeferredResource,
 ) {
 	resolvedStatus := false
 	if message == "" {
@@ -272,25 +272,31 @@ func (u *DeferredResourceUtil) setResolvedStatusCondition(
 		}
 	}
 	if conditionResolvedReason != DeferredResourceError {
-		deferredResource.Status.Conditions = []metav1.Condition{}
+		SetCondition(
+			&deferredResource.Status.Conditions,
+			&deferredResource.Status.ConditionHistory,
+			deferredResource.Generation,
+			DeferredResourceConditionTypeFailed,
+			false,
+			string(conditionResolvedReason),
+			"",
+		)
 	}
 	if conditionResolvedReason == DeferredResourceSuccess {
 		resolvedStatus = true
 	}
-	condition := metav1.Condition{
-		Message:            message,
-		ObservedGeneration: deferredResource.Generation,
-		Reason:             string(conditionResolvedReason),
-		Status:             "False",
-		Type:               string(DeferredResourceConditionTypeResolved),
-	}
-	if resolvedStatus {
-		condition.Status = "True"
-	}
-	meta.SetStatusCondition(&deferredResource.Status.Conditions, condition)
+	SetCondition(
+		&deferredResource.Status.Conditions,
+		&deferredResource.Status.ConditionHistory,
+		deferredResource.Generation,
+		DeferredResourceConditionTypeResolved,
+		resolvedStatus,
+		string(conditionResolvedReason),
+		message,
+	)
 }
 
-func (u *DeferredResourceUtil) setErrorStatus(err error, deferredResource *integrationv1beta1.DeferredResource) error {
+func (u *DeferredResourceUtil) setErrorStatus(err error, deferredResource *integrationv1.DeferredResource) error {
 	e := err
 	if e == nil {
 		return nil
@@ -309,19 +315,20 @@ func (u *DeferredResourceUtil) setErrorStatus(err error, deferredResource *integ
 	if resolvedCondition != nil {
 		u.setResolvedStatusCondition(DeferredResourceError, "failed", deferredResource)
 	}
-	failedCondition := metav1.Condition{
-		Message:            message,
-		ObservedGeneration: deferredResource.Generation,
-		Reason:             "Error",
-		Status:             "True",
-		Type:               string(DeferredResourceConditionTypeFailed),
-	}
-	meta.SetStatusCondition(&deferredResource.Status.Conditions, failedCondition)
+	SetCondition(
+		&deferredResource.Status.Conditions,
+		&deferredResource.Status.ConditionHistory,
+		deferredResource.Generation,
+		DeferredResourceConditionTypeFailed,
+		true,
+		string(DeferredResourceError),
+		message,
+	)
 	return nil
 }
 
 func (u *DeferredResourceUtil) setResolvedStatus(
-	deferredResource *integrationv1beta1.DeferredResource,
+	deferredResource *integrationv1.DeferredResource,
 	appliedResource *unstructured.Unstructured,
 ) {
 	if appliedResource != nil {
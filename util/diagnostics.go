@@ -0,0 +1,247 @@
+/**
+ * File: /util/diagnostics.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 17:41:18
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const maxDiagnosticsResponseSize = 16 * 1024
+
+const maxResponseSnippetSize = 256
+
+var apparatusFailureTimes map[string]time.Time = map[string]time.Time{}
+
+var apparatusFailureTimesMutex *sync.Mutex = &sync.Mutex{}
+
+// collectDiagnostics stores the response of a failed request along with the last logs of the
+// apparatus containers in a configmap and keeps the apparatus alive for the grace period,
+// returning the namespaced name of the configmap or an empty string when nothing was stored
+func (u *ApparatusUtil) collectDiagnostics(
+	apparatus *integrationv1.SpecApparatus,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	uid string,
+	eventName string,
+	statusCode int,
+	body []byte,
+) string {
+	if apparatus == nil || (apparatus.Diagnostics != nil && apparatus.Diagnostics.Disabled) {
+		return ""
+	}
+	name, namespace, ownerReference, ok := u.getApparatusOwner(plug, socket, uid)
+	if !ok {
+		return ""
+	}
+	recordApparatusFailure(uid)
+	u.RenewIdleTimeout(apparatus, name, namespace, uid)
+	_, timeout := getApparatusTimeouts(apparatus, "diagnostics")
+	ctx, cancel := context.WithTimeout(u.ctx, timeout)
+	defer cancel()
+	response := string(body)
+	if len(response) > maxDiagnosticsResponseSize {
+		response = response[:maxDiagnosticsResponseSize]
+	}
+	data := map[string]string{
+		"event":    eventName,
+		"time":     time.Now().UTC().Format(time.RFC3339),
+		"response": response,
+	}
+	if statusCode != 0 {
+		data["status"] = strconv.Itoa(statusCode)
+	}
+	u.collectLogs(ctx, apparatus, name, namespace, data)
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-diagnostics",
+			Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				ownerReference,
+			},
+			Labels: map[string]string{
+				"apparatus": name,
+			},
+		},
+		Data: data,
+	}
+	existing, err := u.client.CoreV1().ConfigMaps(namespace).Get(ctx, configMap.Name, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			u.log.Error(err, "failed to get apparatus diagnostics "+namespace+"/"+configMap.Name)
+			return ""
+		}
+		if _, err := u.client.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{
+			FieldManager: "integration-operator",
+		}); err != nil {
+			u.log.Error(err, "failed to create apparatus diagnostics "+namespace+"/"+configMap.Name)
+			return ""
+		}
+		return namespace + "/" + configMap.Name
+	}
+	if err := checkDiagnosticsOwner(existing, ownerReference); err != nil {
+		u.log.Error(err, "refusing to update apparatus diagnostics "+namespace+"/"+configMap.Name)
+		return ""
+	}
+	configMap.ResourceVersion = existing.ResourceVersion
+	configMap.OwnerReferences = existing.OwnerReferences
+	if _, err := u.client.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{
+		FieldManager: "integration-operator",
+	}); err != nil {
+		u.log.Error(err, "failed to update apparatus diagnostics "+namespace+"/"+configMap.Name)
+		return ""
+	}
+	return namespace + "/" + configMap.Name
+}
+
+// checkDiagnosticsOwner rejects a diagnostics configmap that is not owned by the plug or socket,
+// since a configmap of the same name may belong to something else
+func checkDiagnosticsOwner(configMap *v1.ConfigMap, owner metav1.OwnerReference) error {
+	for _, ownerReference := range configMap.OwnerReferences {
+		if ownerReference.UID == owner.UID {
+			return nil
+		}
+	}
+	return k8serrors.NewConflict(
+		v1.Resource("configmaps"),
+		configMap.Name,
+		errors.New("configmap "+configMap.Namespace+"/"+configMap.Name+" is not owned by "+
+			owner.Name+" with uid "+string(owner.UID)),
+	)
+}
+
+// collectLogs adds the last lines of the logs of each container of the apparatus to the data,
+// sharing the space left in the configmap between the containers
+func (u *ApparatusUtil) collectLogs(
+	ctx context.Context,
+	apparatus *integrationv1.SpecApparatus,
+	name string,
+	namespace string,
+	data map[string]string,
+) {
+	if len(apparatus.Containers) == 0 {
+		return
+	}
+	logLines := int64(100)
+	if apparatus.Diagnostics != nil && apparatus.Diagnostics.LogLines != 0 {
+		logLines = int64(apparatus.Diagnostics.LogLines)
+	}
+	size := 0
+	for _, value := range data {
+		size += len(value)
+	}
+	limitBytes := int64((config.ApparatusDiagnosticsMaxSize - size) / len(apparatus.Containers))
+	if limitBytes <= 0 {
+		return
+	}
+	for _, container := range apparatus.Containers {
+		logs, err := u.client.CoreV1().Pods(namespace).GetLogs(name, &v1.PodLogOptions{
+			Container:  container.Name,
+			LimitBytes: &limitBytes,
+			TailLines:  &logLines,
+		}).DoRaw(ctx)
+		if err != nil {
+			data[container.Name+".log"] = "failed to get logs: " + err.Error()
+			continue
+		}
+		data[container.Name+".log"] = string(logs)
+	}
+}
+
+// getApparatusOwner gets the name of the apparatus and the owner reference of the plug or
+// socket with the uid
+func (u *ApparatusUtil) getApparatusOwner(
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	uid string,
+) (string, string, metav1.OwnerReference, bool) {
+	if plug != nil && string(plug.UID) == uid {
+		return plug.Name + "-apparatus", plug.Namespace, u.createPlugOwnerReference(plug), true
+	}
+	if socket != nil && string(socket.UID) == uid {
+		return socket.Name + "-apparatus", socket.Namespace, u.createSocketOwnerReference(socket), true
+	}
+	return "", "", metav1.OwnerReference{}, false
+}
+
+// getIdleTimeout gets how long the apparatus may be idle before it is terminated, which
+// lasts at least until the grace period after the last failed request
+func getIdleTimeout(apparatus *integrationv1.SpecApparatus, uid string) time.Duration {
+	idleTimeout := time.Second * 60
+	if apparatus.IdleTimeout != 0 {
+		idleTimeout = time.Second * time.Duration(apparatus.IdleTimeout)
+	}
+	apparatusFailureTimesMutex.Lock()
+	failureTime, ok := apparatusFailureTimes[uid]
+	apparatusFailureTimesMutex.Unlock()
+	if !ok {
+		return idleTimeout
+	}
+	gracePeriod := time.Second * 300
+	if apparatus.Diagnostics != nil && apparatus.Diagnostics.GracePeriod != 0 {
+		gracePeriod = time.Second * time.Duration(apparatus.Diagnostics.GracePeriod)
+	}
+	if remaining := gracePeriod - time.Since(failureTime); remaining > idleTimeout {
+		return remaining
+	}
+	return idleTimeout
+}
+
+func recordApparatusFailure(uid string) {
+	apparatusFailureTimesMutex.Lock()
+	defer apparatusFailureTimesMutex.Unlock()
+	apparatusFailureTimes[uid] = time.Now()
+}
+
+// responseSnippet gets the start of the response body to include in an error
+func responseSnippet(body []byte) string {
+	snippet := strings.Join(strings.Fields(string(body)), " ")
+	if len(snippet) > maxResponseSnippetSize {
+		snippet = snippet[:maxResponseSnippetSize] + "..."
+	}
+	if snippet == "" {
+		return ""
+	}
+	return ": " + snippet
+}
+
+func diagnosticsReference(diagnostics string) string {
+	if diagnostics == "" {
+		return ""
+	}
+	return "; diagnostics in configmap " + diagnostics
+}
@@ -0,0 +1,198 @@
+/**
+ * File: util/diagnostics_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 15:41:07
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Diagnostics", func() {
+	var apiServer *httptest.Server
+	var apparatusServer *httptest.Server
+	var configMaps map[string]*v1.ConfigMap
+	var mutex sync.Mutex
+	var release chan struct{}
+
+	newPlug := func(apparatus *integrationv1.SpecApparatus) *integrationv1.Plug {
+		return &integrationv1.Plug{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-plug", Namespace: "default", UID: "diagnostics-plug-uid"},
+			Spec:       integrationv1.PlugSpec{Apparatus: apparatus},
+		}
+	}
+
+	getConfigMap := func(key string) *v1.ConfigMap {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return configMaps[key]
+	}
+
+	BeforeEach(func() {
+		configMaps = map[string]*v1.ConfigMap{}
+		release = make(chan struct{})
+		apiServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/"), "/")
+			if len(parts) < 2 || parts[1] != "configmaps" {
+				writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound)
+				return
+			}
+			if r.Method == http.MethodGet {
+				configMap, ok := configMaps[parts[0]+"/"+parts[len(parts)-1]]
+				if !ok {
+					writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound)
+					return
+				}
+				Expect(json.NewEncoder(w).Encode(configMap)).To(Succeed())
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			configMap := &v1.ConfigMap{}
+			Expect(json.Unmarshal(body, configMap)).To(Succeed())
+			key := parts[0] + "/" + configMap.Name
+			if _, ok := configMaps[key]; !ok && r.Method == http.MethodPut {
+				writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound)
+				return
+			}
+			configMaps[key] = configMap
+			Expect(json.NewEncoder(w).Encode(configMap)).To(Succeed())
+		}))
+		apparatusServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/drop/coupled":
+				conn, _, err := w.(http.Hijacker).Hijack()
+				if err == nil {
+					conn.Close()
+				}
+			case "/hang/coupled":
+				select {
+				case <-release:
+				case <-r.Context().Done():
+				}
+			default:
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		close(release)
+		apparatusServer.Close()
+		apiServer.Close()
+	})
+
+	It("collects diagnostics when the apparatus drops the connection", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{Endpoint: apparatusServer.URL + "/drop"})
+		apparatusUtil := util.NewTestApparatusUtilWithConfig(context.Background(), &rest.Config{Host: apiServer.URL})
+		err := apparatusUtil.PlugCoupled(plug, nil, nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("diagnostics in configmap default/my-plug-apparatus-diagnostics"))
+		configMap := getConfigMap("default/my-plug-apparatus-diagnostics")
+		Expect(configMap).NotTo(BeNil())
+		Expect(configMap.Data).To(HaveKeyWithValue("event", "coupled"))
+		Expect(configMap.Data).To(HaveKeyWithValue("response", ContainSubstring("EOF")))
+		Expect(configMap.Data).NotTo(HaveKey("status"))
+		Expect(configMap.OwnerReferences).To(HaveLen(1))
+		Expect(configMap.OwnerReferences[0].UID).To(Equal(plug.UID))
+	})
+
+	It("collects diagnostics when the request to the apparatus times out", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{
+			Endpoint: apparatusServer.URL + "/hang",
+			Timeouts: &integrationv1.ApparatusTimeouts{Total: 1},
+		})
+		apparatusUtil := util.NewTestApparatusUtilWithConfig(context.Background(), &rest.Config{Host: apiServer.URL})
+		err := apparatusUtil.PlugCoupled(plug, nil, nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("diagnostics in configmap default/my-plug-apparatus-diagnostics"))
+		configMap := getConfigMap("default/my-plug-apparatus-diagnostics")
+		Expect(configMap).NotTo(BeNil())
+		Expect(configMap.Data["response"]).NotTo(BeEmpty())
+	})
+
+	It("updates the diagnostics configmap owned by the plug", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{Endpoint: apparatusServer.URL + "/drop"})
+		configMaps["default/my-plug-apparatus-diagnostics"] = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "my-plug-apparatus-diagnostics",
+				Namespace:       "default",
+				OwnerReferences: []metav1.OwnerReference{{Name: "my-plug", UID: plug.UID}},
+			},
+			Data: map[string]string{"event": "created"},
+		}
+		apparatusUtil := util.NewTestApparatusUtilWithConfig(context.Background(), &rest.Config{Host: apiServer.URL})
+		err := apparatusUtil.PlugCoupled(plug, nil, nil, nil)
+		Expect(err.Error()).To(ContainSubstring("diagnostics in configmap default/my-plug-apparatus-diagnostics"))
+		Expect(getConfigMap("default/my-plug-apparatus-diagnostics").Data).To(HaveKeyWithValue("event", "coupled"))
+	})
+
+	It("does not touch a diagnostics configmap owned by something else", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{Endpoint: apparatusServer.URL + "/drop"})
+		foreign := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "my-plug-apparatus-diagnostics",
+				Namespace:       "default",
+				OwnerReferences: []metav1.OwnerReference{{Name: "other", UID: "other-uid"}},
+			},
+			Data: map[string]string{"owned": "elsewhere"},
+		}
+		configMaps["default/my-plug-apparatus-diagnostics"] = foreign
+		apparatusUtil := util.NewTestApparatusUtilWithConfig(context.Background(), &rest.Config{Host: apiServer.URL})
+		err := apparatusUtil.PlugCoupled(plug, nil, nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).NotTo(ContainSubstring("diagnostics in configmap"))
+		Expect(getConfigMap("default/my-plug-apparatus-diagnostics")).To(Equal(foreign))
+	})
+
+	It("does not collect diagnostics when they are disabled", func() {
+		plug := newPlug(&integrationv1.SpecApparatus{
+			Endpoint:    apparatusServer.URL + "/drop",
+			Diagnostics: &integrationv1.ApparatusDiagnostics{Disabled: true},
+		})
+		apparatusUtil := util.NewTestApparatusUtilWithConfig(context.Background(), &rest.Config{Host: apiServer.URL})
+		err := apparatusUtil.PlugCoupled(plug, nil, nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).NotTo(ContainSubstring("diagnostics in configmap"))
+		Expect(getConfigMap("default/my-plug-apparatus-diagnostics")).To(BeNil())
+	})
+})
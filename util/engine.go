@@ -0,0 +1,240 @@
+/**
+ * File: /util/engine.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 07:20:14
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/google/go-jsonnet"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+var jsonnetIdentifierRegex = regexp.MustCompile(`^[_a-zA-Z][_a-zA-Z0-9]*$`)
+
+// RenderTemplate renders the template with the engine, returning strings produced by jsonnet
+// and cue as is and any other value as json, and failing go templates on missing keys when strict
+func RenderTemplate(
+	engine integrationv1.TemplateEngine,
+	strict bool,
+	data *map[string]interface{},
+	templateValue string,
+	funcMaps ...template.FuncMap,
+) (string, error) {
+	switch engine {
+	case "", integrationv1.GoTemplateEngine:
+		return executeTemplate(data, templateValue, strict, funcMaps...)
+	case integrationv1.JsonnetTemplateEngine, integrationv1.CUETemplateEngine:
+		output, err := evaluateTemplate(engine, *data, templateValue)
+		if err != nil {
+			return "", err
+		}
+		var value interface{}
+		if err := json.Unmarshal(output, &value); err != nil {
+			return "", NewTemplateError(err)
+		}
+		if text, ok := value.(string); ok {
+			return text, nil
+		}
+		var buff bytes.Buffer
+		if err := json.Compact(&buff, output); err != nil {
+			return "", NewTemplateError(err)
+		}
+		return buff.String(), nil
+	}
+	return "", NewTemplateError(errors.New("template engine '" + string(engine) + "' is not supported"))
+}
+
+// RenderDocuments renders the template with the engine into json documents that each hold a
+// single resource, splitting yaml streams and the lists produced by jsonnet and cue and
+// flattening resources of kind List into their items
+func RenderDocuments(
+	engine integrationv1.TemplateEngine,
+	strict bool,
+	data map[string]interface{},
+	templateValue string,
+	funcMap template.FuncMap,
+) ([]string, error) {
+	items := []interface{}{}
+	switch engine {
+	case "", integrationv1.GoTemplateEngine:
+		output, err := executeTemplate(&data, templateValue, strict, funcMap)
+		if err != nil {
+			return nil, err
+		}
+		items, err = decodeYAMLDocuments(output)
+		if err != nil {
+			return nil, err
+		}
+	case integrationv1.JsonnetTemplateEngine, integrationv1.CUETemplateEngine:
+		output, err := evaluateTemplate(engine, data, templateValue)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := json.Unmarshal(output, &value); err != nil {
+			return nil, NewTemplateError(err)
+		}
+		if list, ok := value.([]interface{}); ok {
+			items = list
+		} else {
+			items = append(items, value)
+		}
+	default:
+		return nil, NewTemplateError(errors.New("template engine '" + string(engine) + "' is not supported"))
+	}
+	return flattenDocuments(items)
+}
+
+// decodeYAMLDocuments decodes the documents of the yaml stream, skipping documents that are
+// empty or only contain comments
+func decodeYAMLDocuments(output string) ([]interface{}, error) {
+	items := []interface{}{}
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(output)))
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, NewTemplateError(err)
+		}
+		bJson, err := yaml.YAMLToJSON(document)
+		if err != nil {
+			return nil, NewTemplateError(err)
+		}
+		var item interface{}
+		if err := json.Unmarshal(bJson, &item); err != nil {
+			return nil, NewTemplateError(err)
+		}
+		if item != nil {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// flattenDocuments encodes every object as a json document, replacing resources of kind List
+// with their items
+func flattenDocuments(items []interface{}) ([]string, error) {
+	documents := []string{}
+	for len(items) > 0 {
+		item := items[0]
+		items = items[1:]
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, NewTemplateError(fmt.Errorf("template must produce an object or a list of objects but produced %v", item))
+		}
+		if kind, _ := obj["kind"].(string); kind == "List" {
+			listItems, _ := obj["items"].([]interface{})
+			items = append(listItems, items...)
+			continue
+		}
+		document, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, string(document))
+	}
+	return documents, nil
+}
+
+func evaluateTemplate(
+	engine integrationv1.TemplateEngine,
+	data map[string]interface{},
+	templateValue string,
+) ([]byte, error) {
+	if engine == integrationv1.CUETemplateEngine {
+		return evaluateCUE(data, templateValue)
+	}
+	return evaluateJsonnet(data, templateValue)
+}
+
+// evaluateJsonnet evaluates the jsonnet template with every key of the data bound to a local
+// variable, declared on the first line so errors keep their line numbers
+func evaluateJsonnet(data map[string]interface{}, templateValue string) ([]byte, error) {
+	vm := jsonnet.MakeVM()
+	vm.Importer(&jsonnetImporter{})
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if jsonnetIdentifierRegex.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var locals strings.Builder
+	for _, key := range keys {
+		value, err := json.Marshal(data[key])
+		if err != nil {
+			return nil, err
+		}
+		vm.ExtCode(key, string(value))
+		locals.WriteString("local " + key + " = std.extVar('" + key + "'); ")
+	}
+	output, err := vm.EvaluateAnonymousSnippet("template.jsonnet", locals.String()+templateValue)
+	if err != nil {
+		return nil, NewTemplateError(err).WithTemplate(data, templateValue)
+	}
+	return []byte(output), nil
+}
+
+// jsonnetImporter rejects every import, so templates cannot read the files of the operator
+type jsonnetImporter struct{}
+
+func (i *jsonnetImporter) Import(importedFrom string, importedPath string) (jsonnet.Contents, string, error) {
+	return jsonnet.Contents{}, "", errors.New("import '" + importedPath + "' is not allowed in jsonnet templates")
+}
+
+// evaluateCUE evaluates the cue template with the keys of the data in scope
+func evaluateCUE(data map[string]interface{}, templateValue string) ([]byte, error) {
+	ctx := cuecontext.New()
+	scope := ctx.Encode(data)
+	if err := scope.Err(); err != nil {
+		return nil, NewTemplateError(err)
+	}
+	value := ctx.CompileString(templateValue, cue.Scope(scope), cue.Filename("template.cue"))
+	if err := value.Validate(cue.Concrete(true)); err != nil {
+		return nil, NewTemplateError(err).WithTemplate(data, templateValue)
+	}
+	output, err := value.MarshalJSON()
+	if err != nil {
+		return nil, NewTemplateError(err)
+	}
+	return output, nil
+}
@@ -0,0 +1,198 @@
+/**
+ * File: /util/engine_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 07:46:29
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+)
+
+var _ = Describe("Template engines", func() {
+	data := map[string]interface{}{
+		"plug": map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "app"},
+		},
+		"plugConfig": map[string]interface{}{"color": "blue"},
+	}
+
+	DescribeTable("should render config and result templates",
+		func(engine integrationv1.TemplateEngine, templateValue string, expected string) {
+			result, err := util.RenderTemplate(engine, false, &data, templateValue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(expected))
+		},
+		Entry("default", integrationv1.TemplateEngine(""), `{% .plug.metadata.name %}-db`, "app-db"),
+		Entry("gotemplate", integrationv1.GoTemplateEngine, `{% .plugConfig.color %}`, "blue"),
+		Entry("jsonnet string", integrationv1.JsonnetTemplateEngine, `plug.metadata.name + "-db"`, "app-db"),
+		Entry("jsonnet object", integrationv1.JsonnetTemplateEngine, `{ color: plugConfig.color }`, `{"color":"blue"}`),
+		Entry("cue string", integrationv1.CUETemplateEngine, `"\(plug.metadata.name)-db"`, "app-db"),
+		Entry("cue object", integrationv1.CUETemplateEngine, `color: plugConfig.color`, `{"color":"blue"}`),
+	)
+
+	It("should split lists into documents", func() {
+		documents, err := util.RenderDocuments(integrationv1.JsonnetTemplateEngine, false, data, `[
+  { apiVersion: "v1", kind: "ConfigMap", metadata: { name: plug.metadata.name + "-" + i } }
+  for i in ["a", "b"]
+]`, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(documents).To(HaveLen(2))
+		Expect(documents[1]).To(ContainSubstring(`"name":"app-b"`))
+		documents, err = util.RenderDocuments(integrationv1.CUETemplateEngine, false, data, `
+apiVersion: "v1"
+kind:       "ConfigMap"
+metadata: name: plug.metadata.name
+data: color: plugConfig.color
+`, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(documents).To(HaveLen(1))
+		Expect(documents[0]).To(ContainSubstring(`"color":"blue"`))
+	})
+
+	It("should split yaml streams into documents", func() {
+		documents, err := util.RenderDocuments(integrationv1.GoTemplateEngine, false, data, `# leading comment
+{%- range $i, $tier := list "web" "worker" %}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {% $.plug.metadata.name %}-{% $tier %}
+{%- end %}
+---
+# only a comment
+`, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(documents).To(HaveLen(2))
+		Expect(documents[0]).To(ContainSubstring(`"name":"app-web"`))
+		Expect(documents[1]).To(ContainSubstring(`"name":"app-worker"`))
+	})
+
+	It("should render no documents from empty output", func() {
+		documents, err := util.RenderDocuments(integrationv1.GoTemplateEngine, false, data, `{%- if false %}kind: ConfigMap{% end %}`, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(documents).To(BeEmpty())
+	})
+
+	It("should flatten lists", func() {
+		documents, err := util.RenderDocuments(integrationv1.GoTemplateEngine, false, data, `apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: a
+  - apiVersion: v1
+    kind: List
+    items:
+      - apiVersion: v1
+        kind: Secret
+        metadata:
+          name: b
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: c
+`, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(documents).To(HaveLen(3))
+		Expect(documents[0]).To(ContainSubstring(`"kind":"ConfigMap"`))
+		Expect(documents[1]).To(ContainSubstring(`"kind":"Secret"`))
+		Expect(documents[2]).To(ContainSubstring(`"kind":"Service"`))
+	})
+
+	It("should reject documents that are not objects", func() {
+		_, err := util.RenderDocuments(integrationv1.JsonnetTemplateEngine, false, data, `["a"]`, nil)
+		Expect(err).To(MatchError(ContainSubstring("must produce an object or a list of objects")))
+	})
+
+	It("should fail on incomplete cue templates", func() {
+		_, err := util.RenderTemplate(integrationv1.CUETemplateEngine, false, &data, `color: string`)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject jsonnet imports", func() {
+		_, err := util.RenderTemplate(integrationv1.JsonnetTemplateEngine, false, &data, `import "/etc/passwd"`)
+		Expect(err).To(MatchError(ContainSubstring("import '/etc/passwd' is not allowed")))
+		_, err = util.RenderTemplate(integrationv1.JsonnetTemplateEngine, false, &data, `importstr "/etc/hostname"`)
+		Expect(err).To(MatchError(ContainSubstring("import '/etc/hostname' is not allowed")))
+		_, err = util.RenderDocuments(integrationv1.JsonnetTemplateEngine, false, data, `importbin "data.bin"`, nil)
+		Expect(err).To(MatchError(ContainSubstring("import 'data.bin' is not allowed")))
+	})
+
+	It("should reject unknown engines", func() {
+		_, err := util.RenderTemplate("mustache", false, &data, `{{ color }}`)
+		Expect(err).To(MatchError(ContainSubstring("template engine 'mustache' is not supported")))
+	})
+})
+
+var _ = Describe("Strict templates", func() {
+	data := map[string]interface{}{
+		"plugConfig": map[string]interface{}{"password": "secret"},
+		"socket":     map[string]interface{}{},
+	}
+
+	It("should render missing keys as no value unless strict", func() {
+		result, err := util.RenderTemplate(integrationv1.GoTemplateEngine, false, &data, `{% .plugConfig.pasword %}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("<no value>"))
+		_, err = util.RenderTemplate(integrationv1.GoTemplateEngine, true, &data, `{% .plugConfig.pasword %}`)
+		Expect(err).To(MatchError(ContainSubstring(`map has no entry for key "pasword"`)))
+	})
+
+	It("should allow index and default in strict templates", func() {
+		result, err := util.RenderTemplate(
+			integrationv1.GoTemplateEngine,
+			true,
+			&data,
+			`{% index .plugConfig "user" | default "admin" %}`,
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("admin"))
+	})
+
+	It("should report the template line and the available keys", func() {
+		_, err := util.RenderDocuments(integrationv1.GoTemplateEngine, true, data, `apiVersion: v1
+kind: Secret
+stringData:
+  password: {% .plugConfig.pasword %}
+`, nil)
+		var templateError util.TemplateError
+		Expect(errors.As(err, &templateError)).To(BeTrue())
+		Expect(templateError.WithLocation("resource 2").Error()).To(And(
+			HavePrefix("resource 2: line 4 `password: {% .plugConfig.pasword %}`: "),
+			HaveSuffix("(available keys: plugConfig, socket)"),
+		))
+		_, err = util.RenderTemplate(integrationv1.GoTemplateEngine, true, &data, "ok\n{% .plugConfig.password | nope %}")
+		Expect(err).To(MatchError(HavePrefix("line 2 `{% .plugConfig.password | nope %}`: ")))
+		_, err = util.RenderTemplate(integrationv1.JsonnetTemplateEngine, true, &data, "{\n  password: plugConfig.pasword,\n}")
+		Expect(err).To(MatchError(HavePrefix("line 2 `password: plugConfig.pasword,`: ")))
+	})
+})
@@ -28,32 +28,46 @@ package util
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/generic/registry"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+var warningEventTimes map[string]time.Time = map[string]time.Time{}
+
+var warningEventMutex *sync.Mutex = &sync.Mutex{}
+
 type EventUtil struct {
-	apparatusUtil *ApparatusUtil
-	resourceUtil  *ResourceUtil
-	logger        logr.Logger
+	apparatusUtil       *ApparatusUtil
+	cloudEventPublisher *CloudEventPublisher
+	resourceUtil        *ResourceUtil
+	logger              logr.Logger
 }
 
 func NewEventUtil(
 	ctx context.Context,
 ) *EventUtil {
 	return &EventUtil{
-		apparatusUtil: NewApparatusUtil(ctx),
-		resourceUtil:  NewResourceUtil(ctx),
-		logger:        log.FromContext(ctx),
+		apparatusUtil:       NewApparatusUtil(ctx),
+		cloudEventPublisher: GetCloudEventPublisher(),
+		resourceUtil:        NewResourceUtil(ctx),
+		logger:              log.FromContext(ctx),
 	}
 }
 
 func (u *EventUtil) PlugCreated(
-	plug *integrationv1beta1.Plug,
+	plug *integrationv1.Plug,
 	recorder record.EventRecorder,
 ) error {
 	u.logger.Info(fmt.Sprintf("plug %s/%s created", plug.Name, plug.Namespace))
@@ -64,12 +78,13 @@ func (u *EventUtil) PlugCreated(
 		return err
 	}
 	recorder.Event(plug, "Normal", "PlugCreated", fmt.Sprintf("plug %s/%s created", plug.Name, plug.Namespace))
+	u.cloudEventPublisher.Publish(NewCloudEvent("created", plug, plug, nil))
 	return nil
 }
 
 func (u *EventUtil) PlugCoupled(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 	recorder record.EventRecorder,
@@ -82,12 +97,13 @@ func (u *EventUtil) PlugCoupled(
 		return err
 	}
 	recorder.Event(plug, "Normal", "PlugCoupled", fmt.Sprintf("plug %s/%s coupled", plug.Name, plug.Namespace))
+	u.cloudEventPublisher.Publish(NewCloudEvent("coupled", plug, plug, socket))
 	return nil
 }
 
 func (u *EventUtil) PlugUpdated(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 	recorder record.EventRecorder,
@@ -100,12 +116,13 @@ func (u *EventUtil) PlugUpdated(
 		return err
 	}
 	recorder.Event(plug, "Normal", "PlugUpdated", fmt.Sprintf("plug %s/%s updated", plug.Name, plug.Namespace))
+	u.cloudEventPublisher.Publish(NewCloudEvent("updated", plug, plug, socket))
 	return nil
 }
 
 func (u *EventUtil) PlugDecoupled(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 	recorder record.EventRecorder,
@@ -118,11 +135,12 @@ func (u *EventUtil) PlugDecoupled(
 		return err
 	}
 	recorder.Event(plug, "Normal", "PlugDecoupled", fmt.Sprintf("plug %s/%s decoupled", plug.Name, plug.Namespace))
+	u.cloudEventPublisher.Publish(NewCloudEvent("decoupled", plug, plug, socket))
 	return nil
 }
 
 func (u *EventUtil) PlugDeleted(
-	plug *integrationv1beta1.Plug,
+	plug *integrationv1.Plug,
 	recorder record.EventRecorder,
 ) error {
 	u.logger.Info(fmt.Sprintf("plug %s/%s deleted", plug.Name, plug.Namespace))
@@ -133,11 +151,12 @@ func (u *EventUtil) PlugDeleted(
 		return err
 	}
 	recorder.Event(plug, "Normal", "PlugDeleted", fmt.Sprintf("plug %s/%s deleted", plug.Name, plug.Namespace))
+	u.cloudEventPublisher.Publish(NewCloudEvent("deleted", plug, plug, nil))
 	return nil
 }
 
 func (u *EventUtil) SocketCreated(
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 	recorder record.EventRecorder,
 ) error {
 	u.logger.Info(fmt.Sprintf("socket %s/%s created", socket.Name, socket.Namespace))
@@ -148,12 +167,13 @@ func (u *EventUtil) SocketCreated(
 		return err
 	}
 	recorder.Event(socket, "Normal", "SocketCreated", fmt.Sprintf("socket %s/%s created", socket.Name, socket.Namespace))
+	u.cloudEventPublisher.Publish(NewCloudEvent("created", socket, nil, socket))
 	return nil
 }
 
 func (u *EventUtil) SocketCoupled(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 	recorder record.EventRecorder,
@@ -166,12 +186,13 @@ func (u *EventUtil) SocketCoupled(
 		return err
 	}
 	recorder.Event(socket, "Normal", "SocketCoupled", fmt.Sprintf("socket %s/%s coupled", socket.Name, socket.Namespace))
+	u.cloudEventPublisher.Publish(NewCloudEvent("coupled", socket, plug, socket))
 	return nil
 }
 
 func (u *EventUtil) SocketUpdated(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 	recorder record.EventRecorder,
@@ -184,12 +205,13 @@ func (u *EventUtil) SocketUpdated(
 		return err
 	}
 	recorder.Event(socket, "Normal", "SocketUpdated", fmt.Sprintf("socket %s/%s updated", socket.Name, socket.Namespace))
+	u.cloudEventPublisher.Publish(NewCloudEvent("updated", socket, plug, socket))
 	return nil
 }
 
 func (u *EventUtil) SocketDecoupled(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 	recorder record.EventRecorder,
@@ -202,11 +224,12 @@ func (u *EventUtil) SocketDecoupled(
 		return err
 	}
 	recorder.Event(socket, "Normal", "SocketDecoupled", fmt.Sprintf("socket %s/%s decoupled", socket.Name, socket.Namespace))
+	u.cloudEventPublisher.Publish(NewCloudEvent("decoupled", socket, plug, socket))
 	return nil
 }
 
 func (u *EventUtil) SocketDeleted(
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 	recorder record.EventRecorder,
 ) error {
 	u.logger.Info(fmt.Sprintf("socket %s/%s deleted", socket.Name, socket.Namespace))
@@ -217,5 +240,115 @@ func (u *EventUtil) SocketDeleted(
 		return err
 	}
 	recorder.Event(socket, "Normal", "SocketDeleted", fmt.Sprintf("socket %s/%s deleted", socket.Name, socket.Namespace))
+	u.cloudEventPublisher.Publish(NewCloudEvent("deleted", socket, nil, socket))
 	return nil
 }
+
+// PlugWarning records a warning event for the error on the plug and on the socket it couples to
+func PlugWarning(
+	recorder record.EventRecorder,
+	err error,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+) {
+	if recorder == nil || err == nil || plug == nil ||
+		strings.Contains(err.Error(), registry.OptimisticLockErrorMsg) {
+		return
+	}
+	reason := GetWarningReason(err)
+	annotations := map[string]string{
+		integrationv1.GroupVersion.Group + "/plug": plug.Namespace + "/" + plug.Name,
+	}
+	if socket != nil {
+		annotations[integrationv1.GroupVersion.Group+"/socket"] = socket.Namespace + "/" + socket.Name
+	}
+	if allowWarningEvent(string(plug.UID), reason) {
+		recordWarningEvent(recorder, plug, annotations, reason, err.Error())
+	}
+	if socket != nil && allowWarningEvent(string(socket.UID)+"/"+string(plug.UID), reason) {
+		recordWarningEvent(
+			recorder,
+			socket,
+			annotations,
+			reason,
+			fmt.Sprintf("plug %s/%s: %s", plug.Name, plug.Namespace, err.Error()),
+		)
+	}
+}
+
+// SocketWarning records a warning event for the error on the socket
+func SocketWarning(
+	recorder record.EventRecorder,
+	err error,
+	socket *integrationv1.Socket,
+) {
+	if recorder == nil || err == nil || socket == nil ||
+		strings.Contains(err.Error(), registry.OptimisticLockErrorMsg) {
+		return
+	}
+	reason := GetWarningReason(err)
+	if allowWarningEvent(string(socket.UID), reason) {
+		recordWarningEvent(recorder, socket, map[string]string{
+			integrationv1.GroupVersion.Group + "/socket": socket.Namespace + "/" + socket.Name,
+		}, reason, err.Error())
+	}
+}
+
+// GetWarningReason maps an error to the stable reason of its warning event
+func GetWarningReason(err error) WarningReason {
+	var validationError ValidationError
+	var templateError TemplateError
+	var apparatusNetError ApparatusNetError
+	var resourceError ResourceError
+	if errors.As(err, &validationError) {
+		return ValidationFailed
+	}
+	if errors.As(err, &templateError) {
+		return TemplateFailed
+	}
+	var apparatusGRPCError ApparatusGRPCError
+	var apparatusUnhealthyError ApparatusUnhealthyError
+	var apparatusOperationError ApparatusOperationError
+	if errors.As(err, &apparatusNetError) ||
+		errors.As(err, &apparatusGRPCError) ||
+		errors.As(err, &apparatusUnhealthyError) ||
+		errors.As(err, &apparatusOperationError) {
+		return ApparatusFailed
+	}
+	if errors.As(err, &resourceError) {
+		if k8serrors.IsConflict(err) {
+			return ApplyConflict
+		}
+		return ApplyFailed
+	}
+	return ReconcileFailed
+}
+
+func recordWarningEvent(
+	recorder record.EventRecorder,
+	object runtime.Object,
+	annotations map[string]string,
+	reason WarningReason,
+	message string,
+) {
+	recorder.AnnotatedEventf(object, annotations, "Warning", string(reason), "%s", message)
+}
+
+// allowWarningEvent throttles warnings with the same reason for an object to one per
+// WarningEventInterval, ignoring the message which often contains changing details
+func allowWarningEvent(uid string, reason WarningReason) bool {
+	warningEventMutex.Lock()
+	defer warningEventMutex.Unlock()
+	now := time.Now()
+	for key, lastTime := range warningEventTimes {
+		if now.Sub(lastTime) >= config.WarningEventInterval {
+			delete(warningEventTimes, key)
+		}
+	}
+	key := uid + "/" + string(reason)
+	if _, ok := warningEventTimes[key]; ok {
+		return false
+	}
+	warningEventTimes[key] = now
+	return true
+}
@@ -0,0 +1,119 @@
+/**
+ * File: /util/event_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 14:20:00
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("Warning events", func() {
+	newPlug := func(uid string) *integrationv1.Plug {
+		return &integrationv1.Plug{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-plug", Namespace: "default", UID: types.UID(uid)},
+		}
+	}
+
+	newSocket := func(uid string) *integrationv1.Socket {
+		return &integrationv1.Socket{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-socket", Namespace: "default", UID: types.UID(uid)},
+		}
+	}
+
+	DescribeTable("maps errors to their reason",
+		func(err error, reason util.WarningReason) {
+			Expect(util.GetWarningReason(err)).To(Equal(reason))
+		},
+		Entry("validation", util.NewValidationError(errors.New("invalid")), util.ValidationFailed),
+		Entry("template", util.NewTemplateError(errors.New("bad template")), util.TemplateFailed),
+		Entry("apparatus", util.NewApparatusNetError(errors.New("refused"), nil), util.ApparatusFailed),
+		Entry("apparatus operation", util.NewApparatusOperationError(errors.New("failed")), util.ApparatusFailed),
+		Entry(
+			"apply conflict",
+			util.NewResourceError(k8serrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "my-config", errors.New("owned"))),
+			util.ApplyConflict,
+		),
+		Entry("apply", util.NewResourceError(errors.New("forbidden")), util.ApplyFailed),
+		Entry("anything else", errors.New("boom"), util.ReconcileFailed),
+	)
+
+	It("suppresses warnings with the same reason within the interval", func() {
+		recorder := record.NewFakeRecorder(10)
+		plug := newPlug("warning-plug-uid")
+		socket := newSocket("warning-socket-uid")
+
+		util.PlugWarning(recorder, util.NewTemplateError(errors.New("attempt 1")), plug, socket)
+		util.PlugWarning(recorder, util.NewTemplateError(errors.New("attempt 2")), plug, socket)
+
+		Expect(recorder.Events).To(HaveLen(2))
+		Expect(<-recorder.Events).To(Equal("Warning TemplateFailed attempt 1"))
+		Expect(<-recorder.Events).To(Equal("Warning TemplateFailed plug my-plug/default: attempt 1"))
+
+		util.PlugWarning(recorder, util.NewValidationError(errors.New("invalid")), plug, nil)
+		Expect(recorder.Events).To(HaveLen(1))
+		Expect(<-recorder.Events).To(Equal("Warning ValidationFailed invalid"))
+	})
+
+	It("records warnings for each plug of a socket", func() {
+		recorder := record.NewFakeRecorder(10)
+		socket := newSocket("shared-socket-uid")
+
+		util.PlugWarning(recorder, errors.New("boom"), newPlug("first-plug-uid"), socket)
+		util.PlugWarning(recorder, errors.New("boom"), newPlug("second-plug-uid"), socket)
+
+		Expect(recorder.Events).To(HaveLen(4))
+	})
+
+	It("records warnings again after the interval", func() {
+		previousInterval := config.WarningEventInterval
+		config.WarningEventInterval = time.Millisecond * 10
+		DeferCleanup(func() {
+			config.WarningEventInterval = previousInterval
+		})
+		recorder := record.NewFakeRecorder(10)
+		socket := newSocket("interval-socket-uid")
+
+		util.SocketWarning(recorder, errors.New("boom"), socket)
+		util.SocketWarning(recorder, errors.New("boom"), socket)
+		Expect(recorder.Events).To(HaveLen(1))
+
+		time.Sleep(config.WarningEventInterval)
+		util.SocketWarning(recorder, errors.New("boom"), socket)
+		Expect(recorder.Events).To(HaveLen(2))
+	})
+})
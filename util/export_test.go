@@ -0,0 +1,139 @@
+/**
+ * File: /util/export_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 12:31:09
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"context"
+	"text/template"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// NewTestApparatusUtil creates an apparatus util that does not require a cluster
+func NewTestApparatusUtil(ctx context.Context) *ApparatusUtil {
+	return &ApparatusUtil{
+		client:   kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie()),
+		ctx:      ctx,
+		dataUtil: &DataUtil{ctx: ctx},
+		log:      ctrl.Log.WithName("util.ApparatusUtil"),
+	}
+}
+
+// NewTestApparatusUtilWithConfig creates an apparatus util that stores diagnostics through the
+// api server of the config
+func NewTestApparatusUtilWithConfig(ctx context.Context, config *rest.Config) *ApparatusUtil {
+	return &ApparatusUtil{
+		client:   kubernetes.NewForConfigOrDie(config),
+		ctx:      ctx,
+		dataUtil: &DataUtil{ctx: ctx},
+		log:      ctrl.Log.WithName("util.ApparatusUtil"),
+	}
+}
+
+// NewTestGeneratedUtil creates a generated util that stores its values through the api server
+// of the config
+func NewTestGeneratedUtil(ctx context.Context, config *rest.Config) *GeneratedUtil {
+	return &GeneratedUtil{
+		client: kubernetes.NewForConfigOrDie(config),
+		ctx:    ctx,
+	}
+}
+
+// NewTestKubectlUtil creates a kubectl util that talks to the api server of the config
+func NewTestKubectlUtil(ctx context.Context, config *rest.Config) *KubectlUtil {
+	return &KubectlUtil{
+		cfg: config,
+		ctx: ctx,
+	}
+}
+
+// RenderTestSource renders the resources of the source the way resource actions do
+func RenderTestSource(
+	ctx context.Context,
+	source *integrationv1.ResourceSource,
+	data map[string]interface{},
+	namespace string,
+	funcMap template.FuncMap,
+	kubectlUtil *KubectlUtil,
+) ([]string, error) {
+	u := &ResourceUtil{ctx: ctx}
+	documents, err := u.renderSource(source, "", false, data, namespace, funcMap, kubectlUtil)
+	if err != nil {
+		return nil, err
+	}
+	return namespaceResources(documents, namespace)
+}
+
+// NewTestTemplateContextUtil creates a template context util for plugs and sockets that do not
+// reference secrets, configmaps or vars
+func NewTestTemplateContextUtil(ctx context.Context) *TemplateContextUtil {
+	return &TemplateContextUtil{
+		ctx:      ctx,
+		dataUtil: &DataUtil{ctx: ctx},
+		varUtil:  &VarUtil{},
+	}
+}
+
+// ResetTemplateCache removes the parsed templates from the template cache
+func ResetTemplateCache() {
+	templateCache.Clear()
+}
+
+// TemplateCached reports whether the template without extra functions was parsed and cached
+func TemplateCached(templateValue string) bool {
+	_, ok := templateCache.Get(templateCacheKey(templateValue, false, nil))
+	return ok
+}
+
+// ValidationProgramCached reports whether the validation rule was compiled and cached
+func ValidationProgramCached(rule string) bool {
+	_, ok := validationPrograms.Get(rule)
+	return ok
+}
+
+// SocketResultVarsAlias merges the vars and result vars the way socket result templates see them
+func SocketResultVarsAlias(vars map[string]interface{}, resultVars map[string]interface{}) map[string]interface{} {
+	return *socketResultVarsAlias(vars, resultVars)
+}
+
+// VarProgramCached reports whether the cel expression of a var was compiled and cached
+func VarProgramCached(expression string) bool {
+	_, ok := varPrograms.Get(expression)
+	return ok
+}
+
+// TemplatePassContextCount gets the number of template contexts built in the pass of the context
+func TemplatePassContextCount(ctx context.Context) int {
+	pass := templatePassFromContext(ctx)
+	pass.mutex.Lock()
+	defer pass.mutex.Unlock()
+	return len(pass.contexts)
+}
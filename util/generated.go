@@ -0,0 +1,377 @@
+/**
+ * File: /util/generated.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 05:41:12
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"regexp"
+	"strconv"
+	"sync"
+	"text/template"
+
+	"github.com/google/uuid"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const passwordCharacters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+const defaultRSAKeyBits = 2048
+
+const generatedPlugUIDLabel = "plug-uid"
+
+var generatedValueNameRegex = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+type GeneratedUtil struct {
+	client *kubernetes.Clientset
+	ctx    context.Context
+}
+
+func NewGeneratedUtil(ctx context.Context) *GeneratedUtil {
+	return &GeneratedUtil{
+		client: kubernetes.NewForConfigOrDie(GetRestConfig(ctx)),
+		ctx:    ctx,
+	}
+}
+
+// GeneratedValues generates values for templates once and stores them in a secret owned by a
+// plug or socket, so later renders reuse the same values
+type GeneratedValues struct {
+	client         *kubernetes.Clientset
+	ctx            context.Context
+	labels         map[string]string
+	mutex          *sync.Mutex
+	name           string
+	namespace      string
+	ownerReference metav1.OwnerReference
+	secret         *v1.Secret
+}
+
+// ForPlug gets the values generated by the templates of the plug
+func (u *GeneratedUtil) ForPlug(plug *integrationv1.Plug) *GeneratedValues {
+	return &GeneratedValues{
+		client:         u.client,
+		ctx:            u.ctx,
+		labels:         map[string]string{"plug": plug.Name},
+		mutex:          &sync.Mutex{},
+		name:           getPlugGeneratedSecretName(plug),
+		namespace:      plug.Namespace,
+		ownerReference: getPlugGeneratedOwnerReference(plug),
+	}
+}
+
+// ForSocket gets the values generated by the templates of the socket, which are scoped to the
+// coupled plug since a socket renders its templates once for every plug
+func (u *GeneratedUtil) ForSocket(socket *integrationv1.Socket, plug *integrationv1.Plug) *GeneratedValues {
+	labels := map[string]string{"socket": socket.Name}
+	if plug != nil {
+		labels[generatedPlugUIDLabel] = string(plug.UID)
+	}
+	return &GeneratedValues{
+		client:         u.client,
+		ctx:            u.ctx,
+		labels:         labels,
+		mutex:          &sync.Mutex{},
+		name:           getSocketGeneratedSecretName(socket, plug),
+		namespace:      socket.Namespace,
+		ownerReference: getSocketGeneratedOwnerReference(socket),
+	}
+}
+
+// PlugDecoupled deletes the values generated by the plug unless they are retained
+func (u *GeneratedUtil) PlugDecoupled(plug *integrationv1.Plug) error {
+	if plug.Spec.RetainGeneratedValues {
+		return nil
+	}
+	return u.deleteSecret(
+		plug.Namespace,
+		getPlugGeneratedSecretName(plug),
+		getPlugGeneratedOwnerReference(plug),
+		"",
+	)
+}
+
+// SocketDecoupled deletes the values generated by the socket for the plug unless they are retained
+func (u *GeneratedUtil) SocketDecoupled(socket *integrationv1.Socket, plug *integrationv1.Plug) error {
+	if socket.Spec.RetainGeneratedValues || plug == nil {
+		return nil
+	}
+	return u.deleteSecret(
+		socket.Namespace,
+		getSocketGeneratedSecretName(socket, plug),
+		getSocketGeneratedOwnerReference(socket),
+		string(plug.UID),
+	)
+}
+
+// deleteSecret deletes the secret of generated values, which fails with a conflict when
+// the secret is not owned by the plug or socket or is scoped to another plug
+func (u *GeneratedUtil) deleteSecret(
+	namespace string,
+	name string,
+	owner metav1.OwnerReference,
+	plugUID string,
+) error {
+	secret, err := u.client.CoreV1().Secrets(namespace).Get(u.ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := checkGeneratedSecretOwner(secret, owner, plugUID); err != nil {
+		return err
+	}
+	if err := u.client.CoreV1().Secrets(namespace).Delete(u.ctx, name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &secret.UID},
+	}); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkGeneratedSecretOwner fails with a conflict when the secret does not have an owner
+// reference to the uid of the plug or socket, or when the secret of a socket is labeled with
+// another plug uid, so a secret that happens to have the same name is never reused or deleted
+func checkGeneratedSecretOwner(secret *v1.Secret, owner metav1.OwnerReference, plugUID string) error {
+	owned := false
+	for _, ownerReference := range secret.OwnerReferences {
+		if ownerReference.UID == owner.UID {
+			owned = true
+			break
+		}
+	}
+	if !owned {
+		return k8serrors.NewConflict(
+			v1.Resource("secrets"),
+			secret.Name,
+			errors.New("secret "+secret.Namespace+"/"+secret.Name+" is not owned by "+
+				owner.Name+" with uid "+string(owner.UID)),
+		)
+	}
+	if plugUID != "" && secret.Labels[generatedPlugUIDLabel] != plugUID {
+		return k8serrors.NewConflict(
+			v1.Resource("secrets"),
+			secret.Name,
+			errors.New("secret "+secret.Namespace+"/"+secret.Name+" does not belong to the plug with uid "+plugUID),
+		)
+	}
+	return nil
+}
+
+// FuncMap gets the template functions that generate values, which fail when there is no
+// plug or socket to store the values for
+func (g *GeneratedValues) FuncMap() template.FuncMap {
+	if g == nil {
+		unavailable := func(name string, args ...interface{}) (string, error) {
+			return "", errors.New("generated value '" + name + "' is not available in this template")
+		}
+		return template.FuncMap{
+			"generatePassword": unavailable,
+			"generateRSAKey":   unavailable,
+			"generateUUID":     unavailable,
+		}
+	}
+	return template.FuncMap{
+		"generatePassword": g.generatePassword,
+		"generateRSAKey":   g.generateRSAKey,
+		"generateUUID":     g.generateUUID,
+	}
+}
+
+func (g *GeneratedValues) generatePassword(name string, length int) (string, error) {
+	return g.get(name, func() (string, error) {
+		if length <= 0 {
+			return "", errors.New("generated password '" + name + "' length must be greater than 0")
+		}
+		password := make([]byte, length)
+		max := big.NewInt(int64(len(passwordCharacters)))
+		for i := range password {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", err
+			}
+			password[i] = passwordCharacters[n.Int64()]
+		}
+		return string(password), nil
+	})
+}
+
+func (g *GeneratedValues) generateRSAKey(name string, bits ...int) (string, error) {
+	return g.get(name, func() (string, error) {
+		keyBits := defaultRSAKeyBits
+		if len(bits) > 0 {
+			keyBits = bits[0]
+		}
+		if keyBits < 1024 {
+			return "", errors.New("generated rsa key '" + name + "' must have at least 1024 bits but has " +
+				strconv.Itoa(keyBits))
+		}
+		key, err := rsa.GenerateKey(rand.Reader, keyBits)
+		if err != nil {
+			return "", err
+		}
+		return string(pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})), nil
+	})
+}
+
+func (g *GeneratedValues) generateUUID(name string) (string, error) {
+	return g.get(name, func() (string, error) {
+		return uuid.NewString(), nil
+	})
+}
+
+// get gets the stored value with the name, generating and storing it when it does not exist
+func (g *GeneratedValues) get(name string, generate func() (string, error)) (string, error) {
+	if !generatedValueNameRegex.MatchString(name) {
+		return "", errors.New("generated value name '" + name + "' must consist of alphanumeric characters, '-', '_' or '.'")
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if err := g.load(); err != nil {
+		return "", err
+	}
+	if value, ok := g.secret.Data[name]; ok {
+		return string(value), nil
+	}
+	value, err := generate()
+	if err != nil {
+		return "", err
+	}
+	g.secret.Data[name] = []byte(value)
+	if err := g.save(); err != nil {
+		delete(g.secret.Data, name)
+		if k8serrors.IsAlreadyExists(err) || k8serrors.IsConflict(err) {
+			g.secret = nil
+			if err := g.load(); err != nil {
+				return "", err
+			}
+			if value, ok := g.secret.Data[name]; ok {
+				return string(value), nil
+			}
+		}
+		return "", err
+	}
+	return value, nil
+}
+
+func (g *GeneratedValues) load() error {
+	if g.secret != nil {
+		return nil
+	}
+	secret, err := g.client.CoreV1().Secrets(g.namespace).Get(g.ctx, g.name, metav1.GetOptions{})
+	if err == nil {
+		if err := checkGeneratedSecretOwner(secret, g.ownerReference, g.labels[generatedPlugUIDLabel]); err != nil {
+			return err
+		}
+	} else {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		secret = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      g.name,
+				Namespace: g.namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					g.ownerReference,
+				},
+				Labels: g.labels,
+			},
+			Type: v1.SecretTypeOpaque,
+		}
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	g.secret = secret
+	return nil
+}
+
+func (g *GeneratedValues) save() error {
+	var secret *v1.Secret
+	var err error
+	if g.secret.ResourceVersion == "" {
+		secret, err = g.client.CoreV1().Secrets(g.namespace).Create(g.ctx, g.secret, metav1.CreateOptions{
+			FieldManager: "integration-operator",
+		})
+	} else {
+		secret, err = g.client.CoreV1().Secrets(g.namespace).Update(g.ctx, g.secret, metav1.UpdateOptions{
+			FieldManager: "integration-operator",
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	g.secret = secret
+	return nil
+}
+
+func getPlugGeneratedOwnerReference(plug *integrationv1.Plug) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: plug.APIVersion,
+		Kind:       plug.Kind,
+		Name:       plug.Name,
+		UID:        plug.UID,
+	}
+}
+
+func getSocketGeneratedOwnerReference(socket *integrationv1.Socket) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: socket.APIVersion,
+		Kind:       socket.Kind,
+		Name:       socket.Name,
+		UID:        socket.UID,
+	}
+}
+
+func getPlugGeneratedSecretName(plug *integrationv1.Plug) string {
+	return plug.Name + "-generated"
+}
+
+func getSocketGeneratedSecretName(socket *integrationv1.Socket, plug *integrationv1.Plug) string {
+	if plug == nil {
+		return socket.Name + "-generated"
+	}
+	return socket.Name + "-generated-" + string(plug.UID)
+}
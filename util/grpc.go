@@ -0,0 +1,401 @@
+/**
+ * File: /util/grpc.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 15:48:12
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol/apparatuspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// IsGRPCApparatus reports whether the operator communicates with the apparatus over grpc
+func IsGRPCApparatus(apparatus *integrationv1.SpecApparatus) bool {
+	return apparatus != nil && apparatus.Protocol == integrationv1.GRPCProtocol
+}
+
+func (u *ApparatusUtil) getGRPCConfig(
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	apparatus *integrationv1.SpecApparatus,
+	uid string,
+	endpoint string,
+	specVars []*integrationv1.Var,
+	kubectlUtil *KubectlUtil,
+	namespace string,
+	kind string,
+) ([]byte, error) {
+	capabilities, err := u.getGRPCCapabilities(apparatus, endpoint, uid, plug)
+	if err != nil {
+		return nil, err
+	}
+	if !capabilities.SupportsEvent(protocol.ConfigEvent) {
+		return []byte("{}"), nil
+	}
+	client, ctx, cancel, err := u.newGRPCClient(apparatus, endpoint, string(protocol.ConfigEvent))
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	plugData, socketData, vars, err := u.getConfigData(plug, socket, specVars, kubectlUtil, namespace, kind)
+	if err != nil {
+		return nil, err
+	}
+	request := &apparatuspb.ConfigRequest{
+		Version:    int32(protocol.Version2),
+		PlugData:   plugData,
+		SocketData: socketData,
+		Vars:       vars,
+	}
+	if request.Plug, err = marshalObject(plug); err != nil {
+		return nil, err
+	}
+	if request.Socket, err = marshalObject(socket); err != nil {
+		return nil, err
+	}
+	ctx, callOptions, err := u.signGRPCRequest(
+		ctx,
+		apparatus,
+		namespace,
+		apparatuspb.Apparatus_GetConfig_FullMethodName,
+		request,
+	)
+	if err != nil {
+		return nil, err
+	}
+	u.log.Info("getting "+kind+" config", "method", "GetConfig", "endpoint", endpoint)
+	response, err := client.GetConfig(ctx, request, callOptions...)
+	RecordApparatusResponseCode(plug, uid, grpcResponseCode(err))
+	if err != nil {
+		return nil, u.grpcFailure(err, apparatus, plug, socket, uid, string(protocol.ConfigEvent))
+	}
+	if response.Config == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(response.Config)
+}
+
+func (u *ApparatusUtil) processGRPCEvent(
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	plugConfig *Config,
+	socketConfig *Config,
+	apparatus *integrationv1.SpecApparatus,
+	namespace string,
+	uid string,
+	endpoint string,
+	eventName string,
+	owner *operationOwner,
+) error {
+	capabilities, err := u.getGRPCCapabilities(apparatus, endpoint, uid, plug)
+	if err != nil {
+		return err
+	}
+	if !capabilities.SupportsEvent(protocol.Event(eventName)) {
+		u.log.V(1).Info("skipped event "+eventName+" not supported by apparatus", "endpoint", endpoint)
+		return nil
+	}
+	callbackID := string(uuid.NewUUID())
+	request := &apparatuspb.EventRequest{
+		Version:     int32(protocol.Version2),
+		Event:       eventName,
+		CallbackUrl: owner.callbackURL(callbackID),
+	}
+	if request.Plug, err = marshalObject(plug); err != nil {
+		return err
+	}
+	if request.Socket, err = marshalObject(socket); err != nil {
+		return err
+	}
+	if plugConfig != nil {
+		request.PlugConfig = *plugConfig
+	}
+	if socketConfig != nil {
+		request.SocketConfig = *socketConfig
+	}
+	client, ctx, cancel, err := u.newGRPCClient(apparatus, endpoint, eventName)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	ctx, callOptions, err := u.signGRPCRequest(
+		ctx,
+		apparatus,
+		namespace,
+		apparatuspb.Apparatus_OnEvent_FullMethodName,
+		request,
+	)
+	if err != nil {
+		return err
+	}
+	u.log.Info("triggered event "+eventName, "method", "OnEvent", "endpoint", endpoint)
+	response, err := client.OnEvent(ctx, request, callOptions...)
+	RecordApparatusResponseCode(plug, uid, grpcResponseCode(err))
+	if err != nil {
+		return u.grpcFailure(err, apparatus, plug, socket, uid, eventName)
+	}
+	if response.OperationId != "" {
+		operation := owner.start(response.OperationId, callbackID, apparatus, eventName, response.StatusUrl)
+		u.log.Info("apparatus accepted event "+eventName, "operationId", operation.ID, "completion", operation.Completion)
+		return NewApparatusPendingError(operation.ID, getAsyncPollInterval(apparatus))
+	}
+	return nil
+}
+
+// getGRPCCapabilities gets the events the apparatus supports from its health, assuming
+// every event when the apparatus does not report them
+func (u *ApparatusUtil) getGRPCCapabilities(
+	apparatus *integrationv1.SpecApparatus,
+	endpoint string,
+	uid string,
+	plug *integrationv1.Plug,
+) (*protocol.Capabilities, error) {
+	if capabilities := getCachedCapabilities(uid, endpoint); capabilities != nil {
+		return capabilities, nil
+	}
+	client, ctx, cancel, err := u.newGRPCClient(apparatus, endpoint, "capabilities")
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	u.log.V(1).Info("getting apparatus health", "method", "Health", "endpoint", endpoint)
+	capabilities := &protocol.Capabilities{
+		Versions: []protocol.Version{protocol.Version2},
+	}
+	response, err := client.Health(ctx, &apparatuspb.HealthRequest{})
+	if err != nil && status.Code(err) != codes.Unimplemented {
+		RecordApparatusResponseCode(plug, uid, grpcResponseCode(err))
+		return nil, NewApparatusGRPCError(err)
+	}
+	if response != nil {
+		if response.Status == apparatuspb.HealthResponse_STATUS_NOT_SERVING {
+			return nil, NewApparatusGRPCError(status.Error(codes.Unavailable, "apparatus is not serving"))
+		}
+		for _, event := range response.Events {
+			capabilities.Events = append(capabilities.Events, protocol.Event(event))
+		}
+	}
+	if len(capabilities.Events) == 0 {
+		capabilities.Events = protocol.AllEvents
+	}
+	setCachedCapabilities(uid, endpoint, capabilities)
+	return capabilities, nil
+}
+
+// newGRPCClient creates a client of the apparatus with a context that is canceled with the
+// reconcile context or when the timeout of the apparatus for the event expires
+func (u *ApparatusUtil) newGRPCClient(
+	apparatus *integrationv1.SpecApparatus,
+	endpoint string,
+	eventName string,
+) (apparatuspb.ApparatusClient, context.Context, context.CancelFunc, error) {
+	target, transportCredentials, err := getGRPCTarget(endpoint)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	connectTimeout, timeout := getApparatusTimeouts(apparatus, eventName)
+	conn, err := grpc.DialContext(
+		u.ctx,
+		target,
+		grpc.WithTransportCredentials(transportCredentials),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: connectTimeout,
+		}),
+	)
+	if err != nil {
+		return nil, nil, nil, NewApparatusGRPCError(err)
+	}
+	ctx, cancel := context.WithTimeout(u.ctx, timeout)
+	return apparatuspb.NewApparatusClient(conn), ctx, func() {
+		cancel()
+		conn.Close()
+	}, nil
+}
+
+// signGRPCRequest signs the marshaled request to the full gRPC method with the signing
+// secret of the apparatus, adding the signature headers to the metadata of the context.
+// The returned call options send exactly the signed bytes, because marshaling is not
+// canonical and the apparatus verifies the bytes it received.
+func (u *ApparatusUtil) signGRPCRequest(
+	ctx context.Context,
+	apparatus *integrationv1.SpecApparatus,
+	namespace string,
+	method string,
+	request proto.Message,
+) (context.Context, []grpc.CallOption, error) {
+	signingSecret, err := u.getSigningSecret(apparatus, namespace)
+	if err != nil || signingSecret == nil {
+		return ctx, nil, err
+	}
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return ctx, nil, err
+	}
+	timestamp := time.Now()
+	ctx = metadata.AppendToOutgoingContext(
+		ctx,
+		protocol.TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10),
+		protocol.SignatureHeader, protocol.Sign(signingSecret, timestamp, http.MethodPost, method, body),
+	)
+	return ctx, []grpc.CallOption{grpc.ForceCodec(apparatuspb.MarshaledCodec{Body: body})}, nil
+}
+
+// grpcFailure collects diagnostics when the request to the apparatus failed
+func (u *ApparatusUtil) grpcFailure(
+	err error,
+	apparatus *integrationv1.SpecApparatus,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	uid string,
+	eventName string,
+) error {
+	return NewApparatusGRPCError(err).WithDiagnostics(u.collectDiagnostics(
+		apparatus,
+		plug,
+		socket,
+		uid,
+		eventName,
+		grpcResponseCode(err),
+		[]byte(status.Convert(err).Message()),
+	))
+}
+
+// getGRPCTarget gets the host and port of the endpoint, defaulting the port from the scheme
+func getGRPCTarget(endpoint string) (string, credentials.TransportCredentials, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", nil, err
+	}
+	if endpointURL.Host == "" {
+		return "", nil, errors.New("invalid apparatus endpoint " + endpoint)
+	}
+	if endpointURL.Scheme == "https" {
+		target := endpointURL.Host
+		if endpointURL.Port() == "" {
+			target = net.JoinHostPort(endpointURL.Hostname(), "443")
+		}
+		return target, credentials.NewTLS(&tls.Config{}), nil
+	}
+	target := endpointURL.Host
+	if endpointURL.Port() == "" {
+		target = net.JoinHostPort(endpointURL.Hostname(), "80")
+	}
+	return target, insecure.NewCredentials(), nil
+}
+
+func marshalObject(object interface{}) ([]byte, error) {
+	switch o := object.(type) {
+	case *integrationv1.Plug:
+		if o == nil {
+			return nil, nil
+		}
+	case *integrationv1.Socket:
+		if o == nil {
+			return nil, nil
+		}
+	}
+	return json.Marshal(object)
+}
+
+// grpcResponseCode maps the status of a grpc response to the equivalent http status
+func grpcResponseCode(err error) int {
+	switch status.Code(err) {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+		// the apparatus did not respond
+		return 0
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+type ApparatusGRPCError struct {
+	diagnostics string
+	err         error
+}
+
+func NewApparatusGRPCError(err error) ApparatusGRPCError {
+	return ApparatusGRPCError{
+		err: err,
+	}
+}
+
+func (e ApparatusGRPCError) Error() string {
+	return e.err.Error() + diagnosticsReference(e.diagnostics)
+}
+
+// WithDiagnostics references the configmap containing the diagnostics of the failure
+func (e ApparatusGRPCError) WithDiagnostics(diagnostics string) ApparatusGRPCError {
+	e.diagnostics = diagnostics
+	return e
+}
+
+func (e ApparatusGRPCError) Unwrap() error {
+	return e.err
+}
+
+func (e ApparatusGRPCError) Timeout() bool {
+	return status.Code(e.err) == codes.DeadlineExceeded
+}
+
+func (e ApparatusGRPCError) NotRunning() bool {
+	return status.Code(e.err) == codes.Unavailable
+}
@@ -0,0 +1,157 @@
+/**
+ * File: /util/grpc_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 16:21:05
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/apparatus"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol/apparatuspb"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	"google.golang.org/grpc"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type grpcApparatus struct {
+	apparatus.UnimplementedApparatus
+	configRequests chan *apparatus.ConfigRequest
+	eventRequests  chan *apparatus.EventRequest
+	accept         bool
+}
+
+func (a *grpcApparatus) Config(
+	ctx context.Context,
+	request *apparatus.ConfigRequest,
+) (apparatus.ConfigResponse, error) {
+	a.configRequests <- request
+	return apparatus.ConfigResponse{"plug": request.Plug.Name}, nil
+}
+
+func (a *grpcApparatus) Coupled(ctx context.Context, request *apparatus.EventRequest) error {
+	a.eventRequests <- request
+	if a.accept {
+		return apparatus.Accept("op-1", "")
+	}
+	return nil
+}
+
+var _ = Describe("ApparatusUtil with the grpc protocol", func() {
+	var server *grpc.Server
+	var listener net.Listener
+	var testApparatus *grpcApparatus
+
+	newPlug := func(uid string, events []protocol.Event) *integrationv1.Plug {
+		server = grpc.NewServer(apparatus.GRPCServerOption())
+		apparatuspb.RegisterApparatusServer(server, apparatus.NewGRPCServer(testApparatus, &apparatus.HandlerOptions{
+			Events: events,
+		}))
+		go server.Serve(listener)
+		return &integrationv1.Plug{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-plug", Namespace: "default", UID: types.UID(uid)},
+			Spec: integrationv1.PlugSpec{Apparatus: &integrationv1.SpecApparatus{
+				Endpoint: "http://" + listener.Addr().String(),
+				Protocol: integrationv1.GRPCProtocol,
+			}},
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		testApparatus = &grpcApparatus{
+			configRequests: make(chan *apparatus.ConfigRequest, 10),
+			eventRequests:  make(chan *apparatus.EventRequest, 10),
+		}
+	})
+
+	AfterEach(func() {
+		if server != nil {
+			server.Stop()
+		}
+		listener.Close()
+	})
+
+	It("sends events over grpc", func() {
+		plug := newPlug("grpc-event-uid", nil)
+		plugConfig := util.Config{"hello": "world"}
+		Expect(util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, &plugConfig, nil)).To(Succeed())
+		var eventRequest *apparatus.EventRequest
+		Expect(testApparatus.eventRequests).To(Receive(&eventRequest))
+		Expect(eventRequest.Version).To(Equal(protocol.Version2))
+		Expect(eventRequest.Event).To(Equal(protocol.CoupledEvent))
+		Expect(eventRequest.Plug.Name).To(Equal("my-plug"))
+		Expect(eventRequest.Socket).To(BeNil())
+		Expect(eventRequest.PlugConfig).To(HaveKeyWithValue("hello", "world"))
+	})
+
+	It("gets the config over grpc", func() {
+		plug := newPlug("grpc-config-uid", nil)
+		socket := &integrationv1.Socket{ObjectMeta: metav1.ObjectMeta{Name: "my-socket", Namespace: "default"}}
+		body, err := util.NewTestApparatusUtil(context.Background()).GetPlugConfig(plug, socket)
+		Expect(err).NotTo(HaveOccurred())
+		config := map[string]string{}
+		Expect(json.Unmarshal(body, &config)).To(Succeed())
+		Expect(config).To(HaveKeyWithValue("plug", "my-plug"))
+		var configRequest *apparatus.ConfigRequest
+		Expect(testApparatus.configRequests).To(Receive(&configRequest))
+		Expect(configRequest.Event).To(Equal(protocol.ConfigEvent))
+		Expect(configRequest.Socket.Name).To(Equal("my-socket"))
+	})
+
+	It("skips events the apparatus does not support", func() {
+		plug := newPlug("grpc-skip-uid", []protocol.Event{protocol.ConfigEvent})
+		Expect(util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)).To(Succeed())
+		Expect(testApparatus.eventRequests).NotTo(Receive())
+	})
+
+	It("waits for operations the apparatus accepts", func() {
+		testApparatus.accept = true
+		plug := newPlug("grpc-accept-uid", nil)
+		err := util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)
+		pendingError, ok := util.IsApparatusPending(err)
+		Expect(ok).To(BeTrue())
+		Expect(pendingError.Error()).To(ContainSubstring("op-1"))
+	})
+
+	It("reports an unreachable apparatus as not running", func() {
+		plug := newPlug("grpc-unreachable-uid", nil)
+		server.Stop()
+		server = nil
+		apparatusUtil := util.NewTestApparatusUtil(context.Background())
+		err := apparatusUtil.PlugCoupled(plug, nil, nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(apparatusUtil.NotRunning(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,240 @@
+/**
+ * File: /util/health.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 17:02:36
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol/apparatuspb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type ApparatusHealthReason string
+
+const (
+	ApparatusHealthy     ApparatusHealthReason = "ApparatusHealthy"
+	ApparatusUnhealthy   ApparatusHealthReason = "ApparatusUnhealthy"
+	ApparatusUnreachable ApparatusHealthReason = "ApparatusUnreachable"
+)
+
+// ApparatusHealth is the result of probing the health of an apparatus
+type ApparatusHealth struct {
+	Ready    bool
+	Reason   ApparatusHealthReason
+	Message  string
+	endpoint string
+	expires  time.Time
+}
+
+var apparatusHealth map[string]*ApparatusHealth = map[string]*ApparatusHealth{}
+
+var apparatusHealthMutex *sync.Mutex = &sync.Mutex{}
+
+// CheckHealth probes the health of the apparatus, caching the result for the health ttl
+func (u *ApparatusUtil) CheckHealth(
+	apparatus *integrationv1.SpecApparatus,
+	endpoint string,
+	uid string,
+	plug *integrationv1.Plug,
+) *ApparatusHealth {
+	if health := getCachedHealth(uid, endpoint); health != nil {
+		return health
+	}
+	var health *ApparatusHealth
+	if IsGRPCApparatus(apparatus) {
+		health = u.probeGRPCHealth(apparatus, endpoint)
+	} else {
+		health = u.probeHealth(apparatus, endpoint, uid, plug)
+	}
+	if health.Ready {
+		u.log.V(1).Info("apparatus is healthy", "endpoint", endpoint)
+	} else {
+		u.log.Info("apparatus is not ready", "endpoint", endpoint, "reason", health.Reason, "message", health.Message)
+	}
+	setCachedHealth(uid, endpoint, health)
+	return health
+}
+
+// ensureHealthy returns an error describing why the apparatus is not ready, so requests
+// are not sent to an unhealthy apparatus
+func (u *ApparatusUtil) ensureHealthy(
+	apparatus *integrationv1.SpecApparatus,
+	endpoint string,
+	uid string,
+	plug *integrationv1.Plug,
+) error {
+	health := u.CheckHealth(apparatus, endpoint, uid, plug)
+	if health.Ready {
+		return nil
+	}
+	return NewApparatusUnhealthyError(health)
+}
+
+func (u *ApparatusUtil) probeHealth(
+	apparatus *integrationv1.SpecApparatus,
+	endpoint string,
+	uid string,
+	plug *integrationv1.Plug,
+) *ApparatusHealth {
+	request, cancel := u.newRequest(apparatus, "healthz", 0)
+	defer cancel()
+	url := endpoint + protocol.HealthzPath
+	r, err := request.Get(url)
+	if err != nil {
+		return &ApparatusHealth{
+			Reason:  ApparatusUnreachable,
+			Message: "apparatus is unreachable: " + err.Error(),
+		}
+	}
+	if r.IsError() {
+		if r.StatusCode() == http.StatusNotFound ||
+			r.StatusCode() == http.StatusMethodNotAllowed ||
+			r.StatusCode() == http.StatusNotImplemented {
+			return &ApparatusHealth{
+				Ready:   true,
+				Reason:  ApparatusHealthy,
+				Message: "apparatus responded and does not implement " + protocol.HealthzPath,
+			}
+		}
+		RecordApparatusResponseCode(plug, uid, r.StatusCode())
+		return &ApparatusHealth{
+			Reason:  ApparatusUnhealthy,
+			Message: "apparatus is unhealthy with " + strconv.Itoa(r.StatusCode()) + " status from GET " + url,
+		}
+	}
+	return &ApparatusHealth{
+		Ready:   true,
+		Reason:  ApparatusHealthy,
+		Message: "apparatus is healthy",
+	}
+}
+
+func (u *ApparatusUtil) probeGRPCHealth(
+	apparatus *integrationv1.SpecApparatus,
+	endpoint string,
+) *ApparatusHealth {
+	client, ctx, cancel, err := u.newGRPCClient(apparatus, endpoint, "healthz")
+	if err != nil {
+		return &ApparatusHealth{
+			Reason:  ApparatusUnreachable,
+			Message: "apparatus is unreachable: " + err.Error(),
+		}
+	}
+	defer cancel()
+	response, err := client.Health(ctx, &apparatuspb.HealthRequest{})
+	if err != nil && status.Code(err) != codes.Unimplemented {
+		if status.Code(err) == codes.Unavailable || status.Code(err) == codes.DeadlineExceeded {
+			return &ApparatusHealth{
+				Reason:  ApparatusUnreachable,
+				Message: "apparatus is unreachable: " + err.Error(),
+			}
+		}
+		return &ApparatusHealth{
+			Reason:  ApparatusUnhealthy,
+			Message: "apparatus is unhealthy: " + err.Error(),
+		}
+	}
+	if response != nil && response.Status == apparatuspb.HealthResponse_STATUS_NOT_SERVING {
+		return &ApparatusHealth{
+			Reason:  ApparatusUnhealthy,
+			Message: "apparatus is not serving",
+		}
+	}
+	return &ApparatusHealth{
+		Ready:   true,
+		Reason:  ApparatusHealthy,
+		Message: "apparatus is healthy",
+	}
+}
+
+// GetApparatusHealth gets the last health probed for the apparatus of the plug or socket
+func GetApparatusHealth(uid types.UID) *ApparatusHealth {
+	apparatusHealthMutex.Lock()
+	defer apparatusHealthMutex.Unlock()
+	health, ok := apparatusHealth[string(uid)]
+	if !ok {
+		return nil
+	}
+	result := *health
+	return &result
+}
+
+func getCachedHealth(uid string, endpoint string) *ApparatusHealth {
+	apparatusHealthMutex.Lock()
+	defer apparatusHealthMutex.Unlock()
+	health, ok := apparatusHealth[uid]
+	if !ok || health.endpoint != endpoint || time.Now().After(health.expires) {
+		return nil
+	}
+	result := *health
+	return &result
+}
+
+func setCachedHealth(uid string, endpoint string, health *ApparatusHealth) {
+	apparatusHealthMutex.Lock()
+	defer apparatusHealthMutex.Unlock()
+	health.endpoint = endpoint
+	health.expires = time.Now().Add(config.ApparatusHealthTTL)
+	apparatusHealth[uid] = health
+}
+
+func forgetCachedHealth(uid string) {
+	apparatusHealthMutex.Lock()
+	defer apparatusHealthMutex.Unlock()
+	delete(apparatusHealth, uid)
+}
+
+// ApparatusUnhealthyError reports a request that was not sent because the apparatus is not ready
+type ApparatusUnhealthyError struct {
+	health ApparatusHealth
+}
+
+func NewApparatusUnhealthyError(health *ApparatusHealth) ApparatusUnhealthyError {
+	return ApparatusUnhealthyError{
+		health: *health,
+	}
+}
+
+func (e ApparatusUnhealthyError) Error() string {
+	return e.health.Message
+}
+
+func (e ApparatusUnhealthyError) Reason() ApparatusHealthReason {
+	return e.health.Reason
+}
+
+func (e ApparatusUnhealthyError) NotRunning() bool {
+	return e.health.Reason == ApparatusUnreachable
+}
@@ -32,10 +32,13 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"text/template"
 
-	"github.com/Masterminds/sprig"
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -51,7 +54,7 @@ func EnsureServiceAccount(serviceAccountName string) string {
 }
 
 func EnsureNamespacedName(
-	partialNamespacedName *integrationv1beta1.NamespacedName,
+	partialNamespacedName *integrationv1.NamespacedName,
 	defaultNamespace string,
 ) types.NamespacedName {
 	return types.NamespacedName{
@@ -80,11 +83,8 @@ func JsonToHashMap(body []byte) (map[string]string, error) {
 	return hashMap, nil
 }
 
-func WhenInWhenSlice(when integrationv1beta1.When, whenSlice *[]integrationv1beta1.When) bool {
-	if whenSlice == nil {
-		return false
-	}
-	for _, whenItem := range *whenSlice {
+func WhenInWhenSlice(when integrationv1.When, whenSlice []integrationv1.When) bool {
+	for _, whenItem := range whenSlice {
 		if when == whenItem {
 			return true
 		}
@@ -92,42 +92,150 @@ func WhenInWhenSlice(when integrationv1beta1.When, whenSlice *[]integrationv1bet
 	return false
 }
 
-func Validate(plug *integrationv1beta1.Plug, socket *integrationv1beta1.Socket) error {
-	if socket.Spec.Validation == nil {
-		return nil
-	}
-	if socket.Spec.Validation.NamespaceBlacklist != nil {
-		for _, namespace := range socket.Spec.Validation.NamespaceBlacklist {
-			match, _ := regexp.MatchString(namespace, plug.Namespace)
-			if match {
-				return fmt.Errorf("namespace %s is blacklisted", plug.Namespace)
-			}
-		}
-	}
-	if socket.Spec.Validation.NamespaceWhitelist != nil {
-		for _, namespace := range socket.Spec.Validation.NamespaceWhitelist {
-			match, _ := regexp.MatchString(namespace, plug.Namespace)
-			if match {
-				return nil
-			}
-		}
-		return fmt.Errorf("namespace %s is not whitelisted", plug.Namespace)
-	}
-	return nil
+func Template(
+	data *map[string]interface{},
+	templateValue string,
+	funcMaps ...template.FuncMap,
+) (string, error) {
+	return executeTemplate(data, templateValue, false, funcMaps...)
 }
 
-func Template(
+// StrictTemplate renders the template like Template but fails when the template references
+// a missing key
+func StrictTemplate(
+	data *map[string]interface{},
+	templateValue string,
+	funcMaps ...template.FuncMap,
+) (string, error) {
+	return executeTemplate(data, templateValue, true, funcMaps...)
+}
+
+func executeTemplate(
 	data *map[string]interface{},
 	templateValue string,
+	strict bool,
+	funcMaps ...template.FuncMap,
 ) (string, error) {
-	t, err := template.New("").Funcs(sprig.TxtFuncMap()).Delims("{%", "%}").Parse(templateValue)
+	funcMap := mergeFuncMaps(funcMaps...)
+	t, err := parseTemplate(templateValue, strict, funcMap)
 	if err != nil {
-		return "", err
+		return "", NewTemplateError(err).WithTemplate(*data, templateValue)
+	}
+	if len(funcMap) > 0 {
+		// the cached template is shared, so the functions of this render are bound to a clone
+		t, err = t.Clone()
+		if err != nil {
+			return "", err
+		}
+		t.Funcs(funcMap)
 	}
 	var buff bytes.Buffer
 	err = t.Execute(&buff, data)
 	if err != nil {
-		return "", err
+		return "", NewTemplateError(err).WithTemplate(*data, templateValue)
 	}
 	return buff.String(), nil
 }
+
+// TemplateFuncMap gets the sprig template functions extended with the function maps
+func TemplateFuncMap(funcMaps ...template.FuncMap) template.FuncMap {
+	return mergeFuncMaps(append([]template.FuncMap{sprigFuncMap}, funcMaps...)...)
+}
+
+func mergeFuncMaps(funcMaps ...template.FuncMap) template.FuncMap {
+	funcMap := template.FuncMap{}
+	for _, extraFuncMap := range funcMaps {
+		for name, fn := range extraFuncMap {
+			funcMap[name] = fn
+		}
+	}
+	return funcMap
+}
+
+// PlugStrictTemplates reports whether the templates of the plug fail on missing keys
+func PlugStrictTemplates(plug *integrationv1.Plug) bool {
+	if plug == nil || plug.Spec.StrictTemplates == nil {
+		return config.StrictTemplates
+	}
+	return *plug.Spec.StrictTemplates
+}
+
+// SocketStrictTemplates reports whether the templates of the socket fail on missing keys
+func SocketStrictTemplates(socket *integrationv1.Socket) bool {
+	if socket == nil || socket.Spec.StrictTemplates == nil {
+		return config.StrictTemplates
+	}
+	return *socket.Spec.StrictTemplates
+}
+
+// templateErrorLocation adds the location to the error when it is a template error
+func templateErrorLocation(err error, location string) error {
+	if templateError, ok := err.(TemplateError); ok {
+		return templateError.WithLocation(location)
+	}
+	return err
+}
+
+var templateErrorLineRegex = regexp.MustCompile(`(?:template: [^:]*|template\.jsonnet|template\.cue):(\d+)`)
+
+type TemplateError struct {
+	err      error
+	keys     []string
+	line     int
+	location string
+	source   string
+}
+
+func NewTemplateError(err error) TemplateError {
+	return TemplateError{
+		err: err,
+	}
+}
+
+// WithTemplate adds the line of the template that failed and the top level keys of the
+// template data to the error
+func (e TemplateError) WithTemplate(data map[string]interface{}, templateValue string) TemplateError {
+	e.keys = make([]string, 0, len(data))
+	for key := range data {
+		e.keys = append(e.keys, key)
+	}
+	sort.Strings(e.keys)
+	e.line = 0
+	e.source = ""
+	if match := templateErrorLineRegex.FindStringSubmatch(e.err.Error()); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		lines := strings.Split(templateValue, "\n")
+		if line > 0 && line <= len(lines) {
+			e.line = line
+			e.source = strings.TrimSpace(lines[line-1])
+			if len(e.source) > 120 {
+				e.source = e.source[:120] + "..."
+			}
+		}
+	}
+	return e
+}
+
+// WithLocation adds where the template is defined, such as the index of a resource, to the error
+func (e TemplateError) WithLocation(location string) TemplateError {
+	e.location = location
+	return e
+}
+
+func (e TemplateError) Error() string {
+	message := e.err.Error()
+	if e.line > 0 {
+		message = "line " + strconv.Itoa(e.line) + " `" + e.source + "`: " + message
+	}
+	if e.location != "" {
+		message = e.location + ": " + message
+	}
+	if len(e.keys) > 0 {
+		message += " (available keys: " + strings.Join(e.keys, ", ") + ")"
+	}
+	return message
+}
+
+func (e TemplateError) Unwrap() error {
+	return e.err
+}
@@ -48,8 +48,27 @@ type KubectlUtil struct {
 	cfg *rest.Config
 }
 
+type restConfigKey struct{}
+
+// WithRestConfig makes the utils created with the returned context talk to the api server of the
+// config instead of the api server of the process
+func WithRestConfig(ctx context.Context, cfg *rest.Config) context.Context {
+	return context.WithValue(ctx, restConfigKey{}, cfg)
+}
+
+// GetRestConfig gets a copy of the config of the api server the utils created with the context
+// talk to
+func GetRestConfig(ctx context.Context) *rest.Config {
+	if ctx != nil {
+		if cfg, ok := ctx.Value(restConfigKey{}).(*rest.Config); ok {
+			return rest.CopyConfig(cfg)
+		}
+	}
+	return ctrl.GetConfigOrDie()
+}
+
 func NewKubectlUtil(ctx context.Context, namespace string, serviceAccountName string) *KubectlUtil {
-	cfg := ctrl.GetConfigOrDie()
+	cfg := GetRestConfig(ctx)
 	cfg.Impersonate = rest.ImpersonationConfig{
 		UserName: fmt.Sprintf("system:serviceaccount:%s:%s", namespace, Default(serviceAccountName, "default")),
 	}
@@ -121,6 +140,14 @@ func (u *KubectlUtil) Get(body []byte) (*unstructured.Unstructured, error) {
 	return dr.Get(u.ctx, obj.GetName(), metav1.GetOptions{})
 }
 
+func (u *KubectlUtil) List(body []byte, options metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	dr, _, err := u.prepareDynamic(body)
+	if err != nil {
+		return nil, err
+	}
+	return dr.List(u.ctx, options)
+}
+
 // https://ymmt2005.hatenablog.com/entry/2020/04/14/An_example_of_using_dynamic_client_of_k8s.io/client-go
 func (u *KubectlUtil) prepareDynamic(resource []byte) (dynamic.ResourceInterface, *unstructured.Unstructured, error) {
 	// 1. Prepare a RESTMapper to find GVR
@@ -0,0 +1,106 @@
+/**
+ * File: /util/lookup.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 06:31:08
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"text/template"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Lookup reads resources from inside templates through the kubectl util of the plug or socket,
+// memoizing the results for the duration of a render
+type Lookup struct {
+	kubectlUtil *KubectlUtil
+	namespace   string
+	results     map[string]map[string]interface{}
+}
+
+func NewLookup(kubectlUtil *KubectlUtil, namespace string) *Lookup {
+	return &Lookup{
+		kubectlUtil: kubectlUtil,
+		namespace:   namespace,
+		results:     map[string]map[string]interface{}{},
+	}
+}
+
+// FuncMap gets the lookup template function
+func (l *Lookup) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"lookup": l.lookup,
+	}
+}
+
+// lookup gets the resource with the name or a list of the resources when the name is empty,
+// returning an empty map when the resource does not exist
+func (l *Lookup) lookup(apiVersion string, kind string, namespace string, name string) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = l.namespace
+	} else if namespace != l.namespace {
+		return nil, errors.New("lookup namespace " + namespace + " must be " + l.namespace)
+	}
+	key := apiVersion + "/" + kind + "/" + namespace + "/" + name
+	if result, ok := l.results[key]; ok {
+		return result, nil
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]string{
+			"name":      name,
+			"namespace": namespace,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if name == "" {
+		list, err := l.kubectlUtil.List(body, metav1.ListOptions{})
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return nil, err
+			}
+		} else {
+			result = list.UnstructuredContent()
+		}
+	} else {
+		obj, err := l.kubectlUtil.Get(body)
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return nil, err
+			}
+		} else {
+			result = obj.Object
+		}
+	}
+	l.results[key] = result
+	return result, nil
+}
@@ -0,0 +1,100 @@
+/**
+ * File: /util/lookup_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 06:48:51
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Lookup", func() {
+	var server *fakeAPIServer
+	var kubectlUtil *util.KubectlUtil
+
+	BeforeEach(func() {
+		server = newFakeAPIServer(
+			metav1.APIResource{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"get", "list"}},
+		)
+		server.add("configmaps", map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "settings", "namespace": "default"},
+			"data":       map[string]interface{}{"color": "blue"},
+		})
+		kubectlUtil = util.NewTestKubectlUtil(context.Background(), server.config())
+	})
+
+	render := func(lookup *util.Lookup, templateValue string) (string, error) {
+		data := map[string]interface{}{}
+		return util.Template(&data, templateValue, lookup.FuncMap())
+	}
+
+	It("should get the full resource", func() {
+		result, err := render(
+			util.NewLookup(kubectlUtil, "default"),
+			`{% (lookup "v1" "ConfigMap" "" "settings").data.color %}`,
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("blue"))
+	})
+
+	It("should list the resources when the name is empty", func() {
+		result, err := render(
+			util.NewLookup(kubectlUtil, "default"),
+			`{% range (lookup "v1" "ConfigMap" "default" "").items %}{% .metadata.name %}{% end %}`,
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("settings"))
+	})
+
+	It("should return an empty map when the resource does not exist", func() {
+		result, err := render(
+			util.NewLookup(kubectlUtil, "default"),
+			`{% if not (lookup "v1" "ConfigMap" "" "missing") %}missing{% end %}`,
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("missing"))
+	})
+
+	It("should memoize results for the render", func() {
+		lookup := util.NewLookup(kubectlUtil, "default")
+		for i := 0; i < 2; i++ {
+			_, err := render(lookup, `{% lookup "v1" "ConfigMap" "" "settings" %}{% lookup "v1" "ConfigMap" "default" "settings" %}`)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(server.requestCount("/api/v1/namespaces/default/configmaps/settings")).To(Equal(1))
+	})
+
+	It("should reject other namespaces", func() {
+		_, err := render(util.NewLookup(kubectlUtil, "default"), `{% lookup "v1" "ConfigMap" "kube-system" "settings" %}`)
+		Expect(err).To(MatchError(ContainSubstring("lookup namespace kube-system must be default")))
+	})
+})
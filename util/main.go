@@ -38,22 +38,40 @@ var (
 type ConditionCoupledReason string
 
 const (
+	AwaitingApproval  ConditionCoupledReason = "AwaitingApproval"
 	CouplingInProcess ConditionCoupledReason = "CouplingInProcess"
 	CouplingSucceeded ConditionCoupledReason = "CouplingSucceeded"
 	Error             ConditionCoupledReason = "Error"
+	NotPermitted      ConditionCoupledReason = "NotPermitted"
 	PlugCreated       ConditionCoupledReason = "PlugCreated"
 	SocketCoupled     ConditionCoupledReason = "SocketCoupled"
 	SocketCreated     ConditionCoupledReason = "SocketCreated"
 	SocketEmpty       ConditionCoupledReason = "SocketEmpty"
 	SocketNotCreated  ConditionCoupledReason = "SocketNotCreated"
+	TemplateInvalid   ConditionCoupledReason = "TemplateInvalid"
 	UpdatingInProcess ConditionCoupledReason = "UpdatingInProcess"
 )
 
 type ConditionType string
 
 const (
-	ConditionTypeCoupled ConditionType = "Coupled"
-	ConditionTypeFailed  ConditionType = "Failed"
+	ConditionTypeApparatusReachable ConditionType = "ApparatusReachable"
+	ConditionTypeConfigResolved     ConditionType = "ConfigResolved"
+	ConditionTypeCoupled            ConditionType = "Coupled"
+	ConditionTypeFailed             ConditionType = "Failed"
+	ConditionTypeReady              ConditionType = "Ready"
+	ConditionTypeResourcesApplied   ConditionType = "ResourcesApplied"
+)
+
+type WarningReason string
+
+const (
+	ApparatusFailed  WarningReason = "ApparatusFailed"
+	ApplyConflict    WarningReason = "ApplyConflict"
+	ApplyFailed      WarningReason = "ApplyFailed"
+	ReconcileFailed  WarningReason = "ReconcileFailed"
+	TemplateFailed   WarningReason = "TemplateFailed"
+	ValidationFailed WarningReason = "ValidationFailed"
 )
 
 type DeferredResourceConditionResolvedReason string
@@ -0,0 +1,296 @@
+/**
+ * File: /util/oci.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 09:04:52
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/lru"
+)
+
+var ociManifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+var ociChallengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// ociArchives caches the pulled archives, which can be as large as the resource source max
+// size, so only the most recently used are kept
+var ociArchives = lru.New(config.ResourceSourceCacheSize)
+
+type ociArchive struct {
+	data    []byte
+	expires time.Time
+}
+
+type ociReference struct {
+	registry   string
+	repository string
+	reference  string
+}
+
+type ociManifest struct {
+	Layers []struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+		Size      int64  `json:"size"`
+	} `json:"layers"`
+}
+
+// pullOCIArchive pulls the archive held by the first tar+gzip layer of the oci artifact,
+// caching it for the resource source cache ttl
+func (u *ResourceUtil) pullOCIArchive(source *integrationv1.SourceOCI, namespace string, kubectlUtil *KubectlUtil) ([]byte, error) {
+	ref, err := parseOCIReference(source.Ref)
+	if err != nil {
+		return nil, err
+	}
+	// artifacts pulled with credentials are only shared with the same pull secret
+	cacheKey := source.Ref
+	if source.PullSecretName != "" {
+		cacheKey = namespace + "/" + source.PullSecretName + "/" + source.Ref
+	}
+	if value, ok := ociArchives.Get(cacheKey); ok {
+		if archive := value.(ociArchive); time.Now().Before(archive.expires) {
+			return archive.data, nil
+		}
+		ociArchives.Remove(cacheKey)
+	}
+	username, password, err := u.getRegistryCredentials(source, ref.registry, namespace, kubectlUtil)
+	if err != nil {
+		return nil, err
+	}
+	scheme := "https://"
+	if source.Insecure {
+		scheme = "http://"
+	}
+	baseURL := scheme + ref.registry + "/v2/" + ref.repository
+	client := resty.New().SetTimeout(time.Minute)
+	if username != "" {
+		client.SetBasicAuth(username, password)
+	}
+	body, err := ociGet(client, baseURL+"/manifests/"+ref.reference, strings.Join(ociManifestMediaTypes, ", "))
+	if err != nil {
+		return nil, err
+	}
+	manifest := ociManifest{}
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, err
+	}
+	if len(manifest.Layers) == 0 {
+		return nil, errors.New("oci artifact " + source.Ref + " has no layers")
+	}
+	layer := manifest.Layers[0]
+	for _, l := range manifest.Layers {
+		if strings.HasSuffix(l.MediaType, "tar+gzip") {
+			layer = l
+			break
+		}
+	}
+	if layer.Size > int64(config.ResourceSourceMaxSize) {
+		return nil, errors.New("oci artifact " + source.Ref + " is larger than " +
+			strconv.Itoa(config.ResourceSourceMaxSize) + " bytes")
+	}
+	data, err := ociGet(client, baseURL+"/blobs/"+layer.Digest, "")
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if layer.Digest != "sha256:"+hex.EncodeToString(sum[:]) {
+		return nil, errors.New("oci artifact " + source.Ref + " layer does not match digest " + layer.Digest)
+	}
+	ociArchives.Add(cacheKey, ociArchive{
+		data:    data,
+		expires: time.Now().Add(config.ResourceSourceCacheTTL),
+	})
+	return data, nil
+}
+
+// getRegistryCredentials gets the credentials of the registry from the docker config pull secret
+func (u *ResourceUtil) getRegistryCredentials(
+	source *integrationv1.SourceOCI,
+	registry string,
+	namespace string,
+	kubectlUtil *KubectlUtil,
+) (string, string, error) {
+	if source.PullSecretName == "" {
+		return "", "", nil
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]string{
+			"name":      source.PullSecretName,
+			"namespace": namespace,
+		},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := kubectlUtil.Get(body)
+	if err != nil {
+		return "", "", err
+	}
+	encodedConfig, _, _ := unstructured.NestedString(secret.Object, "data", ".dockerconfigjson")
+	dockerConfig, err := base64.StdEncoding.DecodeString(encodedConfig)
+	if err != nil {
+		return "", "", err
+	}
+	auths := struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}{}
+	if err := json.Unmarshal(dockerConfig, &auths); err != nil {
+		return "", "", err
+	}
+	for server, auth := range auths.Auths {
+		server = strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+		if strings.TrimSuffix(server, "/") != registry {
+			continue
+		}
+		if auth.Auth == "" {
+			return auth.Username, auth.Password, nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", err
+		}
+		username, password, _ := strings.Cut(string(decoded), ":")
+		return username, password, nil
+	}
+	return "", "", errors.New("pull secret " + source.PullSecretName + " has no credentials for " + registry)
+}
+
+// ociGet gets the body of the url, requesting a bearer token when the registry challenges the
+// request, and fails when the body is larger than the resource source max size, since the
+// registry is not trusted to report the real size in its manifest
+func ociGet(client *resty.Client, url string, accept string) ([]byte, error) {
+	response, err := newOCIRequest(client.R(), accept).Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode() == http.StatusUnauthorized {
+		response.RawBody().Close()
+		challenge := response.Header().Get("WWW-Authenticate")
+		if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+			return nil, errors.New("registry rejected the credentials for " + url)
+		}
+		params := map[string]string{}
+		for _, match := range ociChallengeParamRegex.FindAllStringSubmatch(challenge, -1) {
+			params[match[1]] = match[2]
+		}
+		tokenRequest := client.R()
+		if params["service"] != "" {
+			tokenRequest.SetQueryParam("service", params["service"])
+		}
+		if params["scope"] != "" {
+			tokenRequest.SetQueryParam("scope", params["scope"])
+		}
+		tokenResponse, err := tokenRequest.Get(params["realm"])
+		if err != nil {
+			return nil, err
+		}
+		if tokenResponse.IsError() {
+			return nil, errors.New("failed to get registry token from " + params["realm"] + " with status " +
+				strconv.Itoa(tokenResponse.StatusCode()))
+		}
+		token := struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}{}
+		if err := json.Unmarshal(tokenResponse.Body(), &token); err != nil {
+			return nil, err
+		}
+		request := resty.New().SetTimeout(time.Minute).R().SetAuthToken(Default(token.Token, token.AccessToken))
+		response, err = newOCIRequest(request, accept).Get(url)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer response.RawBody().Close()
+	if response.IsError() {
+		return nil, errors.New("failed to get " + url + " with status " + strconv.Itoa(response.StatusCode()))
+	}
+	body, err := io.ReadAll(io.LimitReader(response.RawBody(), int64(config.ResourceSourceMaxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > config.ResourceSourceMaxSize {
+		return nil, errors.New("response from " + url + " is larger than " +
+			strconv.Itoa(config.ResourceSourceMaxSize) + " bytes")
+	}
+	return body, nil
+}
+
+// newOCIRequest prepares the request to read the body of the response itself
+func newOCIRequest(request *resty.Request, accept string) *resty.Request {
+	request.SetDoNotParseResponse(true)
+	if accept != "" {
+		request.SetHeader("Accept", accept)
+	}
+	return request
+}
+
+// parseOCIReference parses a reference such as oci://registry.example.com/charts/app:1.0.0,
+// defaulting to the latest tag
+func parseOCIReference(ref string) (ociReference, error) {
+	ref = strings.TrimPrefix(ref, "oci://")
+	registry, repository, ok := strings.Cut(ref, "/")
+	if !ok || registry == "" || repository == "" {
+		return ociReference{}, errors.New("oci reference " + ref + " must include a registry and a repository")
+	}
+	reference := "latest"
+	if i := strings.Index(repository, "@"); i != -1 {
+		reference = repository[i+1:]
+		repository = repository[:i]
+	} else if i := strings.LastIndex(repository, ":"); i != -1 && !strings.Contains(repository[i:], "/") {
+		reference = repository[i+1:]
+		repository = repository[:i]
+	}
+	return ociReference{
+		registry:   registry,
+		repository: repository,
+		reference:  reference,
+	}, nil
+}
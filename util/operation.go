@@ -0,0 +1,346 @@
+/**
+ * File: /util/operation.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 11:48:22
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const apparatusCallbackPath = "/apparatus/operations/"
+
+const (
+	plugOperationOwner   = "plugs"
+	socketOperationOwner = "sockets"
+)
+
+// PlugOperationEvents triggers a reconcile of the plug that started an operation when the operation completes
+var PlugOperationEvents chan event.GenericEvent = make(chan event.GenericEvent, 100)
+
+// SocketOperationEvents triggers a reconcile of the socket that started an operation when the operation completes
+var SocketOperationEvents chan event.GenericEvent = make(chan event.GenericEvent, 100)
+
+// ApparatusCallbackURL returns the url the apparatus calls when the operation of the plug or
+// socket owning the apparatus completes
+func ApparatusCallbackURL(owner string, namespace string, name string, callbackID string) string {
+	if config.ApparatusCallbackURL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(config.ApparatusCallbackURL, "/") + apparatusCallbackPath +
+		owner + "/" + namespace + "/" + name + "/" + callbackID
+}
+
+// operationOwner identifies the plug or socket owning the apparatus with the uid, whose status
+// holds the pending operations of the apparatus
+type operationOwner struct {
+	kind       string
+	name       string
+	namespace  string
+	operations *[]integrationv1.PendingOperation
+	plug       types.UID
+}
+
+func getOperationOwner(
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	uid string,
+) *operationOwner {
+	if plug != nil && (string(plug.UID) == uid || socket == nil) {
+		return &operationOwner{
+			kind:       plugOperationOwner,
+			name:       plug.Name,
+			namespace:  plug.Namespace,
+			operations: &plug.Status.PendingOperations,
+		}
+	}
+	if socket == nil {
+		return &operationOwner{operations: &[]integrationv1.PendingOperation{}}
+	}
+	owner := &operationOwner{
+		kind:       socketOperationOwner,
+		name:       socket.Name,
+		namespace:  socket.Namespace,
+		operations: &socket.Status.PendingOperations,
+	}
+	if plug != nil {
+		owner.plug = plug.UID
+	}
+	return owner
+}
+
+func (o *operationOwner) callbackURL(callbackID string) string {
+	return ApparatusCallbackURL(o.kind, o.namespace, o.name, callbackID)
+}
+
+// get returns the pending operation of the event
+func (o *operationOwner) get(eventName string) *integrationv1.PendingOperation {
+	for i := range *o.operations {
+		operation := &(*o.operations)[i]
+		if operation.Event == eventName && operation.Plug == o.plug {
+			return operation
+		}
+	}
+	return nil
+}
+
+// start records an operation the apparatus accepted for the event
+func (o *operationOwner) start(
+	id string,
+	callbackID string,
+	apparatus *integrationv1.SpecApparatus,
+	eventName string,
+	statusURL string,
+) *integrationv1.PendingOperation {
+	completion := integrationv1.PollCompletion
+	if apparatus.Async != nil && apparatus.Async.Completion == integrationv1.CallbackCompletion &&
+		config.ApparatusCallbackURL != "" {
+		completion = integrationv1.CallbackCompletion
+	}
+	o.forget(eventName)
+	*o.operations = append(*o.operations, integrationv1.PendingOperation{
+		Event:      eventName,
+		Plug:       o.plug,
+		ID:         id,
+		CallbackID: callbackID,
+		Completion: completion,
+		StatusURL:  statusURL,
+		Deadline:   metav1.NewTime(time.Now().Add(getAsyncTimeout(apparatus))),
+		Status:     string(protocol.OperationPending),
+	})
+	return o.get(eventName)
+}
+
+// forget removes the operation of the event once it is resolved
+func (o *operationOwner) forget(eventName string) {
+	operations := []integrationv1.PendingOperation{}
+	for _, operation := range *o.operations {
+		if operation.Event != eventName || operation.Plug != o.plug {
+			operations = append(operations, operation)
+		}
+	}
+	if len(operations) == 0 {
+		operations = nil
+	}
+	*o.operations = operations
+}
+
+// CompleteApparatusOperation resolves the pending operation with the callback id in the status of
+// the plug or socket and triggers a reconcile of it without blocking
+func CompleteApparatusOperation(
+	ctx context.Context,
+	c client.Client,
+	owner string,
+	namespacedName types.NamespacedName,
+	callbackID string,
+	status protocol.OperationStatus,
+	message string,
+) (bool, error) {
+	var obj client.Object
+	var operations func() []integrationv1.PendingOperation
+	switch owner {
+	case plugOperationOwner:
+		plug := &integrationv1.Plug{}
+		obj = plug
+		operations = func() []integrationv1.PendingOperation { return plug.Status.PendingOperations }
+	case socketOperationOwner:
+		socket := &integrationv1.Socket{}
+		obj = socket
+		operations = func() []integrationv1.PendingOperation { return socket.Status.PendingOperations }
+	default:
+		return false, nil
+	}
+	found := false
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		found = false
+		if err := c.Get(ctx, namespacedName, obj); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		for i, operation := range operations() {
+			if operation.CallbackID != callbackID {
+				continue
+			}
+			found = true
+			if operation.Status != string(protocol.OperationPending) {
+				return nil
+			}
+			operations()[i].Status = string(status)
+			operations()[i].Message = message
+			return c.Status().Update(ctx, obj)
+		}
+		return nil
+	}); err != nil || !found {
+		return false, err
+	}
+	events := SocketOperationEvents
+	if owner == plugOperationOwner {
+		events = PlugOperationEvents
+	}
+	select {
+	case events <- event.GenericEvent{Object: obj}:
+	default:
+		// the pending operation is still picked up when the owner requeues at the poll interval
+		ctrl.Log.WithName("util.CompleteApparatusOperation").Info(
+			"skipped reconcile trigger because the operation events are full",
+			"callbackId", callbackID,
+		)
+	}
+	return true, nil
+}
+
+// NewApparatusCallbackHandler serves the callbacks of apparatus operations, recording the
+// completion in the status of the plug or socket waiting for the operation
+func NewApparatusCallbackHandler(c client.Client) http.Handler {
+	log := ctrl.Log.WithName("util.ApparatusCallbackHandler")
+	mux := http.NewServeMux()
+	mux.HandleFunc(apparatusCallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, apparatusCallbackPath), "/")
+		if len(parts) != 4 {
+			http.Error(w, "operation not found", http.StatusNotFound)
+			return
+		}
+		owner, callbackID := parts[0], parts[3]
+		namespacedName := types.NamespacedName{Namespace: parts[1], Name: parts[2]}
+		body := protocol.OperationStatusResponse{}
+		r.Body = http.MaxBytesReader(w, r.Body, int64(config.ApparatusCallbackMaxSize))
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if body.Status != protocol.OperationSucceeded && body.Status != protocol.OperationFailed {
+			http.Error(w, "status must be succeeded or failed", http.StatusBadRequest)
+			return
+		}
+		found, err := CompleteApparatusOperation(
+			r.Context(),
+			c,
+			owner,
+			namespacedName,
+			callbackID,
+			body.Status,
+			body.Message,
+		)
+		if err != nil {
+			log.Error(err, "failed to complete apparatus operation", "callbackId", callbackID)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.Error(w, "operation not found", http.StatusNotFound)
+			return
+		}
+		log.Info("apparatus operation completed", "callbackId", callbackID, "status", body.Status)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
+
+// ApparatusPendingError reports an apparatus operation that has not completed yet
+type ApparatusPendingError struct {
+	operationID  string
+	requeueAfter time.Duration
+}
+
+func NewApparatusPendingError(operationID string, requeueAfter time.Duration) ApparatusPendingError {
+	return ApparatusPendingError{
+		operationID:  operationID,
+		requeueAfter: requeueAfter,
+	}
+}
+
+func (e ApparatusPendingError) Error() string {
+	return "waiting for apparatus operation " + e.operationID
+}
+
+func (e ApparatusPendingError) RequeueAfter() time.Duration {
+	return e.requeueAfter
+}
+
+// IsApparatusPending reports whether the error is an apparatus operation that has not completed yet
+func IsApparatusPending(err error) (ApparatusPendingError, bool) {
+	var pendingError ApparatusPendingError
+	ok := errors.As(err, &pendingError)
+	return pendingError, ok
+}
+
+func getAsyncPollInterval(apparatus *integrationv1.SpecApparatus) time.Duration {
+	if apparatus.Async != nil && apparatus.Async.PollInterval != 0 {
+		return time.Second * time.Duration(apparatus.Async.PollInterval)
+	}
+	return time.Second * 10
+}
+
+func getAsyncTimeout(apparatus *integrationv1.SpecApparatus) time.Duration {
+	if apparatus.Async != nil && apparatus.Async.Timeout != 0 {
+		return time.Second * time.Duration(apparatus.Async.Timeout)
+	}
+	return time.Hour
+}
+
+// ApparatusOperationError reports an apparatus operation that failed or timed out
+type ApparatusOperationError struct {
+	diagnostics string
+	err         error
+}
+
+func NewApparatusOperationError(err error) ApparatusOperationError {
+	return ApparatusOperationError{
+		err: err,
+	}
+}
+
+func (e ApparatusOperationError) Error() string {
+	return e.err.Error() + diagnosticsReference(e.diagnostics)
+}
+
+// WithDiagnostics references the configmap containing the diagnostics of the failure
+func (e ApparatusOperationError) WithDiagnostics(diagnostics string) ApparatusOperationError {
+	e.diagnostics = diagnostics
+	return e
+}
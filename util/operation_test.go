@@ -0,0 +1,245 @@
+/**
+ * File: /util/operation_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 21:24:09
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"gitlab.com/bitspur/rock8s/integration-operator/protocol"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("Apparatus operations", func() {
+	var server *httptest.Server
+	var events chan []byte
+	var operationStatus atomic.Value
+
+	newPlug := func(uid string, async *integrationv1.ApparatusAsync) *integrationv1.Plug {
+		return &integrationv1.Plug{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-plug", Namespace: "default", UID: types.UID(uid)},
+			Spec: integrationv1.PlugSpec{Apparatus: &integrationv1.SpecApparatus{
+				Endpoint: server.URL,
+				Async:    async,
+			}},
+		}
+	}
+
+	BeforeEach(func() {
+		events = make(chan []byte, 10)
+		operationStatus.Store(protocol.OperationPending)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/coupled":
+				body, _ := io.ReadAll(r.Body)
+				events <- body
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte(`{"operationId":"op-1"}`))
+			case "/operations/op-1":
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(protocol.OperationStatusResponse{
+					Status:  operationStatus.Load().(protocol.OperationStatus),
+					Message: "provisioned",
+				})
+			default:
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should poll the operation kept in the status of the plug", func() {
+		plug := newPlug("poll-uid", nil)
+		err := util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)
+		_, pending := util.IsApparatusPending(err)
+		Expect(pending).To(BeTrue())
+		Expect(events).To(HaveLen(1))
+		Expect(plug.Status.PendingOperations).To(HaveLen(1))
+		operation := plug.Status.PendingOperations[0]
+		Expect(operation.Event).To(Equal("coupled"))
+		Expect(operation.ID).To(Equal("op-1"))
+		Expect(operation.Completion).To(Equal(integrationv1.PollCompletion))
+		Expect(operation.Deadline.Time).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+
+		// a restarted operator resumes from the status instead of sending the event again
+		plug = plug.DeepCopy()
+		err = util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)
+		_, pending = util.IsApparatusPending(err)
+		Expect(pending).To(BeTrue())
+		Expect(events).To(HaveLen(1))
+
+		operationStatus.Store(protocol.OperationSucceeded)
+		Expect(util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)).To(Succeed())
+		Expect(events).To(HaveLen(1))
+		Expect(plug.Status.PendingOperations).To(BeEmpty())
+	})
+
+	It("should fail operations past their deadline", func() {
+		plug := newPlug("deadline-uid", nil)
+		plug.Status.PendingOperations = []integrationv1.PendingOperation{{
+			Event:      "coupled",
+			ID:         "op-1",
+			Completion: integrationv1.CallbackCompletion,
+			Deadline:   metav1.NewTime(time.Now().Add(-time.Second)),
+			Status:     string(protocol.OperationPending),
+		}}
+		err := util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)
+		Expect(err).To(MatchError(ContainSubstring("timed out waiting for apparatus operation op-1")))
+		Expect(plug.Status.PendingOperations).To(BeEmpty())
+		Expect(events).To(BeEmpty())
+	})
+
+	Context("with callbacks", func() {
+		var fakeClient client.Client
+		var callbackServer *httptest.Server
+
+		BeforeEach(func() {
+			previousCallbackURL := config.ApparatusCallbackURL
+			config.ApparatusCallbackURL = "http://integration-operator.system.svc:8082"
+			DeferCleanup(func() {
+				config.ApparatusCallbackURL = previousCallbackURL
+			})
+			scheme := runtime.NewScheme()
+			Expect(integrationv1.AddToScheme(scheme)).To(Succeed())
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			callbackServer = httptest.NewServer(util.NewApparatusCallbackHandler(fakeClient))
+			DeferCleanup(callbackServer.Close)
+		})
+
+		callback := func(url string, status protocol.OperationStatus) int {
+			url = strings.Replace(url, config.ApparatusCallbackURL, callbackServer.URL, 1)
+			body, err := json.Marshal(protocol.OperationStatusResponse{Status: status, Message: "provisioned"})
+			Expect(err).NotTo(HaveOccurred())
+			response, err := http.Post(url, "application/json", bytes.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+			defer response.Body.Close()
+			return response.StatusCode
+		}
+
+		It("should record the callback in the status of the plug", func() {
+			plug := newPlug("callback-uid", &integrationv1.ApparatusAsync{
+				Completion: integrationv1.CallbackCompletion,
+			})
+			Expect(fakeClient.Create(context.Background(), plug)).To(Succeed())
+			err := util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)
+			_, pending := util.IsApparatusPending(err)
+			Expect(pending).To(BeTrue())
+			Expect(plug.Status.PendingOperations).To(HaveLen(1))
+			Expect(plug.Status.PendingOperations[0].Completion).To(Equal(integrationv1.CallbackCompletion))
+			Expect(fakeClient.Status().Update(context.Background(), plug)).To(Succeed())
+			eventRequest := map[string]interface{}{}
+			Expect(json.Unmarshal(<-events, &eventRequest)).To(Succeed())
+			callbackURL, _ := eventRequest["callbackUrl"].(string)
+			Expect(callbackURL).To(HavePrefix(config.ApparatusCallbackURL + "/apparatus/operations/plugs/default/my-plug/"))
+
+			Expect(callback(callbackURL+"-unknown", protocol.OperationSucceeded)).To(Equal(http.StatusNotFound))
+			Expect(callback(callbackURL, protocol.OperationSucceeded)).To(Equal(http.StatusNoContent))
+			Eventually(util.PlugOperationEvents).Should(Receive())
+
+			plug = &integrationv1.Plug{}
+			Expect(fakeClient.Get(
+				context.Background(),
+				types.NamespacedName{Name: "my-plug", Namespace: "default"},
+				plug,
+			)).To(Succeed())
+			Expect(plug.Status.PendingOperations).To(HaveLen(1))
+			Expect(plug.Status.PendingOperations[0].Status).To(Equal(string(protocol.OperationSucceeded)))
+			Expect(util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)).To(Succeed())
+			Expect(plug.Status.PendingOperations).To(BeEmpty())
+			Expect(events).To(BeEmpty())
+		})
+
+		It("should reject oversized callbacks", func() {
+			body, err := json.Marshal(protocol.OperationStatusResponse{
+				Status:  protocol.OperationSucceeded,
+				Message: strings.Repeat("x", config.ApparatusCallbackMaxSize),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			response, err := http.Post(
+				callbackServer.URL+"/apparatus/operations/plugs/default/my-plug/callback-id",
+				"application/json",
+				bytes.NewReader(body),
+			)
+			Expect(err).NotTo(HaveOccurred())
+			defer response.Body.Close()
+			Expect(response.StatusCode).To(Equal(http.StatusRequestEntityTooLarge))
+		})
+
+		It("should not block when the operation events are full", func() {
+			plug := newPlug("full-uid", &integrationv1.ApparatusAsync{
+				Completion: integrationv1.CallbackCompletion,
+			})
+			Expect(fakeClient.Create(context.Background(), plug)).To(Succeed())
+			err := util.NewTestApparatusUtil(context.Background()).PlugCoupled(plug, nil, nil, nil)
+			_, pending := util.IsApparatusPending(err)
+			Expect(pending).To(BeTrue())
+			Expect(fakeClient.Status().Update(context.Background(), plug)).To(Succeed())
+			eventRequest := map[string]interface{}{}
+			Expect(json.Unmarshal(<-events, &eventRequest)).To(Succeed())
+			callbackURL, _ := eventRequest["callbackUrl"].(string)
+			for len(util.PlugOperationEvents) < cap(util.PlugOperationEvents) {
+				util.PlugOperationEvents <- event.GenericEvent{}
+			}
+			DeferCleanup(func() {
+				for len(util.PlugOperationEvents) > 0 {
+					<-util.PlugOperationEvents
+				}
+			})
+
+			Expect(callback(callbackURL, protocol.OperationSucceeded)).To(Equal(http.StatusNoContent))
+
+			plug = &integrationv1.Plug{}
+			Expect(fakeClient.Get(
+				context.Background(),
+				types.NamespacedName{Name: "my-plug", Namespace: "default"},
+				plug,
+			)).To(Succeed())
+			Expect(plug.Status.PendingOperations[0].Status).To(Equal(string(protocol.OperationSucceeded)))
+		})
+	})
+})
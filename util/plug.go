@@ -28,14 +28,17 @@ package util
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"strings"
 	"time"
 
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apiserver/pkg/registry/generic/registry"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -45,17 +48,19 @@ type PlugUtil struct {
 	client         *client.Client
 	ctx            context.Context
 	namespacedName types.NamespacedName
+	recorder       record.EventRecorder
 	req            *ctrl.Request
 	resultUtil     *ResultUtil
-	socket         *integrationv1beta1.Socket
+	socket         *integrationv1.Socket
 }
 
 func NewPlugUtil(
 	client *client.Client,
 	ctx context.Context,
 	req *ctrl.Request,
-	namespacedName *integrationv1beta1.NamespacedName,
-	socket *integrationv1beta1.Socket,
+	namespacedName *integrationv1.NamespacedName,
+	socket *integrationv1.Socket,
+	recorder record.EventRecorder,
 ) *PlugUtil {
 	operatorNamespace := GetOperatorNamespace()
 	return &PlugUtil{
@@ -63,23 +68,24 @@ func NewPlugUtil(
 		client:         client,
 		ctx:            ctx,
 		namespacedName: EnsureNamespacedName(namespacedName, operatorNamespace),
+		recorder:       recorder,
 		req:            req,
 		resultUtil:     NewResultUtil(ctx),
 		socket:         socket,
 	}
 }
 
-func (u *PlugUtil) Get() (*integrationv1beta1.Plug, error) {
+func (u *PlugUtil) Get() (*integrationv1.Plug, error) {
 	client := *u.client
 	ctx := u.ctx
-	plug := &integrationv1beta1.Plug{}
+	plug := &integrationv1.Plug{}
 	if err := client.Get(ctx, u.namespacedName, plug); err != nil {
 		return nil, err
 	}
 	return plug.DeepCopy(), nil
 }
 
-func (u *PlugUtil) Update(plug *integrationv1beta1.Plug, requeue bool) (ctrl.Result, error) {
+func (u *PlugUtil) Update(plug *integrationv1.Plug, requeue bool) (ctrl.Result, error) {
 	client := *u.client
 	ctx := u.ctx
 	if err := client.Update(ctx, plug); err != nil {
@@ -92,24 +98,36 @@ func (u *PlugUtil) Update(plug *integrationv1beta1.Plug, requeue bool) (ctrl.Res
 }
 
 func (u *PlugUtil) UpdateStatus(
-	plug *integrationv1beta1.Plug,
+	plug *integrationv1.Plug,
 	requeue bool,
 ) (ctrl.Result, error) {
 	client := *u.client
 	ctx := u.ctx
+	if health := GetApparatusHealth(plug.UID); health != nil && plug.Spec.Apparatus != nil {
+		SetCondition(
+			&plug.Status.Conditions,
+			&plug.Status.ConditionHistory,
+			plug.Generation,
+			ConditionTypeApparatusReachable,
+			health.Ready,
+			string(health.Reason),
+			health.Message,
+		)
+	}
 	if err := client.Status().Update(ctx, plug); err != nil {
 		if strings.Contains(err.Error(), registry.OptimisticLockErrorMsg) {
 			return ctrl.Result{Requeue: true}, nil
 		}
 		return ctrl.Result{}, err
 	}
+	u.updateSocketSummary(plug)
 	if requeue {
 		return ctrl.Result{Requeue: true, RequeueAfter: 0}, nil
 	}
 	return ctrl.Result{}, nil
 }
 
-func (u *PlugUtil) Delete(plug *integrationv1beta1.Plug) (ctrl.Result, error) {
+func (u *PlugUtil) Delete(plug *integrationv1.Plug) (ctrl.Result, error) {
 	client := *u.client
 	ctx := u.ctx
 	if err := client.Delete(ctx, plug); err != nil {
@@ -119,7 +137,7 @@ func (u *PlugUtil) Delete(plug *integrationv1beta1.Plug) (ctrl.Result, error) {
 }
 
 func (u *PlugUtil) GetCoupledCondition(
-	plug *integrationv1beta1.Plug,
+	plug *integrationv1.Plug,
 ) (*metav1.Condition, error) {
 	if plug == nil {
 		var err error
@@ -134,7 +152,7 @@ func (u *PlugUtil) GetCoupledCondition(
 
 func (u *PlugUtil) Error(
 	err error,
-	plug *integrationv1beta1.Plug,
+	plug *integrationv1.Plug,
 ) (ctrl.Result, error) {
 	e := err
 	if plug == nil {
@@ -144,6 +162,9 @@ func (u *PlugUtil) Error(
 			return ctrl.Result{}, err
 		}
 	}
+	if pendingError, ok := IsApparatusPending(err); ok {
+		return u.waitForApparatus(pendingError, plug)
+	}
 	if u.apparatusUtil.NotRunning(err) {
 		requeueAfter := time.Duration(time.Second.Nanoseconds() * 10)
 		started, err := u.apparatusUtil.Start(plug, u.socket, &requeueAfter)
@@ -165,9 +186,33 @@ func (u *PlugUtil) Error(
 	return result, err
 }
 
+// waitForApparatus keeps the plug in process while an apparatus operation has not completed
+func (u *PlugUtil) waitForApparatus(
+	pendingError ApparatusPendingError,
+	plug *integrationv1.Plug,
+) (ctrl.Result, error) {
+	result := ctrl.Result{RequeueAfter: pendingError.RequeueAfter()}
+	if plug.GetDeletionTimestamp() == nil {
+		reason := CouplingInProcess
+		coupledCondition, err := u.GetCoupledCondition(plug)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if coupledCondition != nil && coupledCondition.Reason == string(UpdatingInProcess) {
+			reason = UpdatingInProcess
+		}
+		u.setCoupledStatusCondition(reason, pendingError.Error(), plug)
+	}
+	// the pending operation is kept in the status until it completes
+	if _, err := u.UpdateStatus(plug, false); err != nil {
+		return ctrl.Result{}, err
+	}
+	return result, nil
+}
+
 func (u *PlugUtil) UpdateErrorStatus(
 	err error,
-	plug *integrationv1beta1.Plug,
+	plug *integrationv1.Plug,
 ) (ctrl.Result, error) {
 	e := err
 	if plug == nil {
@@ -180,6 +225,7 @@ func (u *PlugUtil) UpdateErrorStatus(
 	if err = u.setErrorStatus(e, plug); err != nil {
 		return ctrl.Result{}, err
 	}
+	PlugWarning(u.recorder, e, plug, u.socket)
 	if _, err := u.UpdateStatus(plug, true); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -191,8 +237,8 @@ func (u *PlugUtil) UpdateErrorStatus(
 
 func (u *PlugUtil) UpdateCoupledStatus(
 	conditionCoupledReason ConditionCoupledReason,
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	requeue bool,
 ) (ctrl.Result, error) {
 	if plug == nil {
@@ -205,6 +251,27 @@ func (u *PlugUtil) UpdateCoupledStatus(
 	if socket != nil {
 		u.setCoupledSocketStatus(plug, socket)
 	}
+	if conditionCoupledReason == CouplingInProcess || conditionCoupledReason == UpdatingInProcess {
+		coupledCondition, err := u.GetCoupledCondition(plug)
+		if err != nil {
+			return u.Error(err, plug)
+		}
+		if coupledCondition == nil || coupledCondition.Reason != string(conditionCoupledReason) {
+			plug.Status.RenderedResourceCount = 0
+		}
+	} else if conditionCoupledReason == CouplingSucceeded {
+		now := metav1.Now()
+		plug.Status.LastCoupledTime = &now
+		SetCondition(
+			&plug.Status.Conditions,
+			&plug.Status.ConditionHistory,
+			plug.Generation,
+			ConditionTypeResourcesApplied,
+			true,
+			"ResourcesApplied",
+			strconv.Itoa(plug.Status.RenderedResourceCount)+" resources applied",
+		)
+	}
 	if conditionCoupledReason != "" {
 		u.setCoupledStatusCondition(conditionCoupledReason, "", plug)
 	}
@@ -212,8 +279,8 @@ func (u *PlugUtil) UpdateCoupledStatus(
 }
 
 func (u *PlugUtil) UpdateResultStatus(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig Config,
 	socketConfig Config,
 ) (ctrl.Result, error) {
@@ -241,7 +308,7 @@ func (u *PlugUtil) UpdateResultStatus(
 	); err != nil {
 		return u.Error(err, plug)
 	}
-	coupledResultStatus := integrationv1beta1.CoupledResultStatus{
+	coupledResultStatus := integrationv1.CoupledResultStatus{
 		ObservedGeneration: plug.Generation,
 	}
 	coupledResultStatus.Plug = coupledResult.Plug
@@ -253,7 +320,7 @@ func (u *PlugUtil) UpdateResultStatus(
 func (u *PlugUtil) setCoupledStatusCondition(
 	conditionCoupledReason ConditionCoupledReason,
 	message string,
-	plug *integrationv1beta1.Plug,
+	plug *integrationv1.Plug,
 ) {
 	coupledStatus := false
 	if message == "" {
@@ -261,6 +328,8 @@ func (u *PlugUtil) setCoupledStatusCondition(
 			message = "plug created"
 		} else if conditionCoupledReason == SocketNotCreated {
 			message = "waiting for socket to be created"
+		} else if conditionCoupledReason == AwaitingApproval {
+			message = "awaiting approval to couple to socket"
 		} else if conditionCoupledReason == CouplingInProcess {
 			message = "coupling to socket"
 		} else if conditionCoupledReason == CouplingSucceeded {
@@ -269,28 +338,88 @@ func (u *PlugUtil) setCoupledStatusCondition(
 			message = "updating coupling"
 		} else if conditionCoupledReason == Error {
 			message = "unknown error"
+		} else if conditionCoupledReason == NotPermitted {
+			message = "coupling not permitted"
 		}
 	}
-	if conditionCoupledReason != Error {
-		plug.Status.Conditions = []metav1.Condition{}
+	if conditionCoupledReason != Error && conditionCoupledReason != NotPermitted {
+		SetCondition(
+			&plug.Status.Conditions,
+			&plug.Status.ConditionHistory,
+			plug.Generation,
+			ConditionTypeFailed,
+			false,
+			string(conditionCoupledReason),
+			"",
+		)
 	}
 	if conditionCoupledReason == CouplingSucceeded {
 		coupledStatus = true
 	}
-	condition := metav1.Condition{
-		Message:            message,
-		ObservedGeneration: plug.Generation,
-		Reason:             string(conditionCoupledReason),
-		Status:             "False",
-		Type:               string(ConditionTypeCoupled),
-	}
-	if coupledStatus {
-		condition.Status = "True"
+	SetCondition(
+		&plug.Status.Conditions,
+		&plug.Status.ConditionHistory,
+		plug.Generation,
+		ConditionTypeCoupled,
+		coupledStatus,
+		string(conditionCoupledReason),
+		message,
+	)
+	u.setReadyCondition(plug)
+}
+
+// SetConfigResolvedCondition reports whether the config of the plug and socket resolved
+func (u *PlugUtil) SetConfigResolvedCondition(plug *integrationv1.Plug, err error) {
+	if err != nil {
+		SetCondition(
+			&plug.Status.Conditions,
+			&plug.Status.ConditionHistory,
+			plug.Generation,
+			ConditionTypeConfigResolved,
+			false,
+			"ConfigError",
+			err.Error(),
+		)
+		return
+	}
+	SetCondition(
+		&plug.Status.Conditions,
+		&plug.Status.ConditionHistory,
+		plug.Generation,
+		ConditionTypeConfigResolved,
+		true,
+		"ConfigResolved",
+		"config resolved",
+	)
+}
+
+func (u *PlugUtil) setReadyCondition(plug *integrationv1.Plug) {
+	failedCondition := meta.FindStatusCondition(plug.Status.Conditions, string(ConditionTypeFailed))
+	coupledCondition := meta.FindStatusCondition(plug.Status.Conditions, string(ConditionTypeCoupled))
+	if failedCondition != nil && failedCondition.Status == metav1.ConditionTrue {
+		SetCondition(
+			&plug.Status.Conditions,
+			&plug.Status.ConditionHistory,
+			plug.Generation,
+			ConditionTypeReady,
+			false,
+			failedCondition.Reason,
+			failedCondition.Message,
+		)
+	} else if coupledCondition != nil {
+		SetCondition(
+			&plug.Status.Conditions,
+			&plug.Status.ConditionHistory,
+			plug.Generation,
+			ConditionTypeReady,
+			coupledCondition.Status == metav1.ConditionTrue,
+			coupledCondition.Reason,
+			coupledCondition.Message,
+		)
 	}
-	meta.SetStatusCondition(&plug.Status.Conditions, condition)
 }
 
-func (u *PlugUtil) setErrorStatus(err error, plug *integrationv1beta1.Plug) error {
+func (u *PlugUtil) setErrorStatus(err error, plug *integrationv1.Plug) error {
 	e := err
 	if e == nil {
 		return nil
@@ -302,29 +431,64 @@ func (u *PlugUtil) setErrorStatus(err error, plug *integrationv1beta1.Plug) erro
 		return nil
 	}
 	message := e.Error()
+	reason := Error
+	var templateError TemplateError
+	if _, ok := e.(ValidationError); ok {
+		reason = NotPermitted
+	} else if errors.As(e, &templateError) {
+		reason = TemplateInvalid
+	}
+	if u.apparatusUtil.NotRunning(e) {
+		SetCondition(
+			&plug.Status.Conditions,
+			&plug.Status.ConditionHistory,
+			plug.Generation,
+			ConditionTypeApparatusReachable,
+			false,
+			string(ApparatusUnreachable),
+			message,
+		)
+	}
+	if _, ok := e.(ResourceError); ok {
+		SetCondition(
+			&plug.Status.Conditions,
+			&plug.Status.ConditionHistory,
+			plug.Generation,
+			ConditionTypeResourcesApplied,
+			false,
+			"ApplyFailed",
+			message,
+		)
+	}
 	coupledCondition, err := u.GetCoupledCondition(plug)
 	if err != nil {
 		return err
 	}
 	if coupledCondition != nil {
-		u.setCoupledStatusCondition(Error, "coupling failed", plug)
-	}
-	failedCondition := metav1.Condition{
-		Message:            message,
-		ObservedGeneration: plug.Generation,
-		Reason:             "Error",
-		Status:             "True",
-		Type:               string(ConditionTypeFailed),
+		if reason == NotPermitted {
+			u.setCoupledStatusCondition(reason, "", plug)
+		} else {
+			u.setCoupledStatusCondition(reason, "coupling failed", plug)
+		}
 	}
-	meta.SetStatusCondition(&plug.Status.Conditions, failedCondition)
+	SetCondition(
+		&plug.Status.Conditions,
+		&plug.Status.ConditionHistory,
+		plug.Generation,
+		ConditionTypeFailed,
+		true,
+		string(reason),
+		message,
+	)
+	u.setReadyCondition(plug)
 	return nil
 }
 
 func (u *PlugUtil) setCoupledSocketStatus(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 ) {
-	plug.Status.CoupledSocket = &integrationv1beta1.CoupledSocket{
+	plug.Status.CoupledSocket = &integrationv1.CoupledSocket{
 		APIVersion: socket.APIVersion,
 		Kind:       socket.Kind,
 		Name:       socket.Name,
@@ -332,3 +496,44 @@ func (u *PlugUtil) setCoupledSocketStatus(
 		UID:        socket.UID,
 	}
 }
+
+func (u *PlugUtil) updateSocketSummary(plug *integrationv1.Plug) {
+	if u.socket == nil {
+		return
+	}
+	socketUtil := NewSocketUtil(u.client, u.ctx, u.req, &integrationv1.NamespacedName{
+		Name:      u.socket.Name,
+		Namespace: u.socket.Namespace,
+	}, u.recorder)
+	if err := socketUtil.UpdateSummaryStatus(nil, plug); err != nil {
+		ctrl.Log.WithName("util.PlugUtil").Error(err, "failed to update socket summary")
+	}
+}
+
+// RecordApparatusResponseCode reports the status code of an apparatus response
+// in the status of the plug when the apparatus with the uid is the one of the plug,
+// since the apparatus of the socket is called with the plug as well
+func RecordApparatusResponseCode(plug *integrationv1.Plug, uid string, code int) {
+	if plug == nil || string(plug.UID) != uid || code == 0 {
+		return
+	}
+	plug.Status.LastApparatusResponseCode = code
+	SetCondition(
+		&plug.Status.Conditions,
+		&plug.Status.ConditionHistory,
+		plug.Generation,
+		ConditionTypeApparatusReachable,
+		true,
+		"ApparatusResponded",
+		"apparatus responded with status "+strconv.Itoa(code),
+	)
+}
+
+// RecordRenderedResources adds to the number of resources rendered during the
+// current coupling of a plug in the status of the plug
+func RecordRenderedResources(plug *integrationv1.Plug, count int) {
+	if plug == nil || count == 0 {
+		return
+	}
+	plug.Status.RenderedResourceCount += count
+}
@@ -31,33 +31,37 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strconv"
 	"strings"
 	"text/template"
 
-	"github.com/Masterminds/sprig"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes"
-	ctrl "sigs.k8s.io/controller-runtime"
 	kustomizeTypes "sigs.k8s.io/kustomize/api/types"
 )
 
 type ResourceUtil struct {
-	client *kubernetes.Clientset
-	ctx    context.Context
+	client              *kubernetes.Clientset
+	ctx                 context.Context
+	generatedUtil       *GeneratedUtil
+	templateContextUtil *TemplateContextUtil
 }
 
 func NewResourceUtil(ctx context.Context) *ResourceUtil {
 	return &ResourceUtil{
-		client: kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie()),
-		ctx:    ctx,
+		client:              kubernetes.NewForConfigOrDie(GetRestConfig(ctx)),
+		ctx:                 ctx,
+		generatedUtil:       NewGeneratedUtil(ctx),
+		templateContextUtil: NewTemplateContextUtil(ctx),
 	}
 }
 
-func (u *ResourceUtil) PlugCreated(plug *integrationv1beta1.Plug) error {
+func (u *ResourceUtil) PlugCreated(plug *integrationv1.Plug) error {
 	kubectlUtil := NewKubectlUtil(u.ctx, plug.Namespace, EnsureServiceAccount(plug.Spec.ServiceAccountName))
 	if err := u.ProcessResources(
 		plug,
@@ -67,8 +71,10 @@ func (u *ResourceUtil) PlugCreated(plug *integrationv1beta1.Plug) error {
 		nil,
 		nil,
 		plug.Namespace,
-		u.filterResources(plug.Spec.Resources, integrationv1beta1.CreatedWhen),
+		u.filterResources(plug.Spec.Resources, integrationv1.CreatedWhen),
 		kubectlUtil,
+		u.generatedUtil.ForPlug(plug),
+		PlugTemplateOwner,
 	); err != nil {
 		return err
 	}
@@ -76,8 +82,8 @@ func (u *ResourceUtil) PlugCreated(plug *integrationv1beta1.Plug) error {
 }
 
 func (u *ResourceUtil) PlugCoupled(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) error {
@@ -90,8 +96,10 @@ func (u *ResourceUtil) PlugCoupled(
 		nil,
 		nil,
 		plug.Namespace,
-		u.filterResources(plug.Spec.Resources, integrationv1beta1.CoupledWhen),
+		u.filterResources(plug.Spec.Resources, integrationv1.CoupledWhen),
 		kubectlUtil,
+		u.generatedUtil.ForPlug(plug),
+		PlugTemplateOwner,
 	); err != nil {
 		return err
 	}
@@ -99,8 +107,8 @@ func (u *ResourceUtil) PlugCoupled(
 }
 
 func (u *ResourceUtil) PlugUpdated(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) error {
@@ -113,8 +121,10 @@ func (u *ResourceUtil) PlugUpdated(
 		nil,
 		nil,
 		plug.Namespace,
-		u.filterResources(plug.Spec.Resources, integrationv1beta1.UpdatedWhen),
+		u.filterResources(plug.Spec.Resources, integrationv1.UpdatedWhen),
 		kubectlUtil,
+		u.generatedUtil.ForPlug(plug),
+		PlugTemplateOwner,
 	); err != nil {
 		return err
 	}
@@ -122,11 +132,12 @@ func (u *ResourceUtil) PlugUpdated(
 }
 
 func (u *ResourceUtil) PlugDecoupled(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) error {
+	generated := u.generatedUtil.ForPlug(plug)
 	kubectlUtil := NewKubectlUtil(u.ctx, plug.Namespace, EnsureServiceAccount(plug.Spec.ServiceAccountName))
 	if err := u.ProcessResources(
 		plug,
@@ -136,8 +147,10 @@ func (u *ResourceUtil) PlugDecoupled(
 		nil,
 		nil,
 		plug.Namespace,
-		u.filterResources(plug.Spec.Resources, integrationv1beta1.DecoupledWhen),
+		u.filterResources(plug.Spec.Resources, integrationv1.DecoupledWhen),
 		kubectlUtil,
+		generated,
+		PlugTemplateOwner,
 	); err != nil {
 		return err
 	}
@@ -151,14 +164,19 @@ func (u *ResourceUtil) PlugDecoupled(
 		plug.Namespace,
 		u.filterDeleteWhenDecoupledResources(plug.Spec.Resources),
 		kubectlUtil,
+		generated,
+		PlugTemplateOwner,
 	); err != nil {
 		return err
 	}
+	if err := u.generatedUtil.PlugDecoupled(plug); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (u *ResourceUtil) PlugDeleted(
-	plug *integrationv1beta1.Plug,
+	plug *integrationv1.Plug,
 ) error {
 	kubectlUtil := NewKubectlUtil(u.ctx, plug.Namespace, EnsureServiceAccount(plug.Spec.ServiceAccountName))
 	if err := u.ProcessResources(
@@ -169,15 +187,17 @@ func (u *ResourceUtil) PlugDeleted(
 		nil,
 		nil,
 		plug.Namespace,
-		u.filterResources(plug.Spec.Resources, integrationv1beta1.DeletedWhen),
+		u.filterResources(plug.Spec.Resources, integrationv1.DeletedWhen),
 		kubectlUtil,
+		u.generatedUtil.ForPlug(plug),
+		PlugTemplateOwner,
 	); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (u *ResourceUtil) SocketCreated(socket *integrationv1beta1.Socket) error {
+func (u *ResourceUtil) SocketCreated(socket *integrationv1.Socket) error {
 	kubectlUtil := NewKubectlUtil(u.ctx, socket.Namespace, EnsureServiceAccount(socket.Spec.ServiceAccountName))
 	if err := u.ProcessResources(
 		nil,
@@ -187,8 +207,10 @@ func (u *ResourceUtil) SocketCreated(socket *integrationv1beta1.Socket) error {
 		nil,
 		nil,
 		socket.Namespace,
-		u.filterResources(socket.Spec.Resources, integrationv1beta1.CreatedWhen),
+		u.filterResources(socket.Spec.Resources, integrationv1.CreatedWhen),
 		kubectlUtil,
+		u.generatedUtil.ForSocket(socket, nil),
+		SocketTemplateOwner,
 	); err != nil {
 		return err
 	}
@@ -196,8 +218,8 @@ func (u *ResourceUtil) SocketCreated(socket *integrationv1beta1.Socket) error {
 }
 
 func (u *ResourceUtil) SocketCoupled(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) error {
@@ -210,8 +232,10 @@ func (u *ResourceUtil) SocketCoupled(
 		nil,
 		nil,
 		socket.Namespace,
-		u.filterResources(socket.Spec.Resources, integrationv1beta1.CoupledWhen),
+		u.filterResources(socket.Spec.Resources, integrationv1.CoupledWhen),
 		kubectlUtil,
+		u.generatedUtil.ForSocket(socket, plug),
+		SocketTemplateOwner,
 	); err != nil {
 		return err
 	}
@@ -219,8 +243,8 @@ func (u *ResourceUtil) SocketCoupled(
 }
 
 func (u *ResourceUtil) SocketUpdated(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) error {
@@ -233,8 +257,10 @@ func (u *ResourceUtil) SocketUpdated(
 		nil,
 		nil,
 		socket.Namespace,
-		u.filterResources(socket.Spec.Resources, integrationv1beta1.UpdatedWhen),
+		u.filterResources(socket.Spec.Resources, integrationv1.UpdatedWhen),
 		kubectlUtil,
+		u.generatedUtil.ForSocket(socket, plug),
+		SocketTemplateOwner,
 	); err != nil {
 		return err
 	}
@@ -242,11 +268,12 @@ func (u *ResourceUtil) SocketUpdated(
 }
 
 func (u *ResourceUtil) SocketDecoupled(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) error {
+	generated := u.generatedUtil.ForSocket(socket, plug)
 	kubectlUtil := NewKubectlUtil(u.ctx, socket.Namespace, EnsureServiceAccount(socket.Spec.ServiceAccountName))
 	if err := u.ProcessResources(
 		plug,
@@ -256,8 +283,10 @@ func (u *ResourceUtil) SocketDecoupled(
 		nil,
 		nil,
 		socket.Namespace,
-		u.filterResources(socket.Spec.Resources, integrationv1beta1.DecoupledWhen),
+		u.filterResources(socket.Spec.Resources, integrationv1.DecoupledWhen),
 		kubectlUtil,
+		generated,
+		SocketTemplateOwner,
 	); err != nil {
 		return err
 	}
@@ -271,14 +300,19 @@ func (u *ResourceUtil) SocketDecoupled(
 		socket.Namespace,
 		u.filterDeleteWhenDecoupledResources(socket.Spec.Resources),
 		kubectlUtil,
+		generated,
+		SocketTemplateOwner,
 	); err != nil {
 		return err
 	}
+	if err := u.generatedUtil.SocketDecoupled(socket, plug); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (u *ResourceUtil) SocketDeleted(
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 ) error {
 	kubectlUtil := NewKubectlUtil(u.ctx, socket.Namespace, EnsureServiceAccount(socket.Spec.ServiceAccountName))
 	if err := u.ProcessResources(
@@ -289,8 +323,10 @@ func (u *ResourceUtil) SocketDeleted(
 		nil,
 		nil,
 		socket.Namespace,
-		u.filterResources(socket.Spec.Resources, integrationv1beta1.DeletedWhen),
+		u.filterResources(socket.Spec.Resources, integrationv1.DeletedWhen),
 		kubectlUtil,
+		u.generatedUtil.ForSocket(socket, nil),
+		SocketTemplateOwner,
 	); err != nil {
 		return err
 	}
@@ -302,6 +338,34 @@ func (u *ResourceUtil) GetResource(
 	objRef kustomizeTypes.Target,
 	kubectlUtil *KubectlUtil,
 ) (*unstructured.Unstructured, error) {
+	body, err := u.targetBody(namespace, objRef)
+	if err != nil {
+		return nil, err
+	}
+	return kubectlUtil.Get(body)
+}
+
+// ListResources lists the objects of the kind of the object reference in the namespace that
+// match the selector
+func (u *ResourceUtil) ListResources(
+	namespace string,
+	objRef kustomizeTypes.Target,
+	selector *metav1.LabelSelector,
+	kubectlUtil *KubectlUtil,
+) (*unstructured.UnstructuredList, error) {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	objRef.Name = ""
+	body, err := u.targetBody(namespace, objRef)
+	if err != nil {
+		return nil, err
+	}
+	return kubectlUtil.List(body, metav1.ListOptions{LabelSelector: labelSelector.String()})
+}
+
+func (u *ResourceUtil) targetBody(namespace string, objRef kustomizeTypes.Target) ([]byte, error) {
 	const tpl = `
 apiVersion: {{ .APIVersion }}
 kind: {{ .Kind }}
@@ -325,94 +389,232 @@ metadata:
 	if err != nil {
 		return nil, err
 	}
-	body := buff.Bytes()
-	return kubectlUtil.Get(body)
+	return buff.Bytes(), nil
 }
 
 func (u *ResourceUtil) ProcessResources(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 	plugResult *Result,
 	socketResult *Result,
 	namespace string,
-	resources []*integrationv1beta1.ResourceAction,
+	resources []*integrationv1.ResourceAction,
 	kubectlUtil *KubectlUtil,
+	generated *GeneratedValues,
+	owner TemplateOwner,
 ) error {
-	for _, resource := range resources {
+	funcMap := mergeFuncMaps(generated.FuncMap(), NewLookup(kubectlUtil, namespace).FuncMap())
+	strict := SocketStrictTemplates(socket)
+	if owner == PlugTemplateOwner {
+		strict = PlugStrictTemplates(plug)
+	}
+	from := string(owner) + ".resources"
+	if plugResult != nil || socketResult != nil {
+		from = string(owner) + ".resultResources"
+	}
+	renderedResourceCount := 0
+	defer func() {
+		RecordRenderedResources(plug, renderedResourceCount)
+	}()
+	var data map[string]interface{}
+	for i, resource := range resources {
+		if resource == nil {
+			continue
+		}
+		if data == nil {
+			var err error
+			data, err = u.templateContextUtil.Build(
+				owner,
+				ResourceTemplateStage,
+				plug,
+				socket,
+				plugConfig,
+				socketConfig,
+				plugResult,
+				socketResult,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		location := "resource " + strconv.Itoa(i)
 		templates := []string{}
 		if resource.Template != nil {
 			templates = append(templates, string(resource.Template.Raw))
 		}
-		if resource.Templates != nil {
-			for _, template := range *resource.Templates {
-				templates = append(templates, string(template.Raw))
-			}
+		for _, template := range resource.Templates {
+			templates = append(templates, string(template.Raw))
 		}
 		if resource.StringTemplate != "" {
 			templates = append(templates, resource.StringTemplate)
 		}
-		if resource.StringTemplates != nil {
-			templates = append(templates, *resource.StringTemplates...)
+		templates = append(templates, resource.StringTemplates...)
+		templatedResources := []string{}
+		if resource.Source != nil {
+			documents, err := u.renderSource(resource.Source, resource.Engine, strict, data, namespace, funcMap, kubectlUtil)
+			if err != nil {
+				return templateErrorLocation(err, location)
+			}
+			sourceResources, err := namespaceResources(documents, namespace)
+			if err != nil {
+				return err
+			}
+			templatedResources = append(templatedResources, sourceResources...)
 		}
 		for _, template := range templates {
-			templatedResource, err := u.templateResource(
-				plug,
-				socket,
-				plugConfig,
-				socketConfig,
-				plugResult,
-				socketResult,
+			templateResources, err := u.templateResource(
+				data,
 				namespace,
+				resource.Engine,
+				strict,
 				template,
+				funcMap,
 			)
 			if err != nil {
-				return err
+				return templateErrorLocation(err, location)
 			}
+			templatedResources = append(templatedResources, templateResources...)
+		}
+		appliedResources := []integrationv1.AppliedResource{}
+		for _, templatedResource := range templatedResources {
 			if strings.TrimSpace(templatedResource) == "" {
 				continue
 			}
+			renderedResourceCount++
 			do := resource.Do
 			if do == "" {
-				do = integrationv1beta1.ApplyDo
+				do = integrationv1.ApplyDo
 			}
-			if resource.Do == integrationv1beta1.ApplyDo {
+			if resource.Do == integrationv1.ApplyDo {
 				if err := kubectlUtil.Apply([]byte(templatedResource)); err != nil {
-					return err
+					return NewResourceError(err).WithResource(describeResource(templatedResource))
 				}
-			} else if resource.Do == integrationv1beta1.DeleteDo {
+				appliedResources = append(appliedResources, newAppliedResource(from, i, templatedResource))
+			} else if resource.Do == integrationv1.DeleteDo {
 				if err := kubectlUtil.Delete([]byte(templatedResource)); err != nil {
 					if !k8serrors.IsNotFound(err) {
-						return err
+						return NewResourceError(err).WithResource(describeResource(templatedResource))
 					}
 				}
-			} else if resource.Do == integrationv1beta1.RecreateDo {
+			} else if resource.Do == integrationv1.RecreateDo {
 				kubectlUtil.Delete([]byte(templatedResource))
 				if err := kubectlUtil.Apply([]byte(templatedResource)); err != nil {
-					return err
+					return NewResourceError(err).WithResource(describeResource(templatedResource))
 				}
+				appliedResources = append(appliedResources, newAppliedResource(from, i, templatedResource))
 			}
 		}
+		index := i
+		if err := pruneAppliedResources(plug, from, func(resource int) bool {
+			return resource == index
+		}, appliedResources, kubectlUtil); err != nil {
+			return err
+		}
+	}
+	// objects of resources removed from the spec are pruned as well
+	return pruneAppliedResources(plug, from, func(resource int) bool {
+		return resource >= len(resources)
+	}, nil, kubectlUtil)
+}
+
+// pruneAppliedResources replaces the objects recorded in the status of the plug for the matching
+// resources with the applied objects, deleting the recorded objects that were not applied again,
+// the objects are only recorded for plugs since sockets render their resources for every plug
+func pruneAppliedResources(
+	plug *integrationv1.Plug,
+	from string,
+	match func(resource int) bool,
+	applied []integrationv1.AppliedResource,
+	kubectlUtil *KubectlUtil,
+) error {
+	if plug == nil {
+		return nil
+	}
+	appliedResources := []integrationv1.AppliedResource{}
+	for _, appliedResource := range plug.Status.AppliedResources {
+		if appliedResource.From != from || !match(appliedResource.Resource) {
+			appliedResources = append(appliedResources, appliedResource)
+			continue
+		}
+		if containsAppliedResource(applied, appliedResource) {
+			continue
+		}
+		body, err := json.Marshal(map[string]interface{}{
+			"apiVersion": appliedResource.APIVersion,
+			"kind":       appliedResource.Kind,
+			"metadata": map[string]interface{}{
+				"name":      appliedResource.Name,
+				"namespace": appliedResource.Namespace,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if err := kubectlUtil.Delete(body); err != nil && !k8serrors.IsNotFound(err) {
+			return NewResourceError(err).WithResource(
+				appliedResource.Kind + " " + appliedResource.Namespace + "/" + appliedResource.Name,
+			)
+		}
 	}
+	for _, appliedResource := range applied {
+		if !containsAppliedResource(appliedResources, appliedResource) {
+			appliedResources = append(appliedResources, appliedResource)
+		}
+	}
+	if len(appliedResources) == 0 {
+		appliedResources = nil
+	}
+	plug.Status.AppliedResources = appliedResources
 	return nil
 }
 
+func newAppliedResource(from string, resource int, templatedResource string) integrationv1.AppliedResource {
+	parsed := gjson.Parse(templatedResource)
+	return integrationv1.AppliedResource{
+		From:       from,
+		Resource:   resource,
+		APIVersion: parsed.Get("apiVersion").String(),
+		Kind:       parsed.Get("kind").String(),
+		Name:       parsed.Get("metadata.name").String(),
+		Namespace:  parsed.Get("metadata.namespace").String(),
+	}
+}
+
+func containsAppliedResource(
+	appliedResources []integrationv1.AppliedResource,
+	appliedResource integrationv1.AppliedResource,
+) bool {
+	for _, item := range appliedResources {
+		if item == appliedResource {
+			return true
+		}
+	}
+	return false
+}
+
+// filterResources gets the actions of the resources for the event, leaving nil in place of
+// the other resources so errors report the index of the resource in the spec
 func (u *ResourceUtil) filterResources(
-	resources []*integrationv1beta1.Resource,
-	when integrationv1beta1.When,
-) []*integrationv1beta1.ResourceAction {
-	filteredResources := []*integrationv1beta1.ResourceAction{}
+	resources []*integrationv1.Resource,
+	when integrationv1.When,
+) []*integrationv1.ResourceAction {
+	filteredResources := []*integrationv1.ResourceAction{}
 	if resources == nil {
 		return filteredResources
 	}
 	if when == "" {
-		when = integrationv1beta1.CoupledWhen
+		when = integrationv1.CoupledWhen
 	}
 	for _, resource := range resources {
-		if WhenInWhenSlice(when, resource.When) {
-			filteredResources = append(filteredResources, &integrationv1beta1.ResourceAction{
+		if !WhenInWhenSlice(when, resource.When) {
+			filteredResources = append(filteredResources, nil)
+		} else {
+			filteredResources = append(filteredResources, &integrationv1.ResourceAction{
 				Do:              resource.Do,
+				Engine:          resource.Engine,
+				Source:          resource.Source,
 				StringTemplate:  resource.StringTemplate,
 				StringTemplates: resource.StringTemplates,
 				Template:        resource.Template,
@@ -423,19 +625,25 @@ func (u *ResourceUtil) filterResources(
 	return filteredResources
 }
 
+// filterDeleteWhenDecoupledResources gets delete actions for the resources that are removed
+// when decoupled, leaving nil in place of the other resources
 func (u *ResourceUtil) filterDeleteWhenDecoupledResources(
-	resources []*integrationv1beta1.Resource,
-) []*integrationv1beta1.ResourceAction {
-	filteredResources := []*integrationv1beta1.ResourceAction{}
+	resources []*integrationv1.Resource,
+) []*integrationv1.ResourceAction {
+	filteredResources := []*integrationv1.ResourceAction{}
 	if resources == nil {
 		return filteredResources
 	}
 	for _, resource := range resources {
-		if resource.Do != integrationv1beta1.DeleteDo &&
-			!WhenInWhenSlice(integrationv1beta1.DecoupledWhen, resource.When) &&
-			!resource.RetainWhenDecoupled {
-			filteredResources = append(filteredResources, &integrationv1beta1.ResourceAction{
-				Do:              integrationv1beta1.DeleteDo,
+		if resource.Do == integrationv1.DeleteDo ||
+			WhenInWhenSlice(integrationv1.DecoupledWhen, resource.When) ||
+			resource.RetainWhenDecoupled {
+			filteredResources = append(filteredResources, nil)
+		} else {
+			filteredResources = append(filteredResources, &integrationv1.ResourceAction{
+				Do:              integrationv1.DeleteDo,
+				Engine:          resource.Engine,
+				Source:          resource.Source,
 				StringTemplate:  resource.StringTemplate,
 				StringTemplates: resource.StringTemplates,
 				Template:        resource.Template,
@@ -447,87 +655,80 @@ func (u *ResourceUtil) filterDeleteWhenDecoupledResources(
 }
 
 func (u *ResourceUtil) templateResource(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
-	plugConfig *Config,
-	socketConfig *Config,
-	plugResult *Result,
-	socketResult *Result,
+	data map[string]interface{},
 	namespace string,
+	engine integrationv1.TemplateEngine,
+	strict bool,
 	body string,
-) (string, error) {
-	data, err := u.buildTemplateData(plug, socket, plugConfig, socketConfig, plugResult, socketResult)
-	if err != nil {
-		return "", err
-	}
-	t, err := template.New("").Funcs(sprig.TxtFuncMap()).Delims("{%", "%}").Parse(body)
-	if err != nil {
-		return "", err
-	}
-	var buff bytes.Buffer
-	err = t.Execute(&buff, data)
+	funcMap template.FuncMap,
+) ([]string, error) {
+	documents, err := RenderDocuments(engine, strict, data, body, funcMap)
 	if err != nil {
-		return "", err
-	}
-	if buff.String() == "" {
-		return "", errors.New("failed to parse template in namespace '" + namespace + "'")
-	}
-	obj := unstructured.Unstructured{}
-	if _, _, err := decUnstructured.Decode(buff.Bytes(), nil, &obj); err != nil {
-		return "", err
-	}
-	bJson, err := json.Marshal(obj)
-	if err != nil {
-		return "", err
+		return nil, err
 	}
-	resultGjson := gjson.Parse(string(bJson)).Get("Object")
-	parsedNamespace := resultGjson.Get("metadata.namespace").String()
-	result := resultGjson.String()
-	if parsedNamespace == "" {
-		result, err = sjson.Set(result, "metadata.namespace", namespace)
+	return namespaceResources(documents, namespace)
+}
+
+// namespaceResources sets the namespace of the rendered resources, failing if a resource
+// belongs to another namespace
+func namespaceResources(documents []string, namespace string) ([]string, error) {
+	results := []string{}
+	for _, document := range documents {
+		obj := unstructured.Unstructured{}
+		if _, _, err := decUnstructured.Decode([]byte(document), nil, &obj); err != nil {
+			return nil, NewTemplateError(err)
+		}
+		bJson, err := json.Marshal(obj)
 		if err != nil {
-			return "", err
+			return nil, err
+		}
+		resultGjson := gjson.Parse(string(bJson)).Get("Object")
+		parsedNamespace := resultGjson.Get("metadata.namespace").String()
+		result := resultGjson.String()
+		if parsedNamespace == "" {
+			result, err = sjson.Set(result, "metadata.namespace", namespace)
+			if err != nil {
+				return nil, err
+			}
+		} else if parsedNamespace != namespace {
+			return nil, errors.New("resource namespace " + parsedNamespace + " must be " + namespace)
 		}
-	} else if parsedNamespace != namespace {
-		return "", errors.New("resource namespace " + parsedNamespace + " must be " + namespace)
+		results = append(results, result)
 	}
-	return result, nil
+	return results, nil
 }
 
-func (u *ResourceUtil) buildTemplateData(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
-	plugConfig *Config,
-	socketConfig *Config,
-	plugResult *Result,
-	socketResult *Result,
-) (map[string]interface{}, error) {
-	dataMap := map[string]interface{}{}
-	if plug != nil {
-		dataMap["plug"] = plug
-	}
-	if socket != nil {
-		dataMap["socket"] = socket
-	}
-	if plugConfig != nil {
-		dataMap["plugConfig"] = plugConfig
-	}
-	if socketConfig != nil {
-		dataMap["socketConfig"] = socketConfig
-	}
-	if plugResult != nil {
-		dataMap["plugResult"] = plugResult
-	}
-	if socketResult != nil {
-		dataMap["socketResult"] = socketResult
-	}
-	bData, err := json.Marshal(dataMap)
-	if err != nil {
-		return nil, err
+// describeResource gets the kind, namespace and name of the templated resource
+func describeResource(templatedResource string) string {
+	resource := gjson.Parse(templatedResource)
+	return resource.Get("kind").String() + " " + resource.Get("metadata.namespace").String() + "/" +
+		resource.Get("metadata.name").String()
+}
+
+type ResourceError struct {
+	err      error
+	resource string
+}
+
+func NewResourceError(err error) ResourceError {
+	return ResourceError{
+		err: err,
 	}
-	var data map[string]interface{}
-	if err := json.Unmarshal(bData, &data); err != nil {
-		return nil, err
+}
+
+// WithResource adds the resource that failed to the error
+func (e ResourceError) WithResource(resource string) ResourceError {
+	e.resource = resource
+	return e
+}
+
+func (e ResourceError) Error() string {
+	if e.resource != "" {
+		return e.resource + ": " + e.err.Error()
 	}
-	return data, nil
+	return e.err.Error()
+}
+
+func (e ResourceError) Unwrap() error {
+	return e.err
 }
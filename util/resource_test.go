@@ -0,0 +1,128 @@
+/**
+ * File: /util/resource_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 15:05:00
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ResourceUtil", func() {
+	var server *fakeAPIServer
+	var resourceUtil *util.ResourceUtil
+
+	configMaps := func(names ...string) string {
+		documents := ""
+		for _, name := range names {
+			documents += "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n"
+		}
+		return documents
+	}
+
+	newPlug := func(templates ...string) *integrationv1.Plug {
+		resources := []*integrationv1.Resource{}
+		for _, template := range templates {
+			resources = append(resources, &integrationv1.Resource{
+				ResourceAction: integrationv1.ResourceAction{
+					Do:             integrationv1.ApplyDo,
+					StringTemplate: template,
+				},
+				When: []integrationv1.When{integrationv1.CoupledWhen, integrationv1.UpdatedWhen},
+			})
+		}
+		return &integrationv1.Plug{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-plug", Namespace: "default", UID: "resource-plug-uid"},
+			Spec:       integrationv1.PlugSpec{Resources: resources},
+		}
+	}
+
+	BeforeEach(func() {
+		server = newFakeAPIServer(metav1.APIResource{
+			Name:       "configmaps",
+			Kind:       "ConfigMap",
+			Namespaced: true,
+			Verbs:      []string{"get", "list", "patch", "delete"},
+		})
+		resourceUtil = util.NewResourceUtil(util.WithRestConfig(context.Background(), server.config()))
+	})
+
+	It("records the applied objects in the status of the plug", func() {
+		plug := newPlug(configMaps("first", "second"))
+		Expect(resourceUtil.PlugCoupled(plug, nil, nil, nil)).To(Succeed())
+		Expect(server.has("configmaps", "default", "first")).To(BeTrue())
+		Expect(server.has("configmaps", "default", "second")).To(BeTrue())
+		Expect(plug.Status.AppliedResources).To(ConsistOf(
+			integrationv1.AppliedResource{
+				From: "plug.resources", Resource: 0, APIVersion: "v1", Kind: "ConfigMap", Name: "first", Namespace: "default",
+			},
+			integrationv1.AppliedResource{
+				From: "plug.resources", Resource: 0, APIVersion: "v1", Kind: "ConfigMap", Name: "second", Namespace: "default",
+			},
+		))
+	})
+
+	It("prunes the objects a re-render drops", func() {
+		plug := newPlug(configMaps("first", "second"))
+		Expect(resourceUtil.PlugCoupled(plug, nil, nil, nil)).To(Succeed())
+
+		plug.Spec.Resources[0].StringTemplate = configMaps("first")
+		Expect(resourceUtil.PlugUpdated(plug, nil, nil, nil)).To(Succeed())
+
+		Expect(server.has("configmaps", "default", "first")).To(BeTrue())
+		Expect(server.has("configmaps", "default", "second")).To(BeFalse())
+		Expect(plug.Status.AppliedResources).To(HaveLen(1))
+		Expect(plug.Status.AppliedResources[0].Name).To(Equal("first"))
+	})
+
+	It("prunes the objects of resources removed from the spec", func() {
+		plug := newPlug(configMaps("first"), configMaps("second"))
+		Expect(resourceUtil.PlugCoupled(plug, nil, nil, nil)).To(Succeed())
+		Expect(plug.Status.AppliedResources).To(HaveLen(2))
+
+		plug.Spec.Resources = plug.Spec.Resources[:1]
+		Expect(resourceUtil.PlugUpdated(plug, nil, nil, nil)).To(Succeed())
+
+		Expect(server.has("configmaps", "default", "first")).To(BeTrue())
+		Expect(server.has("configmaps", "default", "second")).To(BeFalse())
+		Expect(plug.Status.AppliedResources).To(HaveLen(1))
+	})
+
+	It("forgets the objects deleted when decoupled", func() {
+		plug := newPlug(configMaps("first"))
+		Expect(resourceUtil.PlugCoupled(plug, nil, nil, nil)).To(Succeed())
+
+		Expect(resourceUtil.PlugDecoupled(plug, nil, nil, nil)).To(Succeed())
+
+		Expect(server.has("configmaps", "default", "first")).To(BeFalse())
+		Expect(plug.Status.AppliedResources).To(BeEmpty())
+	})
+})
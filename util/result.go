@@ -28,38 +28,38 @@ package util
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 type ResultUtil struct {
-	client   *kubernetes.Clientset
-	ctx      context.Context
-	config   *ConfigUtil
-	resource *ResourceUtil
+	client          *kubernetes.Clientset
+	ctx             context.Context
+	generated       *GeneratedUtil
+	resource        *ResourceUtil
+	templateContext *TemplateContextUtil
 }
 
 func NewResultUtil(ctx context.Context) *ResultUtil {
 	return &ResultUtil{
-		client:   kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie()),
-		ctx:      ctx,
-		config:   NewConfigUtil(ctx),
-		resource: NewResourceUtil(ctx),
+		client:          kubernetes.NewForConfigOrDie(GetRestConfig(ctx)),
+		ctx:             ctx,
+		generated:       NewGeneratedUtil(ctx),
+		resource:        NewResourceUtil(ctx),
+		templateContext: NewTemplateContextUtil(ctx),
 	}
 }
 
 func (u *ResultUtil) GetResult(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig Config,
 	socketConfig Config,
-) (integrationv1beta1.CoupledResult, error) {
-	coupledResult := integrationv1beta1.CoupledResult{}
+) (integrationv1.CoupledResult, error) {
+	coupledResult := integrationv1.CoupledResult{}
 	plugResult, err := u.getPlugResult(plug, socket, &plugConfig, &socketConfig)
 	if err != nil {
 		return coupledResult, err
@@ -74,8 +74,8 @@ func (u *ResultUtil) GetResult(
 }
 
 func (u *ResultUtil) PlugTemplateResultResources(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig Config,
 	socketConfig Config,
 	plugResult Result,
@@ -92,6 +92,8 @@ func (u *ResultUtil) PlugTemplateResultResources(
 		plug.Namespace,
 		plug.Spec.ResultResources,
 		kubectlUtil,
+		u.generated.ForPlug(plug),
+		PlugTemplateOwner,
 	); err != nil {
 		return err
 	}
@@ -99,8 +101,8 @@ func (u *ResultUtil) PlugTemplateResultResources(
 }
 
 func (u *ResultUtil) SocketTemplateResultResources(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig Config,
 	socketConfig Config,
 	plugResult Result,
@@ -117,6 +119,8 @@ func (u *ResultUtil) SocketTemplateResultResources(
 		socket.Namespace,
 		socket.Spec.ResultResources,
 		kubectlUtil,
+		u.generated.ForSocket(socket, plug),
+		SocketTemplateOwner,
 	); err != nil {
 		return err
 	}
@@ -124,8 +128,8 @@ func (u *ResultUtil) SocketTemplateResultResources(
 }
 
 func (u *ResultUtil) getPlugResult(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) (map[string]string, error) {
@@ -149,10 +153,11 @@ func (u *ResultUtil) getPlugResult(
 		}
 	}
 	if plug.Spec.ResultTemplate != nil {
+		generated := u.generated.ForPlug(plug)
 		for key, value := range plug.Spec.ResultTemplate {
-			result, err := u.plugResultTemplateLookup(plug, value, socket)
+			result, err := u.plugResultTemplateLookup(plug, value, socket, plugConfig, socketConfig, generated)
 			if err != nil {
-				return nil, err
+				return nil, templateErrorLocation(err, "resultTemplate."+key)
 			}
 			plugResult[key] = result
 		}
@@ -181,8 +186,8 @@ func (u *ResultUtil) getPlugResult(
 }
 
 func (u *ResultUtil) getSocketResult(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
 	plugConfig *Config,
 	socketConfig *Config,
 ) (map[string]string, error) {
@@ -206,10 +211,11 @@ func (u *ResultUtil) getSocketResult(
 		}
 	}
 	if socket.Spec.ResultTemplate != nil {
+		generated := u.generated.ForSocket(socket, plug)
 		for key, value := range socket.Spec.ResultTemplate {
-			result, err := u.socketResultTemplateLookup(socket, value, plug)
+			result, err := u.socketResultTemplateLookup(socket, value, plug, plugConfig, socketConfig, generated)
 			if err != nil {
-				return nil, err
+				return nil, templateErrorLocation(err, "resultTemplate."+key)
 			}
 			socketResult[key] = result
 		}
@@ -235,8 +241,8 @@ func (u *ResultUtil) getSocketResult(
 }
 
 func (u *ResultUtil) validatePlugResult(
-	plug *integrationv1beta1.Plug,
-	resultInterface *integrationv1beta1.ResultInterface,
+	plug *integrationv1.Plug,
+	resultInterface *integrationv1.ResultInterface,
 	plugResult map[string]string,
 ) (map[string]string, error) {
 	if resultInterface == nil || resultInterface.Plug == nil {
@@ -258,7 +264,7 @@ func (u *ResultUtil) validatePlugResult(
 }
 
 func (u *ResultUtil) validateSocketResult(
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 	socketResult map[string]string,
 ) (map[string]string, error) {
 	if socket.Spec.Interface == nil {
@@ -283,98 +289,50 @@ func (u *ResultUtil) validateSocketResult(
 	return validatedSocketResult, nil
 }
 
-func (u *ResultUtil) plugResultTemplateLookup(plug *integrationv1beta1.Plug, resultTemplate string, socket *integrationv1beta1.Socket) (string, error) {
-	data, err := u.buildPlugResultTemplateData(*plug, socket)
+func (u *ResultUtil) plugResultTemplateLookup(
+	plug *integrationv1.Plug,
+	resultTemplate string,
+	socket *integrationv1.Socket,
+	plugConfig *Config,
+	socketConfig *Config,
+	generated *GeneratedValues,
+) (string, error) {
+	data, err := u.templateContext.Build(
+		PlugTemplateOwner,
+		ResultTemplateStage,
+		plug,
+		socket,
+		plugConfig,
+		socketConfig,
+		nil,
+		nil,
+	)
 	if err != nil {
 		return "", err
 	}
-	return Template(&data, resultTemplate)
+	return RenderTemplate(plug.Spec.ResultTemplateEngine, PlugStrictTemplates(plug), &data, resultTemplate, generated.FuncMap())
 }
 
 func (u *ResultUtil) socketResultTemplateLookup(
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 	resultTemplate string,
-	plug *integrationv1beta1.Plug,
+	plug *integrationv1.Plug,
+	plugConfig *Config,
+	socketConfig *Config,
+	generated *GeneratedValues,
 ) (string, error) {
-	data, err := u.buildSocketResultTemplateData(*socket, plug)
+	data, err := u.templateContext.Build(
+		SocketTemplateOwner,
+		ResultTemplateStage,
+		plug,
+		socket,
+		plugConfig,
+		socketConfig,
+		nil,
+		nil,
+	)
 	if err != nil {
 		return "", err
 	}
-	return Template(&data, resultTemplate)
-}
-
-func (u *ResultUtil) buildPlugResultTemplateData(
-	plug integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
-) (map[string]interface{}, error) {
-	kubectlUtil := NewKubectlUtil(u.ctx, plug.Namespace, EnsureServiceAccount(plug.Spec.ServiceAccountName))
-	dataMap := map[string]interface{}{}
-	dataMap["plug"] = plug
-	if socket != nil {
-		dataMap["socket"] = socket
-	}
-	plugData, err := u.config.dataUtil.GetPlugData(&plug)
-	if err != nil {
-		return dataMap, err
-	}
-	dataMap["plugData"] = plugData
-	socketData, err := u.config.dataUtil.GetSocketData(socket)
-	if err != nil {
-		return dataMap, err
-	}
-	dataMap["socketData"] = socketData
-	if plug.Spec.ResultVars != nil {
-		varsMap, err := u.config.varUtil.GetVars(plug.Namespace, plug.Spec.ResultVars, kubectlUtil, &plug, socket)
-		if err != nil {
-			return dataMap, err
-		}
-		dataMap["resultVars"] = varsMap
-	}
-	bData, err := json.Marshal(dataMap)
-	if err != nil {
-		return nil, err
-	}
-	var data map[string]interface{}
-	if err := json.Unmarshal(bData, &data); err != nil {
-		return nil, err
-	}
-	return data, nil
-}
-
-func (u *ResultUtil) buildSocketResultTemplateData(
-	socket integrationv1beta1.Socket,
-	plug *integrationv1beta1.Plug,
-) (map[string]interface{}, error) {
-	kubectlUtil := NewKubectlUtil(u.ctx, socket.Namespace, EnsureServiceAccount(socket.Spec.ServiceAccountName))
-	dataMap := map[string]interface{}{}
-	dataMap["socket"] = socket
-	if plug != nil {
-		dataMap["plug"] = plug
-	}
-	socketData, err := u.config.dataUtil.GetSocketData(&socket)
-	if err != nil {
-		return dataMap, err
-	}
-	dataMap["socketData"] = socketData
-	plugData, err := u.config.dataUtil.GetPlugData(plug)
-	if err != nil {
-		return dataMap, err
-	}
-	dataMap["plugData"] = plugData
-	if socket.Spec.ResultVars != nil {
-		varsMap, err := u.config.varUtil.GetVars(socket.Namespace, socket.Spec.ResultVars, kubectlUtil, plug, &socket)
-		if err != nil {
-			return dataMap, err
-		}
-		dataMap["vars"] = varsMap
-	}
-	bData, err := json.Marshal(dataMap)
-	if err != nil {
-		return nil, err
-	}
-	var data map[string]interface{}
-	if err := json.Unmarshal(bData, &data); err != nil {
-		return nil, err
-	}
-	return data, nil
+	return RenderTemplate(socket.Spec.ResultTemplateEngine, SocketStrictTemplates(socket), &data, resultTemplate, generated.FuncMap())
 }
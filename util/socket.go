@@ -28,16 +28,19 @@ package util
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apiserver/pkg/registry/generic/registry"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -47,6 +50,7 @@ type SocketUtil struct {
 	client         *client.Client
 	ctx            context.Context
 	namespacedName types.NamespacedName
+	recorder       record.EventRecorder
 	req            *ctrl.Request
 }
 
@@ -54,7 +58,8 @@ func NewSocketUtil(
 	client *client.Client,
 	ctx context.Context,
 	req *ctrl.Request,
-	namespacedName *integrationv1beta1.NamespacedName,
+	namespacedName *integrationv1.NamespacedName,
+	recorder record.EventRecorder,
 ) *SocketUtil {
 	operatorNamespace := GetOperatorNamespace()
 	return &SocketUtil{
@@ -62,21 +67,22 @@ func NewSocketUtil(
 		client:         client,
 		ctx:            ctx,
 		namespacedName: EnsureNamespacedName(namespacedName, operatorNamespace),
+		recorder:       recorder,
 		req:            req,
 	}
 }
 
-func (u *SocketUtil) Get() (*integrationv1beta1.Socket, error) {
+func (u *SocketUtil) Get() (*integrationv1.Socket, error) {
 	client := *u.client
 	ctx := u.ctx
-	socket := &integrationv1beta1.Socket{}
+	socket := &integrationv1.Socket{}
 	if err := client.Get(ctx, u.namespacedName, socket); err != nil {
 		return nil, err
 	}
 	return socket.DeepCopy(), nil
 }
 
-func (u *SocketUtil) Update(socket *integrationv1beta1.Socket, requeue bool) (ctrl.Result, error) {
+func (u *SocketUtil) Update(socket *integrationv1.Socket, requeue bool) (ctrl.Result, error) {
 	client := *u.client
 	ctx := u.ctx
 	if err := client.Update(ctx, socket); err != nil {
@@ -86,11 +92,25 @@ func (u *SocketUtil) Update(socket *integrationv1beta1.Socket, requeue bool) (ct
 }
 
 func (u *SocketUtil) UpdateStatus(
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 	requeue bool,
 ) (ctrl.Result, error) {
 	client := *u.client
 	ctx := u.ctx
+	if err := u.setSummaryStatus(socket, nil); err != nil {
+		return ctrl.Result{}, err
+	}
+	if health := GetApparatusHealth(socket.UID); health != nil && socket.Spec.Apparatus != nil {
+		SetCondition(
+			&socket.Status.Conditions,
+			&socket.Status.ConditionHistory,
+			socket.Generation,
+			ConditionTypeApparatusReachable,
+			health.Ready,
+			string(health.Reason),
+			health.Message,
+		)
+	}
 	if err := client.Status().Update(ctx, socket); err != nil {
 		if strings.Contains(err.Error(), registry.OptimisticLockErrorMsg) {
 			return ctrl.Result{Requeue: true}, nil
@@ -100,7 +120,36 @@ func (u *SocketUtil) UpdateStatus(
 	return ctrl.Result{Requeue: requeue}, nil
 }
 
-func (u *SocketUtil) Delete(socket *integrationv1beta1.Socket) (ctrl.Result, error) {
+// UpdateSummaryStatus updates the summary of the plugs referencing the socket
+// if it changed, preferring the given plug over its possibly stale cached copy
+func (u *SocketUtil) UpdateSummaryStatus(
+	socket *integrationv1.Socket,
+	plug *integrationv1.Plug,
+) error {
+	if socket == nil {
+		var err error
+		socket, err = u.Get()
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+	summary := socket.Status.Summary.DeepCopy()
+	if err := u.setSummaryStatus(socket, plug); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(summary, socket.Status.Summary) {
+		return nil
+	}
+	if err := (*u.client).Status().Update(u.ctx, socket); err != nil {
+		if strings.Contains(err.Error(), registry.OptimisticLockErrorMsg) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (u *SocketUtil) Delete(socket *integrationv1.Socket) (ctrl.Result, error) {
 	client := *u.client
 	ctx := u.ctx
 	if err := client.Delete(ctx, socket); err != nil {
@@ -110,7 +159,7 @@ func (u *SocketUtil) Delete(socket *integrationv1beta1.Socket) (ctrl.Result, err
 }
 
 func (u *SocketUtil) GetCoupledCondition(
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 ) (*metav1.Condition, error) {
 	if socket == nil {
 		var err error
@@ -123,7 +172,7 @@ func (u *SocketUtil) GetCoupledCondition(
 	return coupledCondition, nil
 }
 
-func (u *SocketUtil) CoupledPlugExists(coupledPlugs []*integrationv1beta1.CoupledPlug, plugUid types.UID) bool {
+func (u *SocketUtil) CoupledPlugExists(coupledPlugs []*integrationv1.CoupledPlug, plugUid types.UID) bool {
 	for _, coupledPlug := range coupledPlugs {
 		if coupledPlug.UID == plugUid {
 			return true
@@ -132,7 +181,7 @@ func (u *SocketUtil) CoupledPlugExists(coupledPlugs []*integrationv1beta1.Couple
 	return false
 }
 
-func (u *SocketUtil) Error(err error, socket *integrationv1beta1.Socket) (ctrl.Result, error) {
+func (u *SocketUtil) Error(err error, socket *integrationv1.Socket) (ctrl.Result, error) {
 	e := err
 	if socket == nil {
 		var err error
@@ -141,6 +190,13 @@ func (u *SocketUtil) Error(err error, socket *integrationv1beta1.Socket) (ctrl.R
 			return ctrl.Result{}, err
 		}
 	}
+	if pendingError, ok := IsApparatusPending(err); ok {
+		// the pending operation is kept in the status until it completes
+		if _, err := u.UpdateStatus(socket, false); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: pendingError.RequeueAfter()}, nil
+	}
 	if u.apparatusUtil.NotRunning(err) {
 		requeueAfter := time.Duration(time.Second.Nanoseconds() * 10)
 		started, err := u.apparatusUtil.StartFromSocket(socket, &requeueAfter)
@@ -164,8 +220,8 @@ func (u *SocketUtil) Error(err error, socket *integrationv1beta1.Socket) (ctrl.R
 
 func (u *SocketUtil) UpdateCoupledStatus(
 	conditionCoupledReason ConditionCoupledReason,
-	socket *integrationv1beta1.Socket,
-	appendPlug *integrationv1beta1.Plug,
+	socket *integrationv1.Socket,
+	appendPlug *integrationv1.Plug,
 	requeue bool,
 ) (ctrl.Result, error) {
 	if socket == nil {
@@ -176,7 +232,7 @@ func (u *SocketUtil) UpdateCoupledStatus(
 		}
 	}
 	if appendPlug != nil {
-		if err := u.appendCoupledPlugStatus(socket, appendPlug); err != nil {
+		if err := u.appendCoupledPlugStatus(socket, appendPlug, ""); err != nil {
 			return u.Error(err, socket)
 		}
 	}
@@ -188,7 +244,7 @@ func (u *SocketUtil) UpdateCoupledStatus(
 
 func (u *SocketUtil) UpdateErrorStatus(
 	err error,
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 ) (ctrl.Result, error) {
 	e := err
 	if socket == nil {
@@ -201,6 +257,7 @@ func (u *SocketUtil) UpdateErrorStatus(
 	if err = u.setErrorStatus(e, socket); err != nil {
 		return ctrl.Result{}, err
 	}
+	SocketWarning(u.recorder, e, socket)
 	if _, err := u.UpdateStatus(socket, true); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -212,7 +269,7 @@ func (u *SocketUtil) UpdateErrorStatus(
 
 func (u *SocketUtil) UpdateRemoveCoupledPlugStatus(
 	plugUid types.UID,
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 	requeue bool,
 ) (ctrl.Result, error) {
 	if socket == nil {
@@ -233,8 +290,9 @@ func (u *SocketUtil) UpdateRemoveCoupledPlugStatus(
 }
 
 func (u *SocketUtil) UpdateAppendCoupledPlugStatus(
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	approvedBy string,
 	requeue bool,
 ) (ctrl.Result, error) {
 	if socket == nil {
@@ -244,7 +302,7 @@ func (u *SocketUtil) UpdateAppendCoupledPlugStatus(
 			return ctrl.Result{}, err
 		}
 	}
-	if err := u.appendCoupledPlugStatus(socket, plug); err != nil {
+	if err := u.appendCoupledPlugStatus(socket, plug, approvedBy); err != nil {
 		return u.Error(err, socket)
 	}
 	return u.UpdateStatus(socket, requeue)
@@ -252,7 +310,7 @@ func (u *SocketUtil) UpdateAppendCoupledPlugStatus(
 
 func (u *SocketUtil) RemoveCoupledPlugStatus(
 	plugUid types.UID,
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 ) (bool, error) {
 	if socket == nil {
 		var err error
@@ -261,7 +319,7 @@ func (u *SocketUtil) RemoveCoupledPlugStatus(
 			return false, err
 		}
 	}
-	coupledPlugs := []*integrationv1beta1.CoupledPlug{}
+	coupledPlugs := []*integrationv1.CoupledPlug{}
 	removedCoupledPlug := false
 	for _, coupledPlug := range socket.Status.CoupledPlugs {
 		if coupledPlug.UID == plugUid {
@@ -282,17 +340,25 @@ func (u *SocketUtil) RemoveCoupledPlugStatus(
 }
 
 func (u *SocketUtil) appendCoupledPlugStatus(
-	socket *integrationv1beta1.Socket,
-	plug *integrationv1beta1.Plug,
+	socket *integrationv1.Socket,
+	plug *integrationv1.Plug,
+	approvedBy string,
 ) error {
 	if !u.CoupledPlugExists(socket.Status.CoupledPlugs, plug.UID) {
-		socket.Status.CoupledPlugs = append(socket.Status.CoupledPlugs, &integrationv1beta1.CoupledPlug{
+		socket.Status.CoupledPlugs = append(socket.Status.CoupledPlugs, &integrationv1.CoupledPlug{
 			APIVersion: plug.APIVersion,
 			Kind:       plug.Kind,
 			Name:       plug.Name,
 			Namespace:  plug.Namespace,
 			UID:        plug.UID,
+			ApprovedBy: approvedBy,
 		})
+	} else if approvedBy != "" {
+		for _, coupledPlug := range socket.Status.CoupledPlugs {
+			if coupledPlug.UID == plug.UID {
+				coupledPlug.ApprovedBy = approvedBy
+			}
+		}
 	}
 	u.setCoupledStatusCondition(SocketCoupled, "", socket)
 	return nil
@@ -301,7 +367,7 @@ func (u *SocketUtil) appendCoupledPlugStatus(
 func (u *SocketUtil) setCoupledStatusCondition(
 	conditionCoupledReason ConditionCoupledReason,
 	message string,
-	socket *integrationv1beta1.Socket,
+	socket *integrationv1.Socket,
 ) {
 	coupledStatus := false
 	coupledPlugsCount := len(socket.Status.CoupledPlugs)
@@ -321,9 +387,6 @@ func (u *SocketUtil) setCoupledStatusCondition(
 			message = "0 plugs coupled"
 		}
 	}
-	if conditionCoupledReason != Error {
-		socket.Status.Conditions = []metav1.Condition{}
-	}
 	if conditionCoupledReason == SocketCoupled {
 		if coupledPlugsCount > 0 {
 			coupledStatus = true
@@ -331,20 +394,85 @@ func (u *SocketUtil) setCoupledStatusCondition(
 			conditionCoupledReason = SocketEmpty
 		}
 	}
-	condition := metav1.Condition{
-		Message:            message,
-		ObservedGeneration: socket.Generation,
-		Reason:             string(conditionCoupledReason),
-		Status:             "False",
-		Type:               string(ConditionTypeCoupled),
+	if conditionCoupledReason != Error {
+		SetCondition(
+			&socket.Status.Conditions,
+			&socket.Status.ConditionHistory,
+			socket.Generation,
+			ConditionTypeFailed,
+			false,
+			string(conditionCoupledReason),
+			"",
+		)
+	}
+	SetCondition(
+		&socket.Status.Conditions,
+		&socket.Status.ConditionHistory,
+		socket.Generation,
+		ConditionTypeCoupled,
+		coupledStatus,
+		string(conditionCoupledReason),
+		message,
+	)
+	u.setReadyCondition(socket)
+}
+
+func (u *SocketUtil) setReadyCondition(socket *integrationv1.Socket) {
+	failedCondition := meta.FindStatusCondition(socket.Status.Conditions, string(ConditionTypeFailed))
+	if failedCondition != nil && failedCondition.Status == metav1.ConditionTrue {
+		SetCondition(
+			&socket.Status.Conditions,
+			&socket.Status.ConditionHistory,
+			socket.Generation,
+			ConditionTypeReady,
+			false,
+			failedCondition.Reason,
+			failedCondition.Message,
+		)
+		return
+	}
+	SetCondition(
+		&socket.Status.Conditions,
+		&socket.Status.ConditionHistory,
+		socket.Generation,
+		ConditionTypeReady,
+		true,
+		"SocketReady",
+		"socket ready",
+	)
+}
+
+func (u *SocketUtil) setSummaryStatus(
+	socket *integrationv1.Socket,
+	updatedPlug *integrationv1.Plug,
+) error {
+	plugs := &integrationv1.PlugList{}
+	if err := (*u.client).List(u.ctx, plugs, client.MatchingFields{
+		PlugSocketField: socket.Namespace + "/" + socket.Name,
+	}); err != nil {
+		return err
 	}
-	if coupledStatus {
-		condition.Status = "True"
+	summary := &integrationv1.SocketSummary{}
+	for _, plug := range plugs.Items {
+		if updatedPlug != nil && plug.UID == updatedPlug.UID {
+			plug = *updatedPlug
+		}
+		if plug.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if meta.IsStatusConditionTrue(plug.Status.Conditions, string(ConditionTypeCoupled)) {
+			summary.Coupled++
+		} else if meta.IsStatusConditionTrue(plug.Status.Conditions, string(ConditionTypeFailed)) {
+			summary.Failed++
+		} else {
+			summary.Pending++
+		}
 	}
-	meta.SetStatusCondition(&socket.Status.Conditions, condition)
+	socket.Status.Summary = summary
+	return nil
 }
 
-func (u *SocketUtil) setErrorStatus(err error, socket *integrationv1beta1.Socket) error {
+func (u *SocketUtil) setErrorStatus(err error, socket *integrationv1.Socket) error {
 	e := err
 	if e == nil {
 		return nil
@@ -356,22 +484,42 @@ func (u *SocketUtil) setErrorStatus(err error, socket *integrationv1beta1.Socket
 		return nil
 	}
 	message := e.Error()
+	reason := Error
+	var templateError TemplateError
+	if errors.As(e, &templateError) {
+		reason = TemplateInvalid
+	}
 	coupledCondition, err := u.GetCoupledCondition(socket)
 	if err != nil {
 		return err
 	}
 	if coupledCondition != nil {
-		u.setCoupledStatusCondition(Error, "coupling failed", socket)
-	}
-	failedCondition := metav1.Condition{
-		Message:            message,
-		ObservedGeneration: socket.Generation,
-		Reason:             "Error",
-		Status:             "True",
-		Type:               string(ConditionTypeFailed),
-	}
-	meta.SetStatusCondition(&socket.Status.Conditions, failedCondition)
+		u.setCoupledStatusCondition(reason, "coupling failed", socket)
+	}
+	SetCondition(
+		&socket.Status.Conditions,
+		&socket.Status.ConditionHistory,
+		socket.Generation,
+		ConditionTypeFailed,
+		true,
+		string(reason),
+		message,
+	)
+	u.setReadyCondition(socket)
 	return nil
 }
 
 var GlobalSocketMutex *sync.Mutex = &sync.Mutex{}
+
+// PlugSocketField is the field index of plugs by the namespace and name of the
+// socket they reference, which must be registered with the manager
+const PlugSocketField = "spec.socket"
+
+// IndexPlugSocket extracts the value of the PlugSocketField index from a plug
+func IndexPlugSocket(obj client.Object) []string {
+	plug, ok := obj.(*integrationv1.Plug)
+	if !ok {
+		return nil
+	}
+	return []string{Default(plug.Spec.Socket.Namespace, plug.Namespace) + "/" + plug.Spec.Socket.Name}
+}
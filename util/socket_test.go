@@ -0,0 +1,87 @@
+/**
+ * File: /util/socket_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 20:41:18
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Socket summary", func() {
+	plug := func(
+		name string,
+		namespace string,
+		socket integrationv1.NamespacedName,
+		conditionType util.ConditionType,
+	) *integrationv1.Plug {
+		plug := &integrationv1.Plug{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, UID: types.UID(namespace + "/" + name)},
+			Spec:       integrationv1.PlugSpec{Socket: socket},
+		}
+		if conditionType != "" {
+			plug.Status.Conditions = []metav1.Condition{{
+				Type:   string(conditionType),
+				Status: metav1.ConditionTrue,
+				Reason: string(conditionType),
+			}}
+		}
+		return plug
+	}
+
+	It("should only count the plugs referencing the socket", func() {
+		socket := &integrationv1.Socket{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "db"},
+		}
+		postgres := integrationv1.NamespacedName{Name: "postgres", Namespace: "db"}
+		scheme := runtime.NewScheme()
+		Expect(integrationv1.AddToScheme(scheme)).To(Succeed())
+		var fakeClient client.Client = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithIndex(&integrationv1.Plug{}, util.PlugSocketField, util.IndexPlugSocket).
+			WithObjects(
+				socket,
+				plug("app", "app", postgres, util.ConditionTypeCoupled),
+				plug("api", "api", postgres, util.ConditionTypeFailed),
+				plug("worker", "db", integrationv1.NamespacedName{Name: "postgres"}, ""),
+				plug("other", "app", integrationv1.NamespacedName{Name: "postgres", Namespace: "app"}, ""),
+			).
+			Build()
+		socketUtil := util.NewSocketUtil(&fakeClient, context.Background(), nil, &postgres, nil)
+		updatedPlug := plug("worker", "db", integrationv1.NamespacedName{Name: "postgres"}, util.ConditionTypeCoupled)
+		Expect(socketUtil.UpdateSummaryStatus(socket, updatedPlug)).To(Succeed())
+		Expect(socket.Status.Summary).To(Equal(&integrationv1.SocketSummary{Coupled: 2, Failed: 1}))
+	})
+})
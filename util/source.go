@@ -0,0 +1,384 @@
+/**
+ * File: /util/source.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 08:37:26
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+const kustomizeSourceRoot = "/source"
+
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+var helmChartRootFileNames = []string{"Chart.yaml", "values.yaml", "values.schema.json", ".helmignore"}
+
+type sourceFile struct {
+	name string
+	data []byte
+}
+
+// renderSource renders the resources of the kustomization or helm chart of the source
+func (u *ResourceUtil) renderSource(
+	source *integrationv1.ResourceSource,
+	engine integrationv1.TemplateEngine,
+	strict bool,
+	data map[string]interface{},
+	namespace string,
+	funcMap template.FuncMap,
+	kubectlUtil *KubectlUtil,
+) ([]string, error) {
+	files, err := u.loadSource(source, namespace, kubectlUtil)
+	if err != nil {
+		return nil, NewResourceError(err).WithResource(string(source.Kind) + " source")
+	}
+	switch source.Kind {
+	case integrationv1.KustomizeSourceKind:
+		return renderKustomization(files, strict, data, funcMap)
+	case integrationv1.HelmSourceKind:
+		values := map[string]interface{}{}
+		if strings.TrimSpace(source.Values) != "" {
+			renderedValues, err := RenderTemplate(engine, strict, &data, source.Values, funcMap)
+			if err != nil {
+				return nil, err
+			}
+			if err := yaml.Unmarshal([]byte(renderedValues), &values); err != nil {
+				return nil, NewTemplateError(err)
+			}
+		}
+		return renderHelmChart(files, source.ReleaseName, namespace, values)
+	}
+	return nil, NewTemplateError(errors.New("source kind '" + string(source.Kind) + "' is not supported"))
+}
+
+// loadSource loads the files of the source from a configmap, the sources directory or an oci artifact
+func (u *ResourceUtil) loadSource(
+	source *integrationv1.ResourceSource,
+	namespace string,
+	kubectlUtil *KubectlUtil,
+) ([]sourceFile, error) {
+	if source.ConfigMap != nil {
+		return u.loadConfigMapSource(source, namespace, kubectlUtil)
+	}
+	if source.Path != "" {
+		return loadPathSource(source.Path)
+	}
+	if source.OCI != nil {
+		archive, err := u.pullOCIArchive(source.OCI, namespace, kubectlUtil)
+		if err != nil {
+			return nil, err
+		}
+		return extractArchive(archive)
+	}
+	return nil, errors.New("source must have a configMap, path or oci")
+}
+
+// loadConfigMapSource loads the archive in the key of the configmap, or every key of the configmap
+// as a file where the keys of a helm chart other than its root files are templates
+func (u *ResourceUtil) loadConfigMapSource(
+	source *integrationv1.ResourceSource,
+	namespace string,
+	kubectlUtil *KubectlUtil,
+) ([]sourceFile, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]string{
+			"name":      source.ConfigMap.Name,
+			"namespace": namespace,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	configMap, err := kubectlUtil.Get(body)
+	if err != nil {
+		return nil, err
+	}
+	configMapData, _, _ := unstructured.NestedStringMap(configMap.Object, "data")
+	binaryData, _, _ := unstructured.NestedStringMap(configMap.Object, "binaryData")
+	if source.ConfigMap.Key != "" {
+		if value, ok := binaryData[source.ConfigMap.Key]; ok {
+			archive, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, err
+			}
+			return extractArchive(archive)
+		}
+		if value, ok := configMapData[source.ConfigMap.Key]; ok {
+			return extractArchive([]byte(value))
+		}
+		return nil, errors.New("configmap " + source.ConfigMap.Name + " has no key " + source.ConfigMap.Key)
+	}
+	files := []sourceFile{}
+	for key, value := range configMapData {
+		files = append(files, sourceFile{name: key, data: []byte(value)})
+	}
+	for key, value := range binaryData {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, sourceFile{name: key, data: decoded})
+	}
+	if source.Kind == integrationv1.HelmSourceKind {
+		for i, file := range files {
+			if !containsString(helmChartRootFileNames, file.name) {
+				files[i].name = "templates/" + file.name
+			}
+		}
+	}
+	return files, nil
+}
+
+// loadPathSource loads the directory or archive at the path in the sources directory
+func loadPathSource(sourcePath string) ([]sourceFile, error) {
+	root := Default(config.ResourceSourcesPath, "/sources")
+	fullPath := filepath.Join(root, filepath.Clean("/"+sourcePath))
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		archive, err := os.ReadFile(fullPath)
+		if err != nil {
+			return nil, err
+		}
+		return extractArchive(archive)
+	}
+	files := []sourceFile{}
+	size := 0
+	if err := filepath.WalkDir(fullPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		size += len(data)
+		if size > config.ResourceSourceMaxSize {
+			return errors.New("source " + sourcePath + " is larger than " + strconv.Itoa(config.ResourceSourceMaxSize) + " bytes")
+		}
+		name, err := filepath.Rel(fullPath, filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, sourceFile{name: filepath.ToSlash(name), data: data})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// extractArchive extracts the regular files of the gzipped tar archive, removing the directory
+// that packaged charts and most archives wrap their files in
+func extractArchive(archive []byte) ([]sourceFile, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+	files := []sourceFile{}
+	size := int64(0)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, errors.New("archive file " + header.Name + " is outside of the archive")
+		}
+		size += header.Size
+		if size > int64(config.ResourceSourceMaxSize) {
+			return nil, errors.New("archive is larger than " + strconv.Itoa(config.ResourceSourceMaxSize) + " bytes")
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, sourceFile{name: name, data: data})
+	}
+	if len(files) == 0 {
+		return files, nil
+	}
+	prefix, _, ok := strings.Cut(files[0].name, "/")
+	if !ok {
+		return files, nil
+	}
+	for _, file := range files {
+		if !strings.HasPrefix(file.name, prefix+"/") {
+			return files, nil
+		}
+	}
+	for i := range files {
+		files[i].name = strings.TrimPrefix(files[i].name, prefix+"/")
+	}
+	return files, nil
+}
+
+// renderKustomization builds the kustomization at the root of the files, rendering the
+// kustomization file as a go template first so it can use the template data
+func renderKustomization(
+	files []sourceFile,
+	strict bool,
+	data map[string]interface{},
+	funcMap template.FuncMap,
+) ([]string, error) {
+	fileSystem := filesys.MakeFsInMemory()
+	for _, file := range files {
+		content := file.data
+		if containsString(kustomizationFileNames, file.name) {
+			rendered, err := executeTemplate(&data, string(content), strict, funcMap)
+			if err != nil {
+				return nil, err
+			}
+			content = []byte(rendered)
+		}
+		if err := fileSystem.WriteFile(path.Join(kustomizeSourceRoot, file.name), content); err != nil {
+			return nil, err
+		}
+	}
+	resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fileSystem, kustomizeSourceRoot)
+	if err != nil {
+		return nil, NewTemplateError(err)
+	}
+	output, err := resMap.AsYaml()
+	if err != nil {
+		return nil, NewTemplateError(err)
+	}
+	items, err := decodeYAMLDocuments(string(output))
+	if err != nil {
+		return nil, err
+	}
+	return flattenDocuments(items)
+}
+
+// renderHelmChart renders the templates and crds of the helm chart, leaving out hooks since
+// they are not run
+func renderHelmChart(
+	files []sourceFile,
+	releaseName string,
+	namespace string,
+	values map[string]interface{},
+) ([]string, error) {
+	bufferedFiles := []*loader.BufferedFile{}
+	for _, file := range files {
+		bufferedFiles = append(bufferedFiles, &loader.BufferedFile{Name: file.name, Data: file.data})
+	}
+	chart, err := loader.LoadFiles(bufferedFiles)
+	if err != nil {
+		return nil, NewTemplateError(err)
+	}
+	if err := chartutil.ProcessDependencies(chart, values); err != nil {
+		return nil, NewTemplateError(err)
+	}
+	renderValues, err := chartutil.ToRenderValues(chart, values, chartutil.ReleaseOptions{
+		Name:      Default(releaseName, chart.Metadata.Name),
+		Namespace: namespace,
+		Revision:  1,
+		IsInstall: true,
+	}, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, NewTemplateError(err)
+	}
+	rendered, err := engine.Render(chart, renderValues)
+	if err != nil {
+		return nil, NewTemplateError(err)
+	}
+	items := []interface{}{}
+	for _, crd := range chart.CRDObjects() {
+		crdItems, err := decodeYAMLDocuments(string(crd.File.Data))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, crdItems...)
+	}
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		if path.Ext(name) == ".yaml" || path.Ext(name) == ".yml" || path.Ext(name) == ".json" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		templateItems, err := decodeYAMLDocuments(rendered[name])
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range templateItems {
+			if obj, ok := item.(map[string]interface{}); ok {
+				if _, found, _ := unstructured.NestedString(obj, "metadata", "annotations", "helm.sh/hook"); found {
+					continue
+				}
+			}
+			items = append(items, item)
+		}
+	}
+	return flattenDocuments(items)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
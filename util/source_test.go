@@ -0,0 +1,276 @@
+/**
+ * File: /util/source_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 09:12:40
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Resource sources", func() {
+	var server *fakeAPIServer
+	var kubectlUtil *util.KubectlUtil
+
+	data := map[string]interface{}{
+		"plug":       map[string]interface{}{"metadata": map[string]interface{}{"name": "app"}},
+		"plugConfig": map[string]interface{}{"replicas": 3, "color": "blue"},
+	}
+
+	chartFiles := map[string]string{
+		"app/Chart.yaml":  "apiVersion: v2\nname: app\nversion: 1.0.0\n",
+		"app/values.yaml": "color: red\nreplicas: 1\n",
+		"app/templates/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-settings
+data:
+  color: {{ .Values.color | quote }}
+  replicas: {{ .Values.replicas | quote }}
+`,
+		"app/templates/hook.yaml": `apiVersion: v1
+kind: Pod
+metadata:
+  name: {{ .Release.Name }}-test
+  annotations:
+    helm.sh/hook: test
+`,
+		"app/templates/NOTES.txt": "installed {{ .Release.Name }}\n",
+	}
+
+	archive := func(files map[string]string) []byte {
+		buffer := bytes.Buffer{}
+		gzipWriter := gzip.NewWriter(&buffer)
+		tarWriter := tar.NewWriter(gzipWriter)
+		for name, content := range files {
+			Expect(tarWriter.WriteHeader(&tar.Header{
+				Name:     name,
+				Mode:     0o644,
+				Size:     int64(len(content)),
+				Typeflag: tar.TypeReg,
+			})).To(Succeed())
+			_, err := tarWriter.Write([]byte(content))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(tarWriter.Close()).To(Succeed())
+		Expect(gzipWriter.Close()).To(Succeed())
+		return buffer.Bytes()
+	}
+
+	BeforeEach(func() {
+		server = newFakeAPIServer(
+			metav1.APIResource{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"get"}},
+		)
+		kubectlUtil = util.NewTestKubectlUtil(context.Background(), server.config())
+	})
+
+	configMap := func(name string, configMapData map[string]interface{}, binaryData map[string]interface{}) {
+		server.add("configmaps", map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+			"data":       configMapData,
+			"binaryData": binaryData,
+		})
+	}
+
+	It("should build a kustomization from the keys of a configmap", func() {
+		configMap("kustomization", map[string]interface{}{
+			"kustomization.yaml": `namePrefix: {% .plug.metadata.name %}-
+resources:
+  - deployment.yaml
+replicas:
+  - name: web
+    count: {% .plugConfig.replicas %}
+`,
+			"deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`,
+		}, nil)
+		resources, err := util.RenderTestSource(context.Background(), &integrationv1.ResourceSource{
+			Kind:      integrationv1.KustomizeSourceKind,
+			ConfigMap: &integrationv1.SourceConfigMap{Name: "kustomization"},
+		}, data, "default", nil, kubectlUtil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(HaveLen(1))
+		Expect(resources[0]).To(ContainSubstring(`"name":"app-web"`))
+		Expect(resources[0]).To(ContainSubstring(`"namespace":"default"`))
+		Expect(resources[0]).To(ContainSubstring(`"replicas":3`))
+	})
+
+	It("should render a packaged helm chart from a configmap key with templated values", func() {
+		configMap("chart", nil, map[string]interface{}{
+			"app.tgz": base64.StdEncoding.EncodeToString(archive(chartFiles)),
+		})
+		resources, err := util.RenderTestSource(context.Background(), &integrationv1.ResourceSource{
+			Kind:        integrationv1.HelmSourceKind,
+			ConfigMap:   &integrationv1.SourceConfigMap{Name: "chart", Key: "app.tgz"},
+			Values:      "color: {% .plugConfig.color %}\n",
+			ReleaseName: "db",
+		}, data, "default", nil, kubectlUtil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(HaveLen(1))
+		Expect(resources[0]).To(ContainSubstring(`"color":"blue"`))
+		Expect(resources[0]).To(ContainSubstring(`"replicas":"1"`))
+		Expect(resources[0]).To(ContainSubstring(`"name":"db-settings"`))
+	})
+
+	It("should render a helm chart from the sources path", func() {
+		sourcesPath := GinkgoT().TempDir()
+		for name, content := range chartFiles {
+			filePath := filepath.Join(sourcesPath, "charts", name)
+			Expect(os.MkdirAll(filepath.Dir(filePath), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filePath, []byte(content), 0o644)).To(Succeed())
+		}
+		previousPath := config.ResourceSourcesPath
+		config.ResourceSourcesPath = sourcesPath
+		DeferCleanup(func() {
+			config.ResourceSourcesPath = previousPath
+		})
+		resources, err := util.RenderTestSource(context.Background(), &integrationv1.ResourceSource{
+			Kind: integrationv1.HelmSourceKind,
+			Path: "../../charts/app",
+		}, data, "default", nil, kubectlUtil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(HaveLen(1))
+		Expect(resources[0]).To(ContainSubstring(`"name":"app-settings"`))
+		Expect(resources[0]).To(ContainSubstring(`"color":"red"`))
+	})
+
+	It("should pull a helm chart from an oci registry", func() {
+		layer := archive(chartFiles)
+		sum := sha256.Sum256(layer)
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v2/charts/app/manifests/1.0.0":
+				w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+				Expect(json.NewEncoder(w).Encode(map[string]interface{}{
+					"schemaVersion": 2,
+					"layers": []interface{}{
+						map[string]interface{}{
+							"mediaType": "application/vnd.cncf.helm.chart.content.v1.tar+gzip",
+							"digest":    digest,
+							"size":      len(layer),
+						},
+					},
+				})).To(Succeed())
+			case "/v2/charts/app/blobs/" + digest:
+				_, err := w.Write(layer)
+				Expect(err).NotTo(HaveOccurred())
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		DeferCleanup(registry.Close)
+		resources, err := util.RenderTestSource(context.Background(), &integrationv1.ResourceSource{
+			Kind: integrationv1.HelmSourceKind,
+			OCI: &integrationv1.SourceOCI{
+				Ref:      "oci://" + strings.TrimPrefix(registry.URL, "http://") + "/charts/app:1.0.0",
+				Insecure: true,
+			},
+		}, data, "default", nil, kubectlUtil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(HaveLen(1))
+		Expect(resources[0]).To(ContainSubstring(`"name":"app-settings"`))
+	})
+
+	It("should stop reading an oci blob at the max size whatever its manifest claims", func() {
+		maxSize := config.ResourceSourceMaxSize
+		config.ResourceSourceMaxSize = 1024
+		DeferCleanup(func() {
+			config.ResourceSourceMaxSize = maxSize
+		})
+		layer := bytes.Repeat([]byte("a"), 4096)
+		sum := sha256.Sum256(layer)
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v2/charts/big/manifests/1.0.0":
+				w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+				Expect(json.NewEncoder(w).Encode(map[string]interface{}{
+					"schemaVersion": 2,
+					"layers": []interface{}{
+						map[string]interface{}{
+							"mediaType": "application/vnd.cncf.helm.chart.content.v1.tar+gzip",
+							"digest":    digest,
+							"size":      16,
+						},
+					},
+				})).To(Succeed())
+			case "/v2/charts/big/blobs/" + digest:
+				_, err := w.Write(layer)
+				Expect(err).NotTo(HaveOccurred())
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		DeferCleanup(registry.Close)
+		_, err := util.RenderTestSource(context.Background(), &integrationv1.ResourceSource{
+			Kind: integrationv1.HelmSourceKind,
+			OCI: &integrationv1.SourceOCI{
+				Ref:      "oci://" + strings.TrimPrefix(registry.URL, "http://") + "/charts/big:1.0.0",
+				Insecure: true,
+			},
+		}, data, "default", nil, kubectlUtil)
+		Expect(err).To(MatchError(ContainSubstring("is larger than 1024 bytes")))
+	})
+
+	It("should reject archives with files outside of the archive", func() {
+		configMap("escape", nil, map[string]interface{}{
+			"app.tgz": base64.StdEncoding.EncodeToString(archive(map[string]string{
+				"../kustomization.yaml": "resources: []\n",
+			})),
+		})
+		_, err := util.RenderTestSource(context.Background(), &integrationv1.ResourceSource{
+			Kind:      integrationv1.KustomizeSourceKind,
+			ConfigMap: &integrationv1.SourceConfigMap{Name: "escape", Key: "app.tgz"},
+		}, data, "default", nil, kubectlUtil)
+		Expect(err).To(MatchError(ContainSubstring("is outside of the archive")))
+	})
+})
@@ -0,0 +1,78 @@
+/**
+ * File: /util/suite_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 11:20:37
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestUtil(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Util Suite")
+}
+
+// kubeconfig lets utils that load the config of the cluster be created, the server is never reached
+const kubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+  - name: test
+    cluster:
+      server: https://127.0.0.1:1
+contexts:
+  - name: test
+    context:
+      cluster: test
+      user: test
+current-context: test
+users:
+  - name: test
+    user:
+      token: test
+`
+
+var _ = BeforeSuite(func() {
+	kubeconfigDir, err := os.MkdirTemp("", "util-test")
+	Expect(err).NotTo(HaveOccurred())
+	kubeconfigPath := filepath.Join(kubeconfigDir, "kubeconfig")
+	Expect(os.WriteFile(kubeconfigPath, []byte(kubeconfig), 0600)).To(Succeed())
+	previousKubeconfig, hasKubeconfig := os.LookupEnv("KUBECONFIG")
+	Expect(os.Setenv("KUBECONFIG", kubeconfigPath)).To(Succeed())
+	DeferCleanup(func() {
+		os.RemoveAll(kubeconfigDir)
+		if hasKubeconfig {
+			os.Setenv("KUBECONFIG", previousKubeconfig)
+		} else {
+			os.Unsetenv("KUBECONFIG")
+		}
+	})
+})
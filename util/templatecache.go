@@ -0,0 +1,92 @@
+/**
+ * File: /util/templatecache.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 11:24:48
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"k8s.io/utils/lru"
+)
+
+var sprigFuncMap template.FuncMap = sprig.TxtFuncMap()
+
+var templateCache = lru.New(config.TemplateCacheSize)
+
+// parseTemplate parses the template once per content, mode and set of function names, since
+// renders only differ in the data and the functions bound before executing
+func parseTemplate(templateValue string, strict bool, funcMap template.FuncMap) (*template.Template, error) {
+	names := make([]string, 0, len(funcMap))
+	for name := range funcMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	key := templateCacheKey(templateValue, strict, names)
+	if t, ok := templateCache.Get(key); ok {
+		return t.(*template.Template), nil
+	}
+	// the cached template outlives the render, so it is parsed with placeholders instead of the
+	// functions of the render, which may hold on to the plug, socket and their clients
+	placeholderFuncMap := make(template.FuncMap, len(names))
+	for _, name := range names {
+		placeholderFuncMap[name] = unboundTemplateFunc
+	}
+	t := template.New("").Funcs(sprigFuncMap).Funcs(placeholderFuncMap).Delims("{%", "%}")
+	if strict {
+		t = t.Option("missingkey=error")
+	}
+	t, err := t.Parse(templateValue)
+	if err != nil {
+		return nil, err
+	}
+	templateCache.Add(key, t)
+	return t, nil
+}
+
+func templateCacheKey(templateValue string, strict bool, names []string) string {
+	hash := sha256.New()
+	hash.Write([]byte(strconv.FormatBool(strict)))
+	for _, name := range names {
+		hash.Write([]byte{0})
+		hash.Write([]byte(name))
+	}
+	hash.Write([]byte{0})
+	hash.Write([]byte(templateValue))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// unboundTemplateFunc stands in for the functions of a render until they are bound to a clone of
+// the cached template
+func unboundTemplateFunc(...interface{}) (interface{}, error) {
+	return nil, errors.New("template function is not bound to the render")
+}
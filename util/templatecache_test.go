@@ -0,0 +1,216 @@
+/**
+ * File: /util/templatecache_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 11:58:13
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"text/template"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Template cache", func() {
+	It("should bind the functions of every render to the cached template", func() {
+		data := map[string]interface{}{}
+		for _, value := range []string{"a", "b"} {
+			value := value
+			result, err := util.Template(&data, `{% current %}`, template.FuncMap{
+				"current": func() string { return value },
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(value))
+		}
+	})
+
+	It("should cache strict and lenient templates separately", func() {
+		data := map[string]interface{}{}
+		result, err := util.Template(&data, `{% .missing %}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("<no value>"))
+		_, err = util.StrictTemplate(&data, `{% .missing %}`)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should share template contexts within a coupling pass", func() {
+		plug, socket := benchmarkPlugAndSocket(1)
+		build := func(ctx context.Context) map[string]interface{} {
+			data, err := util.NewTestTemplateContextUtil(ctx).Build(
+				util.PlugTemplateOwner,
+				util.ConfigTemplateStage,
+				plug,
+				socket,
+				nil,
+				nil,
+				nil,
+				nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			return data
+		}
+		ctx := util.WithTemplatePass(context.Background())
+		build(ctx)
+		build(ctx)
+		Expect(util.TemplatePassContextCount(ctx)).To(Equal(1))
+	})
+
+	It("should not leak changes of a template into the other templates of a pass", func() {
+		plug, socket := benchmarkPlugAndSocket(1)
+		build := func(ctx context.Context, owner util.TemplateOwner) map[string]interface{} {
+			data, err := util.NewTestTemplateContextUtil(ctx).Build(
+				owner,
+				util.ConfigTemplateStage,
+				plug,
+				socket,
+				nil,
+				nil,
+				nil,
+				nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			return data
+		}
+		ctx := util.WithTemplatePass(context.Background())
+		data := build(ctx, util.PlugTemplateOwner)
+		_, err := util.Template(&data, `{% $_ := set . "leaked" true %}{% $_ := unset .plug "spec" %}`+
+			`{% $_ := set .plug.metadata "name" "leaked" %}{% $_ := set .plugData "tier" "leaked" %}`)
+		Expect(err).NotTo(HaveOccurred())
+		for _, owner := range []util.TemplateOwner{util.PlugTemplateOwner, util.SocketTemplateOwner} {
+			data := build(ctx, owner)
+			Expect(data).NotTo(HaveKey("leaked"))
+			Expect(data["plug"]).To(HaveKey("spec"))
+			Expect(data["plug"].(map[string]interface{})["metadata"]).To(HaveKeyWithValue("name", "app"))
+			Expect(data["plugData"]).To(HaveKeyWithValue("tier", "web"))
+		}
+	})
+
+	It("should evict the least recently used templates", func() {
+		util.ResetTemplateCache()
+		data := map[string]interface{}{}
+		_, err := util.Template(&data, `{% "used" %}`)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = util.Template(&data, `{% "unused" %}`)
+		Expect(err).NotTo(HaveOccurred())
+		for i := 0; i < config.TemplateCacheSize-1; i++ {
+			_, err := util.Template(&data, `{% "used" %}{% "`+strconv.Itoa(i)+`" %}`)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = util.Template(&data, `{% "used" %}`)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(util.TemplateCached(`{% "used" %}`)).To(BeTrue())
+		Expect(util.TemplateCached(`{% "unused" %}`)).To(BeFalse())
+	})
+})
+
+// benchmarkPlugAndSocket creates a plug and socket with large resource templates
+func benchmarkPlugAndSocket(resourceCount int) (*integrationv1.Plug, *integrationv1.Socket) {
+	resources := []*integrationv1.Resource{}
+	for i := 0; i < resourceCount; i++ {
+		resources = append(resources, &integrationv1.Resource{
+			ResourceAction: integrationv1.ResourceAction{StringTemplate: benchmarkTemplate},
+		})
+	}
+	plug := &integrationv1.Plug{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "plug", ResourceVersion: "1"},
+		Spec: integrationv1.PlugSpec{
+			Data:      map[string]string{"tier": "web"},
+			Resources: resources,
+		},
+	}
+	socket := &integrationv1.Socket{
+		ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default", UID: "socket", ResourceVersion: "1"},
+		Spec:       integrationv1.SocketSpec{Resources: resources},
+	}
+	return plug, socket
+}
+
+var benchmarkTemplate = func() string {
+	var builder strings.Builder
+	builder.WriteString("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {% .plug.metadata.name %}\ndata:\n")
+	for i := 0; i < 50; i++ {
+		builder.WriteString("  key" + strconv.Itoa(i) + `: {% printf "%s-%s" .plug.metadata.name .plugData.tier | upper | quote %}` + "\n")
+	}
+	return builder.String()
+}()
+
+func BenchmarkTemplate(b *testing.B) {
+	plug, socket := benchmarkPlugAndSocket(1)
+	data, err := util.NewTestTemplateContextUtil(context.Background()).Build(
+		util.PlugTemplateOwner, util.VarTemplateStage, plug, socket, nil, nil, nil, nil,
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			util.ResetTemplateCache()
+			if _, err := util.Template(&data, benchmarkTemplate); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := util.Template(&data, benchmarkTemplate); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkTemplateContext builds the template context for every template of a coupling pass
+// of a plug with 20 resources
+func BenchmarkTemplateContext(b *testing.B) {
+	plug, socket := benchmarkPlugAndSocket(20)
+	build := func(b *testing.B, ctx context.Context) {
+		templateContextUtil := util.NewTestTemplateContextUtil(ctx)
+		for range plug.Spec.Resources {
+			if _, err := templateContextUtil.Build(
+				util.PlugTemplateOwner, util.ResourceTemplateStage, plug, socket, nil, nil, nil, nil,
+			); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("per render", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			build(b, context.Background())
+		}
+	})
+	b.Run("per pass", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			build(b, util.WithTemplatePass(context.Background()))
+		}
+	})
+}
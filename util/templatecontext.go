@@ -0,0 +1,411 @@
+/**
+ * File: /util/templatecontext.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 10:02:11
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/invopop/jsonschema"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TemplateContextVersion is the version of the keys of the template context, bumped when a
+// key is removed or changes meaning
+const TemplateContextVersion = "v1"
+
+type TemplateOwner string
+
+const (
+	PlugTemplateOwner   TemplateOwner = "plug"
+	SocketTemplateOwner TemplateOwner = "socket"
+)
+
+type TemplateStage string
+
+const (
+	VarTemplateStage      TemplateStage = "var"
+	ConfigTemplateStage   TemplateStage = "config"
+	ResultTemplateStage   TemplateStage = "result"
+	ResourceTemplateStage TemplateStage = "resource"
+)
+
+// TemplateContext is the data every template is rendered with. A key is set when the stage of
+// the template has resolved it, so a key holds the same value in every template that can see it.
+type TemplateContext struct {
+	Version      string                  `json:"version" jsonschema:"enum=v1" jsonschema_description:"version of the template context"`
+	Stage        TemplateStage           `json:"stage" jsonschema:"enum=var,enum=config,enum=result,enum=resource" jsonschema_description:"stage of the template"`
+	Plug         *integrationv1.Plug     `json:"plug,omitempty" jsonschema_description:"plug being coupled, not set for the templates of a socket without a plug"`
+	Socket       *integrationv1.Socket   `json:"socket,omitempty" jsonschema_description:"socket being coupled, not set for the templates of a plug without a socket"`
+	PlugData     map[string]string       `json:"plugData" jsonschema_description:"data of the plug"`
+	SocketData   map[string]string       `json:"socketData" jsonschema_description:"data of the socket"`
+	Vars         *map[string]interface{} `json:"vars,omitempty" jsonschema_description:"vars of the plug or socket that owns the template, set from the config stage, also holding the result vars in socket result templates until the next version"`
+	ResultVars   *map[string]interface{} `json:"resultVars,omitempty" jsonschema_description:"result vars of the plug or socket that owns the template, set from the result stage"`
+	PlugConfig   *Config                 `json:"plugConfig,omitempty" jsonschema_description:"config of the plug, set from the result stage once resolved"`
+	SocketConfig *Config                 `json:"socketConfig,omitempty" jsonschema_description:"config of the socket, set from the result stage once resolved"`
+	PlugResult   *Result                 `json:"plugResult,omitempty" jsonschema_description:"result of the plug, set for resources once resolved"`
+	SocketResult *Result                 `json:"socketResult,omitempty" jsonschema_description:"result of the socket, set for resources once resolved"`
+}
+
+// includes reports whether templates of the stage can see the keys resolved at the other stage
+func (s TemplateStage) includes(other TemplateStage) bool {
+	order := map[TemplateStage]int{
+		VarTemplateStage:      0,
+		ConfigTemplateStage:   1,
+		ResultTemplateStage:   2,
+		ResourceTemplateStage: 3,
+	}
+	return order[s] >= order[other]
+}
+
+type TemplateContextUtil struct {
+	ctx         context.Context
+	dataUtil    *DataUtil
+	varUtil     *VarUtil
+	varUtilOnce sync.Once
+}
+
+func NewTemplateContextUtil(ctx context.Context) *TemplateContextUtil {
+	return &TemplateContextUtil{
+		ctx:      ctx,
+		dataUtil: NewDataUtil(ctx),
+	}
+}
+
+// Build builds the template context of a template of the owner at the stage
+func (u *TemplateContextUtil) Build(
+	owner TemplateOwner,
+	stage TemplateStage,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	plugConfig *Config,
+	socketConfig *Config,
+	plugResult *Result,
+	socketResult *Result,
+) (map[string]interface{}, error) {
+	pass := templatePassFromContext(u.ctx)
+	key := ""
+	if pass != nil {
+		var err error
+		key, err = templateContextKey(owner, stage, plug, socket, plugConfig, socketConfig, plugResult, socketResult)
+		if err != nil {
+			return nil, err
+		}
+		if data, ok := pass.getContext(key); ok {
+			return copyTemplateContext(data), nil
+		}
+	}
+	data, err := u.build(owner, stage, plug, socket, plugConfig, socketConfig, plugResult, socketResult, pass)
+	if err != nil {
+		return nil, err
+	}
+	if pass != nil {
+		pass.setContext(key, data)
+		return copyTemplateContext(data), nil
+	}
+	return data, nil
+}
+
+// copyTemplateContext deeply copies the template context shared by the pass, so functions like
+// set and unset of one template do not leak into another
+func copyTemplateContext(data map[string]interface{}) map[string]interface{} {
+	return runtime.DeepCopyJSON(data)
+}
+
+func (u *TemplateContextUtil) build(
+	owner TemplateOwner,
+	stage TemplateStage,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	plugConfig *Config,
+	socketConfig *Config,
+	plugResult *Result,
+	socketResult *Result,
+	pass *templatePass,
+) (map[string]interface{}, error) {
+	templateContext := TemplateContext{
+		Version:    TemplateContextVersion,
+		Stage:      stage,
+		Plug:       plug,
+		Socket:     socket,
+		PlugData:   map[string]string{},
+		SocketData: map[string]string{},
+	}
+	var err error
+	if plug != nil {
+		templateContext.PlugData, err = u.dataUtil.GetPlugData(plug)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if socket != nil {
+		templateContext.SocketData, err = u.dataUtil.GetSocketData(socket)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !stage.includes(ConfigTemplateStage) {
+		return templateContext.toMap(pass)
+	}
+	// vars belong to the plug or socket that owns the template, so they are resolved as soon as
+	// the owner exists even if the other side is not coupled yet
+	var namespace, serviceAccountName string
+	var vars, resultVars []*integrationv1.Var
+	var strict bool
+	if owner == PlugTemplateOwner {
+		if plug == nil {
+			return templateContext.toMap(pass)
+		}
+		strict = PlugStrictTemplates(plug)
+		namespace = plug.Namespace
+		serviceAccountName = plug.Spec.ServiceAccountName
+		vars = plug.Spec.Vars
+		resultVars = plug.Spec.ResultVars
+	} else if owner == SocketTemplateOwner {
+		if socket == nil {
+			return templateContext.toMap(pass)
+		}
+		strict = SocketStrictTemplates(socket)
+		namespace = socket.Namespace
+		serviceAccountName = socket.Spec.ServiceAccountName
+		vars = socket.Spec.Vars
+		resultVars = socket.Spec.ResultVars
+	} else {
+		return nil, errors.New("template owner '" + string(owner) + "' is not available")
+	}
+	var kubectlUtil *KubectlUtil
+	if len(vars) > 0 || len(resultVars) > 0 {
+		kubectlUtil = NewKubectlUtil(u.ctx, namespace, EnsureServiceAccount(serviceAccountName))
+	}
+	varsMap, err := u.getVarUtil().GetVars(namespace, vars, kubectlUtil, plug, socket, strict)
+	if err != nil {
+		return nil, err
+	}
+	templateContext.Vars = &varsMap
+	if stage.includes(ResultTemplateStage) {
+		resultVarsMap, err := u.getVarUtil().GetVars(namespace, resultVars, kubectlUtil, plug, socket, strict)
+		if err != nil {
+			return nil, err
+		}
+		templateContext.ResultVars = &resultVarsMap
+		if owner == SocketTemplateOwner && stage == ResultTemplateStage {
+			templateContext.Vars = socketResultVarsAlias(varsMap, resultVarsMap)
+		}
+		templateContext.PlugConfig = plugConfig
+		templateContext.SocketConfig = socketConfig
+	}
+	if stage.includes(ResourceTemplateStage) {
+		templateContext.PlugResult = plugResult
+		templateContext.SocketResult = socketResult
+	}
+	return templateContext.toMap(pass)
+}
+
+// socketResultVarsAlias keeps the result vars of a socket readable from the vars key of its result
+// templates, where they were read from before version v1 of the template context. The result vars
+// win over the vars of the same name. The alias is removed with the next version of the context.
+func socketResultVarsAlias(vars map[string]interface{}, resultVars map[string]interface{}) *map[string]interface{} {
+	aliasedVars := make(map[string]interface{}, len(vars)+len(resultVars))
+	for name, value := range vars {
+		aliasedVars[name] = value
+	}
+	for name, value := range resultVars {
+		aliasedVars[name] = value
+	}
+	return &aliasedVars
+}
+
+// getVarUtil creates the var util on first use since var templates are rendered with a
+// template context of their own
+func (u *TemplateContextUtil) getVarUtil() *VarUtil {
+	u.varUtilOnce.Do(func() {
+		if u.varUtil == nil {
+			u.varUtil = NewVarUtil(u.ctx)
+		}
+	})
+	return u.varUtil
+}
+
+// toMap converts the template context to the plain data templates are rendered with, reusing
+// the plug and socket converted earlier in the pass
+func (c TemplateContext) toMap(pass *templatePass) (map[string]interface{}, error) {
+	plug := c.Plug
+	socket := c.Socket
+	c.Plug = nil
+	c.Socket = nil
+	data, err := toPlainMap(c)
+	if err != nil {
+		return nil, err
+	}
+	if plug != nil {
+		data["plug"], err = pass.object(string(plug.UID)+"/"+plug.ResourceVersion, plug)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if socket != nil {
+		data["socket"], err = pass.object(string(socket.UID)+"/"+socket.ResourceVersion, socket)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func toPlainMap(value interface{}) (map[string]interface{}, error) {
+	bData, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(bData, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+type templatePassKey struct{}
+
+// templatePass holds the template contexts built while coupling so every template of the
+// pass shares them instead of resolving data and vars and converting the plug and socket again
+type templatePass struct {
+	contexts map[string]map[string]interface{}
+	mutex    sync.Mutex
+	objects  map[string]map[string]interface{}
+}
+
+// WithTemplatePass starts a coupling pass that shares template contexts between the utils
+// created with the returned context
+func WithTemplatePass(ctx context.Context) context.Context {
+	if templatePassFromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, templatePassKey{}, &templatePass{
+		contexts: map[string]map[string]interface{}{},
+		objects:  map[string]map[string]interface{}{},
+	})
+}
+
+func templatePassFromContext(ctx context.Context) *templatePass {
+	if ctx == nil {
+		return nil
+	}
+	pass, _ := ctx.Value(templatePassKey{}).(*templatePass)
+	return pass
+}
+
+func (p *templatePass) getContext(key string) (map[string]interface{}, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	data, ok := p.contexts[key]
+	return data, ok
+}
+
+func (p *templatePass) setContext(key string, data map[string]interface{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.contexts[key] = data
+}
+
+// object converts the object to plain data once per pass and version of the object
+func (p *templatePass) object(key string, obj interface{}) (map[string]interface{}, error) {
+	if p == nil || key == "/" {
+		return toPlainMap(obj)
+	}
+	p.mutex.Lock()
+	data, ok := p.objects[key]
+	p.mutex.Unlock()
+	if ok {
+		return data, nil
+	}
+	data, err := toPlainMap(obj)
+	if err != nil {
+		return nil, err
+	}
+	p.mutex.Lock()
+	p.objects[key] = data
+	p.mutex.Unlock()
+	return data, nil
+}
+
+// templateContextKey identifies the template context by its owner, stage and inputs
+func templateContextKey(
+	owner TemplateOwner,
+	stage TemplateStage,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	plugConfig *Config,
+	socketConfig *Config,
+	plugResult *Result,
+	socketResult *Result,
+) (string, error) {
+	key := string(owner) + "/" + string(stage)
+	if plug != nil {
+		key += "/plug:" + plug.Namespace + "/" + plug.Name + ":" + string(plug.UID) + ":" + plug.ResourceVersion
+	}
+	if socket != nil {
+		key += "/socket:" + socket.Namespace + "/" + socket.Name + ":" + string(socket.UID) + ":" + socket.ResourceVersion
+	}
+	values, err := json.Marshal([]interface{}{plugConfig, socketConfig, plugResult, socketResult})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(values)
+	return key + "/" + hex.EncodeToString(sum[:]), nil
+}
+
+// TemplateContextSchema generates the json schema of the template context
+func TemplateContextSchema() ([]byte, error) {
+	reflector := jsonschema.Reflector{
+		DoNotReference: true,
+		Mapper: func(t reflect.Type) *jsonschema.Schema {
+			switch t {
+			case reflect.TypeOf(integrationv1.Plug{}):
+				return &jsonschema.Schema{
+					Type:        "object",
+					Description: "plug resource as stored in the cluster",
+				}
+			case reflect.TypeOf(integrationv1.Socket{}):
+				return &jsonschema.Schema{
+					Type:        "object",
+					Description: "socket resource as stored in the cluster",
+				}
+			}
+			return nil
+		},
+	}
+	schema := reflector.Reflect(&TemplateContext{})
+	schema.ID = "https://rock8s.com/schemas/integration-operator/template-context/" + TemplateContextVersion
+	schema.Title = "Template context " + TemplateContextVersion
+	return json.MarshalIndent(schema, "", "  ")
+}
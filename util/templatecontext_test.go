@@ -0,0 +1,131 @@
+/**
+ * File: /util/templatecontext_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 10:52:04
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Template context", func() {
+	plug := &integrationv1.Plug{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec:       integrationv1.PlugSpec{Data: map[string]string{"tier": "web"}},
+	}
+	socket := &integrationv1.Socket{
+		ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default"},
+	}
+
+	keys := func(data map[string]interface{}) []string {
+		result := []string{}
+		for key := range data {
+			result = append(result, key)
+		}
+		return result
+	}
+
+	It("should expose the keys the stage has resolved", func() {
+		templateContextUtil := util.NewTemplateContextUtil(context.Background())
+		data, err := templateContextUtil.Build("", util.VarTemplateStage, plug, socket, nil, nil, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys(data)).To(ConsistOf("version", "stage", "plug", "socket", "plugData", "socketData"))
+		Expect(data["version"]).To(Equal(util.TemplateContextVersion))
+		Expect(data["plugData"]).To(Equal(map[string]interface{}{"tier": "web"}))
+		data, err = templateContextUtil.Build(util.PlugTemplateOwner, util.ConfigTemplateStage, plug, socket, nil, nil, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys(data)).To(ConsistOf("version", "stage", "plug", "socket", "plugData", "socketData", "vars"))
+		plugConfig := util.Config{"database": "app"}
+		socketResult := util.Result{"host": "postgres"}
+		data, err = templateContextUtil.Build(
+			util.SocketTemplateOwner,
+			util.ResourceTemplateStage,
+			plug,
+			socket,
+			&plugConfig,
+			nil,
+			nil,
+			&socketResult,
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys(data)).To(ConsistOf(
+			"version", "stage", "plug", "socket", "plugData", "socketData",
+			"vars", "resultVars", "plugConfig", "socketResult",
+		))
+		Expect(data["stage"]).To(Equal("resource"))
+		Expect(data["socketResult"]).To(Equal(map[string]interface{}{"host": "postgres"}))
+	})
+
+	It("should not resolve vars before the config stage", func() {
+		data, err := util.NewTemplateContextUtil(context.Background()).Build(
+			"",
+			util.VarTemplateStage,
+			plug,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys(data)).To(ConsistOf("version", "stage", "plug", "plugData", "socketData"))
+	})
+
+	It("should resolve the vars of the owner before the other side is coupled", func() {
+		templateContextUtil := util.NewTemplateContextUtil(context.Background())
+		data, err := templateContextUtil.Build(util.SocketTemplateOwner, util.ConfigTemplateStage, nil, socket, nil, nil, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys(data)).To(ConsistOf("version", "stage", "socket", "plugData", "socketData", "vars"))
+		data, err = templateContextUtil.Build(util.PlugTemplateOwner, util.ResourceTemplateStage, plug, nil, nil, nil, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys(data)).To(ConsistOf("version", "stage", "plug", "plugData", "socketData", "vars", "resultVars"))
+		data, err = templateContextUtil.Build(util.SocketTemplateOwner, util.ConfigTemplateStage, plug, nil, nil, nil, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys(data)).To(ConsistOf("version", "stage", "plug", "plugData", "socketData"))
+	})
+
+	It("should keep the result vars of socket result templates under vars", func() {
+		Expect(util.SocketResultVarsAlias(
+			map[string]interface{}{"host": "config", "port": "5432"},
+			map[string]interface{}{"host": "result"},
+		)).To(Equal(map[string]interface{}{"host": "result", "port": "5432"}))
+	})
+
+	It("should match the generated schema", func() {
+		schema, err := util.TemplateContextSchema()
+		Expect(err).NotTo(HaveOccurred())
+		generated, err := os.ReadFile("../config/schema/template-context.schema.json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.TrimSpace(string(generated))).To(Equal(string(schema)), "run make template-schema")
+	})
+})
@@ -0,0 +1,307 @@
+/**
+ * File: /util/validation.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 09:12:41
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/lru"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var validationPrograms = lru.New(config.CELProgramCacheSize)
+
+type ValidationUtil struct {
+	client    *client.Client
+	ctx       context.Context
+	namespace *v1.Namespace
+}
+
+func NewValidationUtil(
+	client *client.Client,
+	ctx context.Context,
+) *ValidationUtil {
+	return &ValidationUtil{
+		client: client,
+		ctx:    ctx,
+	}
+}
+
+// Validate checks the plug against the namespaces, selectors and service accounts the socket
+// permits, which does not require the config of the plug
+func (u *ValidationUtil) Validate(
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+) error {
+	validation := socket.Spec.Validation
+	if validation == nil {
+		return nil
+	}
+	if err := u.validateNamespaceName(plug, validation); err != nil {
+		return err
+	}
+	if err := u.validateNamespaceSelector(plug, validation); err != nil {
+		return err
+	}
+	if err := u.validatePlugSelector(plug, validation); err != nil {
+		return err
+	}
+	if err := u.validateServiceAccount(plug, validation); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RulesRequired reports whether the socket has validation rules, which are evaluated against
+// the config of the plug
+func (u *ValidationUtil) RulesRequired(socket *integrationv1.Socket) bool {
+	return socket.Spec.Validation != nil && len(socket.Spec.Validation.Rules) > 0
+}
+
+// ValidateRules evaluates the validation rules of the socket against the plug, the socket,
+// the config of the plug and the metadata of the plug namespace
+func (u *ValidationUtil) ValidateRules(
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	plugConfig map[string]string,
+) error {
+	if !u.RulesRequired(socket) {
+		return nil
+	}
+	validation := socket.Spec.Validation
+	plugMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(plug)
+	if err != nil {
+		return err
+	}
+	socketMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(socket)
+	if err != nil {
+		return err
+	}
+	namespace, err := u.getNamespace(plug)
+	if err != nil {
+		return err
+	}
+	namespaceMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(namespace)
+	if err != nil {
+		return err
+	}
+	if plugConfig == nil {
+		plugConfig = map[string]string{}
+	}
+	vars := map[string]interface{}{
+		"plug":       plugMap,
+		"socket":     socketMap,
+		"plugConfig": plugConfig,
+		"namespaceObject": map[string]interface{}{
+			"metadata": namespaceMap["metadata"],
+		},
+	}
+	for _, rule := range validation.Rules {
+		program, err := validationProgram(rule.Rule)
+		if err != nil {
+			return fmt.Errorf("invalid validation rule %q: %s", rule.Rule, err.Error())
+		}
+		result, _, err := program.ContextEval(u.ctx, vars)
+		if err != nil {
+			return NewValidationError(fmt.Errorf("validation rule %q failed: %s", rule.Rule, err.Error()))
+		}
+		passed, ok := result.Value().(bool)
+		if !ok {
+			return fmt.Errorf("validation rule %q must evaluate to a bool", rule.Rule)
+		}
+		if !passed {
+			if rule.Message != "" {
+				return NewValidationError(errors.New(rule.Message))
+			}
+			return NewValidationError(fmt.Errorf("validation rule %q failed", rule.Rule))
+		}
+	}
+	return nil
+}
+
+func (u *ValidationUtil) validateNamespaceName(
+	plug *integrationv1.Plug,
+	validation *integrationv1.SocketSpecValidation,
+) error {
+	if validation.NamespaceBlacklist != nil {
+		for _, namespace := range validation.NamespaceBlacklist {
+			match, _ := regexp.MatchString(namespace, plug.Namespace)
+			if match {
+				return NewValidationError(fmt.Errorf("namespace %s is blacklisted", plug.Namespace))
+			}
+		}
+	}
+	if validation.NamespaceWhitelist != nil {
+		for _, namespace := range validation.NamespaceWhitelist {
+			match, _ := regexp.MatchString(namespace, plug.Namespace)
+			if match {
+				return nil
+			}
+		}
+		return NewValidationError(fmt.Errorf("namespace %s is not whitelisted", plug.Namespace))
+	}
+	return nil
+}
+
+func (u *ValidationUtil) validateNamespaceSelector(
+	plug *integrationv1.Plug,
+	validation *integrationv1.SocketSpecValidation,
+) error {
+	if validation.NamespaceSelector == nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(validation.NamespaceSelector)
+	if err != nil {
+		return err
+	}
+	namespace, err := u.getNamespace(plug)
+	if err != nil {
+		return err
+	}
+	if !selector.Matches(labels.Set(namespace.Labels)) {
+		return NewValidationError(fmt.Errorf(
+			"namespace %s does not match namespace selector %s",
+			plug.Namespace,
+			selector.String(),
+		))
+	}
+	return nil
+}
+
+func (u *ValidationUtil) validatePlugSelector(
+	plug *integrationv1.Plug,
+	validation *integrationv1.SocketSpecValidation,
+) error {
+	if validation.PlugSelector == nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(validation.PlugSelector)
+	if err != nil {
+		return err
+	}
+	if !selector.Matches(labels.Set(plug.Labels)) {
+		return NewValidationError(fmt.Errorf(
+			"plug %s/%s does not match plug selector %s",
+			plug.Namespace,
+			plug.Name,
+			selector.String(),
+		))
+	}
+	return nil
+}
+
+func (u *ValidationUtil) validateServiceAccount(
+	plug *integrationv1.Plug,
+	validation *integrationv1.SocketSpecValidation,
+) error {
+	if validation.AllowedServiceAccounts == nil {
+		return nil
+	}
+	serviceAccountName := EnsureServiceAccount(plug.Spec.ServiceAccountName)
+	for _, allowedServiceAccount := range validation.AllowedServiceAccounts {
+		if allowedServiceAccount == serviceAccountName ||
+			allowedServiceAccount == plug.Namespace+"/"+serviceAccountName {
+			return nil
+		}
+	}
+	return NewValidationError(fmt.Errorf(
+		"service account %s/%s is not allowed",
+		plug.Namespace,
+		serviceAccountName,
+	))
+}
+
+// validationProgram compiles the validation rule once per rule, since the rules of a socket
+// are evaluated on every reconcile of its plugs, limiting the cost of its evaluation like the
+// cel expressions of vars
+func validationProgram(rule string) (cel.Program, error) {
+	if program, ok := validationPrograms.Get(rule); ok {
+		return program.(cel.Program), nil
+	}
+	env, err := cel.NewEnv(
+		ext.Strings(),
+		cel.Variable("plug", cel.DynType),
+		cel.Variable("socket", cel.DynType),
+		cel.Variable("plugConfig", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("namespaceObject", cel.DynType),
+	)
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(rule)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	program, err := env.Program(
+		ast,
+		cel.CostLimit(config.CELCostLimit),
+		cel.InterruptCheckFrequency(config.CELInterruptCheckFrequency),
+	)
+	if err != nil {
+		return nil, err
+	}
+	validationPrograms.Add(rule, program)
+	return program, nil
+}
+
+func (u *ValidationUtil) getNamespace(plug *integrationv1.Plug) (*v1.Namespace, error) {
+	if u.namespace != nil {
+		return u.namespace, nil
+	}
+	namespace := &v1.Namespace{}
+	if err := (*u.client).Get(u.ctx, types.NamespacedName{Name: plug.Namespace}, namespace); err != nil {
+		return nil, err
+	}
+	u.namespace = namespace
+	return namespace, nil
+}
+
+type ValidationError struct {
+	err error
+}
+
+func NewValidationError(err error) ValidationError {
+	return ValidationError{
+		err: err,
+	}
+}
+
+func (e ValidationError) Error() string {
+	return e.err.Error()
+}
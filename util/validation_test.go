@@ -0,0 +1,204 @@
+/**
+ * File: /util/validation_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 17:42:08
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Validation", func() {
+	var plug *integrationv1.Plug
+	var socket *integrationv1.Socket
+	var plugConfig map[string]string
+
+	BeforeEach(func() {
+		plug = &integrationv1.Plug{
+			ObjectMeta: metav1.ObjectMeta{Name: "plug", Namespace: "tenant"},
+		}
+		socket = &integrationv1.Socket{
+			ObjectMeta: metav1.ObjectMeta{Name: "socket", Namespace: "default"},
+			Spec: integrationv1.SocketSpec{
+				Validation: &integrationv1.SocketSpecValidation{},
+			},
+		}
+		plugConfig = nil
+	})
+
+	validate := func(objects ...client.Object) error {
+		var fakeClient client.Client = fake.NewClientBuilder().WithObjects(objects...).Build()
+		validationUtil := util.NewValidationUtil(&fakeClient, context.Background())
+		if err := validationUtil.Validate(plug, socket); err != nil {
+			return err
+		}
+		return validationUtil.ValidateRules(plug, socket, plugConfig)
+	}
+
+	namespace := func(labels map[string]string) *v1.Namespace {
+		return &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant", Labels: labels},
+			Spec:       v1.NamespaceSpec{Finalizers: []v1.FinalizerName{"kubernetes"}},
+		}
+	}
+
+	Context("namespace selector", func() {
+		BeforeEach(func() {
+			socket.Spec.Validation.NamespaceSelector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{"tier": "gold"},
+			}
+		})
+
+		It("should permit plugs in matching namespaces", func() {
+			Expect(validate(namespace(map[string]string{"tier": "gold"}))).To(Succeed())
+		})
+
+		It("should reject plugs in namespaces that do not match", func() {
+			err := validate(namespace(map[string]string{"tier": "bronze"}))
+			Expect(err).To(BeAssignableToTypeOf(util.ValidationError{}))
+			Expect(err).To(MatchError(ContainSubstring("namespace tenant does not match namespace selector tier=gold")))
+		})
+
+		It("should fail when the namespace cannot be read", func() {
+			err := validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err).NotTo(BeAssignableToTypeOf(util.ValidationError{}))
+		})
+	})
+
+	Context("namespace object rules", func() {
+		It("should evaluate rules against the labels of the plug namespace", func() {
+			socket.Spec.Validation.Rules = []integrationv1.ValidationRule{{
+				Rule:    `namespaceObject.metadata.labels["tier"] == "gold"`,
+				Message: "namespace must be gold",
+			}}
+			Expect(validate(namespace(map[string]string{"tier": "gold"}))).To(Succeed())
+			Expect(validate(namespace(map[string]string{"tier": "bronze"}))).To(
+				MatchError("namespace must be gold"),
+			)
+		})
+
+		It("should only expose the metadata of the plug namespace", func() {
+			socket.Spec.Validation.Rules = []integrationv1.ValidationRule{{
+				Rule: `!has(namespaceObject.spec)`,
+			}}
+			Expect(validate(namespace(nil))).To(Succeed())
+		})
+	})
+
+	Context("rules", func() {
+		rules := func(rules ...integrationv1.ValidationRule) {
+			socket.Spec.Validation.Rules = rules
+		}
+
+		It("should not be required without rules", func() {
+			var fakeClient client.Client = fake.NewClientBuilder().Build()
+			validationUtil := util.NewValidationUtil(&fakeClient, context.Background())
+			Expect(validationUtil.RulesRequired(socket)).To(BeFalse())
+			rules(integrationv1.ValidationRule{Rule: "true"})
+			Expect(validationUtil.RulesRequired(socket)).To(BeTrue())
+		})
+
+		It("should evaluate rules against the plug, socket and plug config", func() {
+			plug.Labels = map[string]string{"team": "payments"}
+			plugConfig = map[string]string{"size": "10"}
+			rules(
+				integrationv1.ValidationRule{Rule: `plug.metadata.labels.team == "payments"`},
+				integrationv1.ValidationRule{Rule: `socket.metadata.name == "socket"`},
+				integrationv1.ValidationRule{Rule: `int(plugConfig.size) <= 100`},
+			)
+			Expect(validate(namespace(nil))).To(Succeed())
+			plugConfig["size"] = "1000"
+			Expect(validate(namespace(nil))).To(MatchError(`validation rule "int(plugConfig.size) <= 100" failed`))
+		})
+
+		It("should report the message of a violated rule", func() {
+			rules(integrationv1.ValidationRule{Rule: `plug.metadata.name.startsWith("app-")`, Message: "plugs must be apps"})
+			err := validate(namespace(nil))
+			Expect(err).To(BeAssignableToTypeOf(util.ValidationError{}))
+			Expect(err).To(MatchError("plugs must be apps"))
+		})
+
+		It("should stop at the first violated rule", func() {
+			rules(
+				integrationv1.ValidationRule{Rule: "false", Message: "first"},
+				integrationv1.ValidationRule{Rule: "false", Message: "second"},
+			)
+			Expect(validate(namespace(nil))).To(MatchError("first"))
+		})
+
+		It("should treat rules that fail to evaluate as violated", func() {
+			rules(integrationv1.ValidationRule{Rule: `plugConfig.missing == "value"`})
+			err := validate(namespace(nil))
+			Expect(err).To(BeAssignableToTypeOf(util.ValidationError{}))
+			Expect(err).To(MatchError(ContainSubstring("no such key: missing")))
+		})
+
+		It("should reject invalid rules", func() {
+			rules(integrationv1.ValidationRule{Rule: `plug.metadata.name ==`})
+			err := validate(namespace(nil))
+			Expect(err).To(MatchError(ContainSubstring(`invalid validation rule "plug.metadata.name =="`)))
+			Expect(err).NotTo(BeAssignableToTypeOf(util.ValidationError{}))
+		})
+
+		It("should reject rules that do not evaluate to a bool", func() {
+			rules(integrationv1.ValidationRule{Rule: `plug.metadata.name`})
+			Expect(validate(namespace(nil))).To(MatchError(`validation rule "plug.metadata.name" must evaluate to a bool`))
+		})
+
+		It("should treat rules that exceed the cost limit as violated", func() {
+			items := make([]string, 50)
+			for i := range items {
+				items[i] = strconv.Itoa(i)
+			}
+			list := "[" + strings.Join(items, ", ") + "]"
+			rules(integrationv1.ValidationRule{
+				Rule: "size(" + list + ".map(a, " + list + ".map(b, " + list + ".map(c, " + list + ".map(d, d))))) > 0",
+			})
+			err := validate(namespace(nil))
+			Expect(err).To(BeAssignableToTypeOf(util.ValidationError{}))
+			Expect(err).To(MatchError(ContainSubstring("actual cost limit exceeded")))
+		})
+
+		It("should compile each rule once", func() {
+			rule := `plug.metadata.name != "compiled-once"`
+			rules(integrationv1.ValidationRule{Rule: rule})
+			Expect(validate(namespace(nil))).To(Succeed())
+			Expect(util.ValidationProgramCached(rule)).To(BeTrue())
+			Expect(validate(namespace(nil))).To(Succeed())
+		})
+	})
+})
@@ -29,36 +29,53 @@ package util
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/ext"
 	"github.com/tidwall/gjson"
-	integrationv1beta1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1beta1"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/config"
 	"k8s.io/client-go/kubernetes"
-	ctrl "sigs.k8s.io/controller-runtime"
+	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/utils/lru"
 	kustomizeTypes "sigs.k8s.io/kustomize/api/types"
 )
 
+var varPrograms = lru.New(config.CELProgramCacheSize)
+
 type VarUtil struct {
-	client       *kubernetes.Clientset
-	resourceUtil *ResourceUtil
+	client              *kubernetes.Clientset
+	ctx                 context.Context
+	resourceUtil        *ResourceUtil
+	templateContextUtil *TemplateContextUtil
 }
 
 func NewVarUtil(ctx context.Context) *VarUtil {
 	return &VarUtil{
-		client:       kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie()),
-		resourceUtil: NewResourceUtil(ctx),
+		client:              kubernetes.NewForConfigOrDie(GetRestConfig(ctx)),
+		ctx:                 ctx,
+		resourceUtil:        NewResourceUtil(ctx),
+		templateContextUtil: NewTemplateContextUtil(ctx),
 	}
 }
 
 func (u *VarUtil) GetVars(
 	namespace string,
-	vars []*integrationv1beta1.Var,
+	vars []*integrationv1.Var,
 	kubectlUtil *KubectlUtil,
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
-) (map[string]string, error) {
-	resultMap := make(map[string]string)
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	strict bool,
+) (map[string]interface{}, error) {
+	resultMap := make(map[string]interface{})
 	for _, v := range vars {
-		varResult, err := u.GetVar(namespace, v, kubectlUtil, plug, socket)
+		varResult, err := u.GetVar(namespace, v, kubectlUtil, plug, socket, strict)
 		if err != nil {
 			return nil, err
 		}
@@ -67,13 +84,18 @@ func (u *VarUtil) GetVars(
 	return resultMap, nil
 }
 
+// GetVar gets the value of the var from the object it refers to, or from a list of the objects
+// its selector selects. Field paths get a string while jsonpath and cel expressions get the json
+// value of the result, such as a list or a map. The name and namespace templates of the var fail
+// on missing keys when strict is set, like the other templates of the plug or socket that owns it.
 func (u *VarUtil) GetVar(
 	namespace string,
-	v *integrationv1beta1.Var,
+	v *integrationv1.Var,
 	kubectlUtil *KubectlUtil,
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
-) (string, error) {
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	strict bool,
+) (interface{}, error) {
 	objRef := kustomizeTypes.Target{
 		APIVersion: v.ObjRef.APIVersion,
 		Name:       v.ObjRef.Name,
@@ -82,58 +104,243 @@ func (u *VarUtil) GetVar(
 	objRef.Kind = v.ObjRef.Kind
 	var err error
 	if v.ObjRef.TemplateNamespace != "" {
-		objRef.Namespace, err = u.varTemplateLookup(v.ObjRef.TemplateNamespace, plug, socket)
+		objRef.Namespace, err = u.varTemplateLookup(v.ObjRef.TemplateNamespace, plug, socket, strict)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	}
 	if v.ObjRef.TemplateName != "" {
-		objRef.Name, err = u.varTemplateLookup(v.ObjRef.TemplateName, plug, socket)
+		objRef.Name, err = u.varTemplateLookup(v.ObjRef.TemplateName, plug, socket, strict)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var document map[string]interface{}
+	if v.ObjRef.Selector != nil {
+		if objRef.Name != "" {
+			return nil, errors.New("var " + v.Name + " objref must set either a name or a selector")
+		}
+		list, err := u.resourceUtil.ListResources(namespace, objRef, v.ObjRef.Selector, kubectlUtil)
+		if err != nil {
+			return nil, err
+		}
+		items := []interface{}{}
+		for _, item := range list.Items {
+			items = append(items, item.Object)
+		}
+		document = map[string]interface{}{"items": items}
+	} else {
+		resource, err := u.resourceUtil.GetResource(namespace, objRef, kubectlUtil)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
+		document = resource.Object
 	}
-	resource, err := u.resourceUtil.GetResource(namespace, objRef, kubectlUtil)
+	value, err := u.selectField(v.FieldRef, document)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("var %s: %w", v.Name, err)
 	}
-	bResource, err := json.Marshal(resource)
+	value, err = u.applyFieldOptions(value, v.FieldRef.Options)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("var %s: %w", v.Name, err)
+	}
+	return value, nil
+}
+
+func (u *VarUtil) selectField(
+	fieldRef integrationv1.FieldSelector,
+	document map[string]interface{},
+) (interface{}, error) {
+	selectors := 0
+	for _, selector := range []string{fieldRef.FieldPath, fieldRef.JSONPath, fieldRef.CEL} {
+		if selector != "" {
+			selectors++
+		}
+	}
+	if selectors > 1 {
+		return nil, errors.New("fieldref must set only one of fieldPath, jsonPath or cel")
+	}
+	if fieldRef.JSONPath != "" {
+		return u.selectJSONPath(fieldRef.JSONPath, document)
+	}
+	if fieldRef.CEL != "" {
+		return u.selectCEL(fieldRef.CEL, document)
+	}
+	body, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	return gjson.ParseBytes(body).Get(fieldRef.FieldPath).String(), nil
+}
+
+// selectJSONPath gets the match of the expression, or a list of the matches when the expression
+// has wildcards, filters, slices, unions or recursive descents
+func (u *VarUtil) selectJSONPath(expression string, document map[string]interface{}) (interface{}, error) {
+	if !strings.Contains(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+	parser, err := jsonpath.Parse("var", expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jsonpath expression %q: %w", expression, err)
+	}
+	j := jsonpath.New("var").AllowMissingKeys(true)
+	if err := j.Parse(expression); err != nil {
+		return nil, fmt.Errorf("invalid jsonpath expression %q: %w", expression, err)
+	}
+	results, err := j.FindResults(document)
+	if err != nil {
+		return nil, err
+	}
+	values := []interface{}{}
+	for _, result := range results {
+		for _, value := range result {
+			values = append(values, value.Interface())
+		}
+	}
+	if len(parser.Root.Nodes) != 1 || !definiteJSONPath(parser.Root.Nodes[0]) {
+		return values, nil
+	}
+	if len(values) == 0 {
+		return nil, nil
 	}
-	return gjson.Parse(string(bResource)).Get(v.FieldRef.FieldPath).String(), nil
+	return values[0], nil
+}
+
+func (u *VarUtil) selectCEL(expression string, document map[string]interface{}) (interface{}, error) {
+	program, err := varProgram(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cel expression %q: %s", expression, err.Error())
+	}
+	ctx := u.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	result, _, err := program.ContextEval(ctx, map[string]interface{}{"object": document})
+	if err != nil {
+		return nil, fmt.Errorf("cel expression %q failed: %s", expression, err.Error())
+	}
+	return celValue(result), nil
+}
+
+// varProgram compiles the cel expression of a var once per expression, limiting the cost of its
+// evaluation since the expression runs over every object the selector of the var lists
+func varProgram(expression string) (cel.Program, error) {
+	if program, ok := varPrograms.Get(expression); ok {
+		return program.(cel.Program), nil
+	}
+	env, err := cel.NewEnv(
+		ext.Strings(),
+		cel.Variable("object", cel.DynType),
+	)
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	program, err := env.Program(
+		ast,
+		cel.CostLimit(config.CELCostLimit),
+		cel.InterruptCheckFrequency(config.CELInterruptCheckFrequency),
+	)
+	if err != nil {
+		return nil, err
+	}
+	varPrograms.Add(expression, program)
+	return program, nil
+}
+
+// applyFieldOptions picks the part of the value at the index of the options after splitting
+// it with their delimiter, the way kustomize replacements do
+func (u *VarUtil) applyFieldOptions(value interface{}, options *integrationv1.FieldOptions) (interface{}, error) {
+	if options == nil || options.Delimiter == "" {
+		return value, nil
+	}
+	stringValue, ok := value.(string)
+	if !ok {
+		return nil, errors.New("fieldref options require a string value")
+	}
+	parts := strings.Split(stringValue, options.Delimiter)
+	if options.Index < 0 || options.Index >= len(parts) {
+		return nil, fmt.Errorf("fieldref options index %d is out of bounds for value %s", options.Index, stringValue)
+	}
+	return parts[options.Index], nil
 }
 
 func (u *VarUtil) varTemplateLookup(
 	varTemplate string,
-	plug *integrationv1beta1.Plug,
-	socket *integrationv1beta1.Socket,
+	plug *integrationv1.Plug,
+	socket *integrationv1.Socket,
+	strict bool,
 ) (string, error) {
-	data, err := u.buildVarTemplateData(socket, plug)
+	data, err := u.templateContextUtil.Build("", VarTemplateStage, plug, socket, nil, nil, nil, nil)
 	if err != nil {
 		return "", err
 	}
+	if strict {
+		return StrictTemplate(&data, varTemplate)
+	}
 	return Template(&data, varTemplate)
 }
 
-func (u *VarUtil) buildVarTemplateData(
-	socket *integrationv1beta1.Socket,
-	plug *integrationv1beta1.Plug,
-) (map[string]interface{}, error) {
-	dataMap := map[string]interface{}{}
-	if socket != nil {
-		dataMap["socket"] = socket
+// definiteJSONPath reports whether the jsonpath node matches at most one value
+func definiteJSONPath(node jsonpath.Node) bool {
+	switch n := node.(type) {
+	case *jsonpath.ListNode:
+		for _, child := range n.Nodes {
+			if !definiteJSONPath(child) {
+				return false
+			}
+		}
+		return true
+	case *jsonpath.FieldNode:
+		return true
+	case *jsonpath.ArrayNode:
+		return n.Params[1].Derived
 	}
-	if plug != nil {
-		dataMap["plug"] = plug
+	return false
+}
+
+// celValue converts the result of a cel expression to its json value
+func celValue(value ref.Val) interface{} {
+	switch v := value.(type) {
+	case traits.Lister:
+		list := []interface{}{}
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			list = append(list, celValue(it.Next()))
+		}
+		return list
+	case traits.Mapper:
+		m := map[string]interface{}{}
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			key := it.Next()
+			m[fmt.Sprint(key.Value())] = celValue(v.Get(key))
+		}
+		return m
 	}
-	bData, err := json.Marshal(dataMap)
-	if err != nil {
-		return nil, err
+	if value.Type() == types.NullType {
+		return nil
 	}
-	var data map[string]interface{}
-	if err := json.Unmarshal(bData, &data); err != nil {
-		return nil, err
+	return value.Value()
+}
+
+// varStrings gets the vars sent to an apparatus, encoding the values that are not strings as json
+func varStrings(vars map[string]interface{}) (map[string]string, error) {
+	if vars == nil {
+		return nil, nil
+	}
+	result := make(map[string]string, len(vars))
+	for key, value := range vars {
+		if stringValue, ok := value.(string); ok {
+			result[key] = stringValue
+			continue
+		}
+		body, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = string(body)
 	}
-	return data, nil
+	return result, nil
 }
@@ -0,0 +1,237 @@
+/**
+ * File: /util/var_test.go
+ * Project: integration-operator
+ * File Created: 19-10-2026 16:05:14
+ * Author: Clay Risser
+ * -----
+ * BitSpur (c) Copyright 2021 - 2026
+ *
+ * Licensed under the GNU Affero General Public License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.gnu.org/licenses/agpl-3.0.en.html
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * You can be released from the requirements of the license by purchasing
+ * a commercial license. Buying such a license is mandatory as soon as you
+ * develop commercial activities involving this software without disclosing
+ * the source code of your own applications.
+ */
+
+package util_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	integrationv1 "gitlab.com/bitspur/rock8s/integration-operator/api/v1"
+	"gitlab.com/bitspur/rock8s/integration-operator/util"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Vars", func() {
+	var kubectlUtil *util.KubectlUtil
+	var varUtil *util.VarUtil
+
+	services := []map[string]interface{}{
+		{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata": map[string]interface{}{
+				"name":      "postgres",
+				"namespace": "default",
+				"labels":    map[string]interface{}{"app": "postgres"},
+				"annotations": map[string]interface{}{
+					"url": "postgres.default.svc:5432",
+				},
+			},
+			"spec": map[string]interface{}{
+				"ports": []interface{}{
+					map[string]interface{}{"name": "postgres", "port": 5432},
+					map[string]interface{}{"name": "metrics", "port": 9187},
+				},
+			},
+		},
+		{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata": map[string]interface{}{
+				"name":      "postgres-replica",
+				"namespace": "default",
+				"labels":    map[string]interface{}{"app": "postgres"},
+			},
+		},
+		{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata": map[string]interface{}{
+				"name":      "redis",
+				"namespace": "default",
+				"labels":    map[string]interface{}{"app": "redis"},
+			},
+		},
+	}
+
+	BeforeEach(func() {
+		server := newFakeAPIServer(
+			metav1.APIResource{Name: "services", Kind: "Service", Namespaced: true, Verbs: []string{"get", "list"}},
+		)
+		for _, service := range services {
+			server.add("services", service)
+		}
+		kubectlUtil = util.NewTestKubectlUtil(context.Background(), server.config())
+		varUtil = util.NewVarUtil(context.Background())
+	})
+
+	getVar := func(v *integrationv1.Var) (interface{}, error) {
+		return varUtil.GetVar("default", v, kubectlUtil, nil, nil, false)
+	}
+
+	postgres := integrationv1.Target{APIVersion: "v1", Gvk: integrationv1.Gvk{Kind: "Service"}, Name: "postgres"}
+
+	selected := integrationv1.Target{
+		APIVersion: "v1",
+		Gvk:        integrationv1.Gvk{Kind: "Service"},
+		Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "postgres"}},
+	}
+
+	It("should get the string of the field path split by the options", func() {
+		value, err := getVar(&integrationv1.Var{
+			Name:     "port",
+			ObjRef:   postgres,
+			FieldRef: integrationv1.FieldSelector{FieldPath: "spec.ports.0.port"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal("5432"))
+		value, err = getVar(&integrationv1.Var{
+			Name:   "host",
+			ObjRef: postgres,
+			FieldRef: integrationv1.FieldSelector{
+				FieldPath: "metadata.annotations.url",
+				Options:   &integrationv1.FieldOptions{Delimiter: ":", Index: 0},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal("postgres.default.svc"))
+		_, err = getVar(&integrationv1.Var{
+			Name:   "host",
+			ObjRef: postgres,
+			FieldRef: integrationv1.FieldSelector{
+				FieldPath: "metadata.annotations.url",
+				Options:   &integrationv1.FieldOptions{Delimiter: ":", Index: 2},
+			},
+		})
+		Expect(err).To(MatchError("var host: fieldref options index 2 is out of bounds for value postgres.default.svc:5432"))
+	})
+
+	It("should get the json value of jsonpath expressions", func() {
+		value, err := getVar(&integrationv1.Var{
+			Name:     "port",
+			ObjRef:   postgres,
+			FieldRef: integrationv1.FieldSelector{JSONPath: "{.spec.ports[0]}"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal(map[string]interface{}{"name": "postgres", "port": int64(5432)}))
+		value, err = getVar(&integrationv1.Var{
+			Name:     "ports",
+			ObjRef:   postgres,
+			FieldRef: integrationv1.FieldSelector{JSONPath: ".spec.ports[*].port"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal([]interface{}{int64(5432), int64(9187)}))
+		value, err = getVar(&integrationv1.Var{
+			Name:     "metrics",
+			ObjRef:   postgres,
+			FieldRef: integrationv1.FieldSelector{JSONPath: `{.spec.ports[?(@.name=="metrics")].port}`},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal([]interface{}{int64(9187)}))
+	})
+
+	It("should select objects by label", func() {
+		value, err := getVar(&integrationv1.Var{
+			Name:     "names",
+			ObjRef:   selected,
+			FieldRef: integrationv1.FieldSelector{JSONPath: "{.items[*].metadata.name}"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal([]interface{}{"postgres", "postgres-replica"}))
+		value, err = getVar(&integrationv1.Var{
+			Name:     "count",
+			ObjRef:   selected,
+			FieldRef: integrationv1.FieldSelector{FieldPath: "items.#"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal("2"))
+	})
+
+	It("should get the json value of cel expressions", func() {
+		value, err := getVar(&integrationv1.Var{
+			Name:   "ports",
+			ObjRef: selected,
+			FieldRef: integrationv1.FieldSelector{
+				CEL: `object.items.filter(s, has(s.spec)).map(s, {"name": s.metadata.name, "ports": size(s.spec.ports)})`,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal([]interface{}{map[string]interface{}{"name": "postgres", "ports": int64(2)}}))
+		_, err = getVar(&integrationv1.Var{
+			Name:     "invalid",
+			ObjRef:   postgres,
+			FieldRef: integrationv1.FieldSelector{CEL: "object.metadata.name +"},
+		})
+		Expect(err).To(MatchError(ContainSubstring(`var invalid: invalid cel expression "object.metadata.name +"`)))
+	})
+
+	It("should limit the cost of cel expressions and compile them once", func() {
+		items := make([]string, 50)
+		for i := range items {
+			items[i] = strconv.Itoa(i)
+		}
+		list := "[" + strings.Join(items, ", ") + "]"
+		expression := list + ".map(a, " + list + ".map(b, " + list + ".map(c, " + list + ".map(d, d))))"
+		_, err := getVar(&integrationv1.Var{
+			Name:     "expensive",
+			ObjRef:   postgres,
+			FieldRef: integrationv1.FieldSelector{CEL: expression},
+		})
+		Expect(err).To(MatchError(ContainSubstring("operation cancelled: actual cost limit exceeded")))
+		Expect(util.VarProgramCached(expression)).To(BeTrue())
+	})
+
+	It("should fail on missing keys of the name template of strict vars", func() {
+		plug := &integrationv1.Plug{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}
+		v := &integrationv1.Var{
+			Name:     "port",
+			ObjRef:   integrationv1.Target{APIVersion: "v1", Gvk: integrationv1.Gvk{Kind: "Service"}, TemplateName: "{% .missing %}"},
+			FieldRef: integrationv1.FieldSelector{FieldPath: "spec.ports.0.port"},
+		}
+		_, err := varUtil.GetVar("default", v, kubectlUtil, plug, nil, false)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		_, err = varUtil.GetVar("default", v, kubectlUtil, plug, nil, true)
+		Expect(err).To(MatchError(ContainSubstring(`map has no entry for key "missing"`)))
+	})
+
+	It("should reject ambiguous vars", func() {
+		_, err := getVar(&integrationv1.Var{
+			Name:     "name",
+			ObjRef:   postgres,
+			FieldRef: integrationv1.FieldSelector{FieldPath: "metadata.name", CEL: "object.metadata.name"},
+		})
+		Expect(err).To(MatchError("var name: fieldref must set only one of fieldPath, jsonPath or cel"))
+		objRef := selected
+		objRef.Name = "postgres"
+		_, err = getVar(&integrationv1.Var{Name: "name", ObjRef: objRef})
+		Expect(err).To(MatchError("var name objref must set either a name or a selector"))
+	})
+})